@@ -0,0 +1,218 @@
+// Package cef parses ArcSight Common Event Format (CEF) payloads as they are commonly sent in the MSG of a syslog
+// message.
+package cef
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+const prefix = "CEF:"
+
+type Parser struct{}
+
+// NewParser creates a new Parser.
+func NewParser() Parser {
+	return Parser{}
+}
+
+// Parse parses a CEF payload. Any text before the "CEF:" prefix, such as a syslog header that was not stripped, is
+// ignored. If no CEF payload is found ErrNotCEF is returned.
+func (p Parser) Parse(input string) (Message, error) {
+	start := strings.Index(input, prefix)
+	if start < 0 {
+		return Message{}, ErrNotCEF
+	}
+	input = input[start+len(prefix):]
+
+	header, extension, err := splitHeader(input)
+	if err != nil {
+		return Message{}, err
+	}
+
+	version, err := strconv.Atoi(header[0])
+	if err != nil || version < 0 {
+		return Message{}, ErrInvalidVersion
+	}
+
+	extensions, err := parseExtension(extension)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Version:       version,
+		DeviceVendor:  header[1],
+		DeviceProduct: header[2],
+		DeviceVersion: header[3],
+		SignatureID:   header[4],
+		Name:          header[5],
+		Severity:      header[6],
+		Extensions:    extensions,
+	}, nil
+}
+
+// FromRFC3164 parses the CEF payload of an RFC3164 message. As the RFC3164 parser splits the content on the first
+// ':', the tag and content are joined again before parsing.
+func (p Parser) FromRFC3164(m rfc3164.Message) (Message, error) {
+	return p.Parse(m.Tag + m.Content)
+}
+
+// FromRFC5424 parses the CEF payload in the MSG of an RFC5424 message.
+func (p Parser) FromRFC5424(m rfc5424.Message) (Message, error) {
+	return p.Parse(m.Message)
+}
+
+// ParseRFC3164 parses a syslog message using the given RFC3164 parser and then parses its CEF payload.
+func (p Parser) ParseRFC3164(parser rfc3164.Parser, input io.ByteScanner) (rfc3164.Message, Message, error) {
+	m, err := parser.Parse(input)
+	if err != nil {
+		return m, Message{}, err
+	}
+	c, err := p.FromRFC3164(m)
+	return m, c, err
+}
+
+// ParseRFC5424 parses a syslog message using the given RFC5424 parser and then parses its CEF payload.
+func (p Parser) ParseRFC5424(parser rfc5424.Parser, input io.ByteScanner) (rfc5424.Message, Message, error) {
+	m, err := parser.Parse(input)
+	if err != nil {
+		return m, Message{}, err
+	}
+	c, err := p.FromRFC5424(m)
+	return m, c, err
+}
+
+// splitHeader splits the input on the first seven unescaped pipes into the unescaped header fields and the
+// remaining extension. In the header, '|' and '\' are escaped using a backslash.
+func splitHeader(input string) ([7]string, string, error) {
+	var (
+		header  [7]string
+		field   int
+		start   int
+		escaped bool
+	)
+	for i := 0; i < len(input); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case input[i] == '\\':
+			escaped = true
+		case input[i] == '|':
+			header[field] = unescapeHeader(input[start:i])
+			field++
+			start = i + 1
+			if field == len(header) {
+				return header, input[start:], nil
+			}
+		}
+	}
+	return header, "", ErrInvalidHeader
+}
+
+func unescapeHeader(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	builder := strings.Builder{}
+	builder.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && (s[i+1] == '|' || s[i+1] == '\\') {
+			i++
+		}
+		builder.WriteByte(s[i])
+	}
+	return builder.String()
+}
+
+// parseExtension parses the space separated key=value pairs of the extension. Values may contain spaces, so a value
+// ends where the next key starts. In values, '=' and '\' are escaped using a backslash and newlines are written as \n
+// or \r.
+func parseExtension(input string) (map[string]string, error) {
+	input = strings.TrimSpace(input)
+	extensions := map[string]string{}
+	if input == "" {
+		return extensions, nil
+	}
+
+	key := ""
+	valueStart := 0
+	for i := 0; i < len(input); i++ {
+		if input[i] == '\\' {
+			i++
+			continue
+		}
+		if input[i] != '=' {
+			continue
+		}
+
+		// The key of this pair is the word before the '='. For the first pair that is the start of the input, for the
+		// next pairs it starts after the last space in the value of the previous pair.
+		keyStart := 0
+		if key != "" {
+			keyStart = strings.LastIndexByte(input[valueStart:i], ' ')
+			if keyStart < 0 {
+				// An unescaped '=' inside a value, which is common enough to accept.
+				continue
+			}
+			keyStart += valueStart + 1
+			extensions[key] = unescapeValue(strings.TrimRight(input[valueStart:keyStart], " "))
+		}
+		key = input[keyStart:i]
+		if !validKey(key) {
+			return nil, ErrInvalidExtension
+		}
+		valueStart = i + 1
+	}
+	if key == "" {
+		return nil, ErrInvalidExtension
+	}
+	extensions[key] = unescapeValue(input[valueStart:])
+
+	return extensions, nil
+}
+
+func unescapeValue(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	builder := strings.Builder{}
+	builder.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case '=', '\\':
+				i++
+			case 'n':
+				i++
+				builder.WriteByte('\n')
+				continue
+			case 'r':
+				i++
+				builder.WriteByte('\r')
+				continue
+			}
+		}
+		builder.WriteByte(s[i])
+	}
+	return builder.String()
+}
+
+// validKey checks whether the key consists of characters that are used in the CEF dictionary and custom keys.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		b := key[i]
+		if !(b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '_' || b == '.' ||
+			b == '-' || b == '[' || b == ']') {
+			return false
+		}
+	}
+	return true
+}
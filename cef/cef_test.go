@@ -0,0 +1,164 @@
+//nolint:lll
+package cef
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		msg             string
+		expectedMessage Message
+		expectedError   error
+	}{
+		{
+			name: "valid message",
+			msg:  "CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232",
+			expectedMessage: Message{
+				Version:       0,
+				DeviceVendor:  "Security",
+				DeviceProduct: "threatmanager",
+				DeviceVersion: "1.0",
+				SignatureID:   "100",
+				Name:          "worm successfully stopped",
+				Severity:      "10",
+				Extensions:    map[string]string{"src": "10.0.0.1", "dst": "2.1.2.2", "spt": "1232"},
+			},
+		},
+		{
+			name: "valid message - escaped header",
+			msg:  "CEF:0|security|threatmanager|1.0|100|detected a \\| in message|10|src=10.0.0.1 act=blocked a \\\\ dst=1.1.1.1",
+			expectedMessage: Message{
+				DeviceVendor:  "security",
+				DeviceProduct: "threatmanager",
+				DeviceVersion: "1.0",
+				SignatureID:   "100",
+				Name:          "detected a | in message",
+				Severity:      "10",
+				Extensions:    map[string]string{"src": "10.0.0.1", "act": "blocked a \\", "dst": "1.1.1.1"},
+			},
+		},
+		{
+			name: "valid message - escaped extension values",
+			msg:  "CEF:1|security|threatmanager|1.0|100|detected an = in message|High|fileId=key\\=value msg=Detected a threat.\\n No action needed. cs1Label=Custom Label",
+			expectedMessage: Message{
+				Version:       1,
+				DeviceVendor:  "security",
+				DeviceProduct: "threatmanager",
+				DeviceVersion: "1.0",
+				SignatureID:   "100",
+				Name:          "detected an = in message",
+				Severity:      "High",
+				Extensions: map[string]string{
+					"fileId":   "key=value",
+					"msg":      "Detected a threat.\n No action needed.",
+					"cs1Label": "Custom Label",
+				},
+			},
+		},
+		{
+			name: "valid message - unescaped equals in value",
+			msg:  "CEF:0|Vendor|Product|1|2|name|5|request=https://example.com/?a=b suser=bob",
+			expectedMessage: Message{
+				DeviceVendor:  "Vendor",
+				DeviceProduct: "Product",
+				DeviceVersion: "1",
+				SignatureID:   "2",
+				Name:          "name",
+				Severity:      "5",
+				Extensions:    map[string]string{"request": "https://example.com/?a=b", "suser": "bob"},
+			},
+		},
+		{
+			name: "valid message - prefixed and no extension",
+			msg:  "host app: CEF:0|Vendor|Product|1|2|name|5|",
+			expectedMessage: Message{
+				DeviceVendor:  "Vendor",
+				DeviceProduct: "Product",
+				DeviceVersion: "1",
+				SignatureID:   "2",
+				Name:          "name",
+				Severity:      "5",
+				Extensions:    map[string]string{},
+			},
+		},
+		{
+			name:          "invalid message - no prefix",
+			msg:           "0|Vendor|Product|1|2|name|5|",
+			expectedError: ErrNotCEF,
+		},
+		{
+			name:          "invalid message - missing header fields",
+			msg:           "CEF:0|Vendor|Product|1|2|name",
+			expectedError: ErrInvalidHeader,
+		},
+		{
+			name:          "invalid message - version",
+			msg:           "CEF:a|Vendor|Product|1|2|name|5|",
+			expectedError: ErrInvalidVersion,
+		},
+		{
+			name:          "invalid message - extension without key",
+			msg:           "CEF:0|Vendor|Product|1|2|name|5|just text",
+			expectedError: ErrInvalidExtension,
+		},
+		{
+			name:          "invalid message - invalid key",
+			msg:           "CEF:0|Vendor|Product|1|2|name|5|a b=c",
+			expectedError: ErrInvalidExtension,
+		},
+	}
+
+	for _, tc := range testcases {
+		msg, err := NewParser().Parse(tc.msg)
+		assert.Equal(t, tc.expectedMessage, msg, tc.name)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<134>Feb 12 10:32:00 fw01 CEF:0|Vendor|Firewall|2.0|4000|Connection denied|5|src=1.2.3.4 dst=5.6.7.8")
+	m, c, err := NewParser().ParseRFC3164(rfc3164.NewParser(), bytes.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, "fw01", m.Hostname)
+	assert.Equal(t, "Firewall", c.DeviceProduct)
+	assert.Equal(t, map[string]string{"src": "1.2.3.4", "dst": "5.6.7.8"}, c.Extensions)
+
+	_, _, err = NewParser().ParseRFC3164(rfc3164.NewParser(), bytes.NewReader([]byte("<134>invalid")))
+	assert.Equal(t, rfc3164.ErrInvalidTimestamp, err)
+}
+
+func TestParseRFC5424(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<134>1 2003-10-11T22:14:15.003Z fw01 firewall - - - CEF:0|Vendor|Firewall|2.0|4000|Connection denied|5|src=1.2.3.4")
+	m, c, err := NewParser().ParseRFC5424(rfc5424.NewParser(), bytes.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, "firewall", m.AppName)
+	assert.Equal(t, "Connection denied", c.Name)
+	assert.Equal(t, map[string]string{"src": "1.2.3.4"}, c.Extensions)
+
+	_, err = NewParser().FromRFC5424(rfc5424.Message{Message: "not cef"})
+	assert.Equal(t, ErrNotCEF, err)
+}
+
+func BenchmarkParse(b *testing.B) {
+	msg := "CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232 msg=Some longer message with spaces"
+	p := NewParser()
+	for i := 0; i < b.N; i++ {
+		_, err := p.Parse(msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
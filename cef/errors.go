@@ -0,0 +1,10 @@
+package cef
+
+import "errors"
+
+var (
+	ErrNotCEF           = errors.New("not a CEF message")
+	ErrInvalidVersion   = errors.New("invalid CEF version")
+	ErrInvalidHeader    = errors.New("invalid CEF header")
+	ErrInvalidExtension = errors.New("invalid CEF extension")
+)
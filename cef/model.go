@@ -0,0 +1,16 @@
+package cef
+
+// Message represents an ArcSight Common Event Format (CEF) event.
+// CEF:Version|Device Vendor|Device Product|Device Version|Device Event Class ID|Name|Severity|[Extension]
+type Message struct {
+	Version       int
+	DeviceVendor  string
+	DeviceProduct string
+	DeviceVersion string
+	// SignatureID is the Device Event Class ID, which uniquely identifies the type of event.
+	SignatureID string
+	Name        string
+	// Severity is either an integer from 0 to 10 or one of Unknown, Low, Medium, High and Very-High.
+	Severity   string
+	Extensions map[string]string
+}
@@ -0,0 +1,85 @@
+// Package common contains the types and parsing primitives shared by the syslog formats in this module.
+package common
+
+import "io"
+
+// ParsePRI parses the PRI part of a syslog message according to the following rules.
+// PRI             = "<" PRIVAL ">"
+// PRIVAL          = 1*3DIGIT ; range 0 .. 191
+func ParsePRI(input io.ByteScanner) (PRI, error) {
+	b, err := input.ReadByte()
+	if err != nil || b != '<' {
+		return PRI{}, ErrInvalidPRI
+	}
+
+	value := 0
+	for i := 0; i < 4; i++ {
+		b, err = input.ReadByte()
+		if err != nil {
+			return PRI{}, ErrInvalidPRI
+		}
+		if b == '>' {
+			if i == 0 || value > 191 {
+				return PRI{}, ErrInvalidPRI
+			}
+			return PRI{value: byte(value)}, nil
+		}
+		if b < '0' || b > '9' {
+			return PRI{}, ErrInvalidPRI
+		}
+		value = value*10 + int(b-'0')
+	}
+
+	return PRI{}, ErrInvalidPRI
+}
+
+// ParseField reads the input up to the next space and returns it as a string. The space is consumed but not included
+// in the result. If the input ends before a space is found or the field is longer than max, e is returned. A max of
+// zero disables the length check.
+func ParseField(input io.ByteScanner, max int, e error) (string, error) {
+	field, err := AppendField(nil, input, max, e)
+	if err != nil {
+		return "", err
+	}
+	return string(field), nil
+}
+
+// AppendField reads a field in the same way as ParseField, but appends it to dst instead of returning a string. On
+// error, dst is returned with its original length.
+func AppendField(dst []byte, input io.ByteScanner, max int, e error) ([]byte, error) {
+	start := len(dst)
+	for {
+		b, err := input.ReadByte()
+		if err != nil {
+			return dst[:start], e
+		}
+		if b == ' ' {
+			break
+		}
+		dst = append(dst, b)
+	}
+	if max > 0 && len(dst)-start > max {
+		return dst[:start], e
+	}
+	return dst, nil
+}
+
+// SkipField reads the input up to and including the next space without storing it and returns the length of the
+// field. It follows the same rules as ParseField.
+func SkipField(input io.ByteScanner, max int, e error) (int, error) {
+	n := 0
+	for {
+		b, err := input.ReadByte()
+		if err != nil {
+			return 0, e
+		}
+		if b == ' ' {
+			break
+		}
+		n++
+	}
+	if max > 0 && n > max {
+		return 0, e
+	}
+	return n, nil
+}
@@ -0,0 +1,309 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePRI(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		msg           []byte
+		expectedPRI   PRI
+		expectedError error
+	}{
+		{
+			name:        "valid PRI - single digit",
+			msg:         []byte("<3>"),
+			expectedPRI: PRI{3},
+		},
+		{
+			name:        "valid PRI - double digit",
+			msg:         []byte("<34>"),
+			expectedPRI: PRI{34},
+		},
+		{
+			name:        "valid PRI - triple digit",
+			msg:         []byte("<165>"),
+			expectedPRI: PRI{165},
+		},
+		{
+			name:          "invalid PRI - missing closing bracket",
+			msg:           []byte("<165"),
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "invalid PRI - invalid character",
+			msg:           []byte("<1a5>"),
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "invalid PRI - value too high",
+			msg:           []byte("<192>"),
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "invalid PRI - value overflowing a byte",
+			msg:           []byte("<300>"),
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "invalid PRI - value too long",
+			msg:           []byte("<0192>"),
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "invalid PRI - no digits",
+			msg:           []byte("<>"),
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "invalid PRI - missing opening bracket",
+			msg:           []byte("165>"),
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "invalid PRI - empty",
+			msg:           []byte(""),
+			expectedError: ErrInvalidPRI,
+		},
+	}
+
+	for _, tc := range testcases {
+		pri, err := ParsePRI(bytes.NewReader(tc.msg))
+		assert.Equal(t, tc.expectedPRI, pri, tc.name)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+	}
+}
+
+func TestNewPRI(t *testing.T) {
+	t.Parallel()
+
+	pri, err := NewPRI(165)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(165), pri.Value())
+	assert.Equal(t, byte(20), pri.Facility())
+	assert.Equal(t, byte(5), pri.Severity())
+	assert.Equal(t, "local4", pri.FacilityName())
+	assert.Equal(t, "notice", pri.SeverityName())
+
+	pri, err = NewPRI(191)
+	assert.Nil(t, err)
+	assert.Equal(t, "local7", pri.FacilityName())
+	assert.Equal(t, "debug", pri.SeverityName())
+
+	_, err = NewPRI(192)
+	assert.Equal(t, ErrInvalidPRI, err)
+
+	assert.Equal(t, byte(165), MustPRI(165).Value())
+	assert.PanicsWithValue(t, ErrInvalidPRI, func() { MustPRI(192) })
+}
+
+func TestNewPRIFromParts(t *testing.T) {
+	t.Parallel()
+
+	pri, err := NewPRIFromParts(20, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, PRI{165}, pri)
+
+	pri, err = NewPRIFromNames("local4", "notice")
+	assert.Nil(t, err)
+	assert.Equal(t, PRI{165}, pri)
+	_, err = NewPRIFromNames("local8", "notice")
+	assert.Equal(t, ErrInvalidPRI, err)
+	_, err = NewPRIFromNames("local4", "fatal")
+	assert.Equal(t, ErrInvalidPRI, err)
+
+	_, err = NewPRIFromParts(24, 0)
+	assert.Equal(t, ErrInvalidPRI, err)
+	_, err = NewPRIFromParts(0, 8)
+	assert.Equal(t, ErrInvalidPRI, err)
+}
+
+func TestParseNames(t *testing.T) {
+	t.Parallel()
+
+	for i := byte(0); i < 24; i++ {
+		pri, _ := NewPRIFromParts(i, i%8)
+		facility, ok := ParseFacilityName(pri.FacilityName())
+		assert.True(t, ok)
+		assert.Equal(t, i, facility)
+		severity, ok := ParseSeverityName(pri.SeverityName())
+		assert.True(t, ok)
+		assert.Equal(t, i%8, severity)
+	}
+
+	_, ok := ParseFacilityName("unknown")
+	assert.False(t, ok)
+	_, ok = ParseSeverityName("unknown")
+	assert.False(t, ok)
+}
+
+func TestPRIJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(PRI{165})
+	assert.Nil(t, err)
+	assert.Equal(t, "165", string(data))
+
+	var pri PRI
+	assert.Nil(t, json.Unmarshal([]byte("34"), &pri))
+	assert.Equal(t, PRI{34}, pri)
+	assert.Equal(t, ErrInvalidPRI, json.Unmarshal([]byte("192"), &pri))
+	assert.Equal(t, ErrInvalidPRI, json.Unmarshal([]byte(`"34"`), &pri))
+}
+
+func TestParseField(t *testing.T) {
+	t.Parallel()
+
+	errTest := ErrInvalidPRI
+
+	testcases := []struct {
+		name          string
+		msg           []byte
+		max           int
+		expectedField string
+		expectedError error
+	}{
+		{
+			name:          "valid field",
+			msg:           []byte("host rest"),
+			max:           4,
+			expectedField: "host",
+		},
+		{
+			name:          "valid field - unbounded",
+			msg:           []byte("hostname "),
+			expectedField: "hostname",
+		},
+		{
+			name:          "valid field - empty",
+			msg:           []byte(" "),
+			expectedField: "",
+		},
+		{
+			name:          "invalid field - too long",
+			msg:           []byte("hostname "),
+			max:           4,
+			expectedError: errTest,
+		},
+		{
+			name:          "invalid field - no space",
+			msg:           []byte("host"),
+			expectedError: errTest,
+		},
+	}
+
+	for _, tc := range testcases {
+		field, err := ParseField(bytes.NewReader(tc.msg), tc.max, errTest)
+		assert.Equal(t, tc.expectedField, field, tc.name)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	pri := PRI{165} // Facility 20, severity 5.
+
+	var f Filter
+	assert.Nil(t, f.CheckPRI(pri))
+	assert.Nil(t, f.CheckHostname("host"))
+	assert.Nil(t, f.CheckAppName("app"))
+
+	f.SetMinSeverity(5)
+	assert.Nil(t, f.CheckPRI(pri))
+	f.SetMinSeverity(4)
+	assert.Equal(t, ErrFiltered, f.CheckPRI(pri))
+
+	f = Filter{}
+	f.AddFacilities(3)
+	assert.Equal(t, ErrFiltered, f.CheckPRI(pri))
+	f.AddFacilities(20)
+	assert.Nil(t, f.CheckPRI(pri))
+	assert.Nil(t, f.Err())
+	f.AddFacilities(23, 24)
+	assert.Equal(t, ErrInvalidFacility, f.Err())
+
+	f.SetHostnameMatch(regexp.MustCompile(`^web-\d+$`))
+	assert.Nil(t, f.CheckHostname("web-01"))
+	assert.Equal(t, ErrFiltered, f.CheckHostname("db-01"))
+
+	f.AddAppNames("sshd", "sudo")
+	assert.Nil(t, f.CheckAppName("sudo"))
+	assert.Equal(t, ErrFiltered, f.CheckAppName("cron"))
+}
+
+func TestDetectJSON(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name        string
+		body        string
+		expectedRaw string
+		expectedOk  bool
+	}{
+		{
+			name:        "plain object",
+			body:        ` {"user": "bob"} `,
+			expectedRaw: `{"user": "bob"}`,
+			expectedOk:  true,
+		},
+		{
+			name:        "cee cookie",
+			body:        `@cee: {"user": "bob"}`,
+			expectedRaw: `{"user": "bob"}`,
+			expectedOk:  true,
+		},
+		{
+			name: "text",
+			body: `user logged in {"user": "bob"}`,
+		},
+		{
+			name: "cee cookie without object",
+			body: `@cee: user logged in`,
+		},
+		{
+			name: "array",
+			body: `["bob"]`,
+		},
+	}
+
+	for _, tc := range testcases {
+		payload, ok := DetectJSON(tc.body)
+		assert.Equal(t, tc.expectedOk, ok, tc.name)
+		if ok {
+			assert.Equal(t, tc.expectedRaw, payload.Raw(), tc.name)
+		}
+	}
+}
+
+func TestJSONPayload(t *testing.T) {
+	t.Parallel()
+
+	payload, ok := DetectJSON(`@cee: {"user": "bob", "id": 42, "tags": ["a"]}`)
+	assert.True(t, ok)
+
+	fields, err := payload.Fields()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"user": "bob", "id": float64(42), "tags": []any{"a"}}, fields)
+
+	var typed struct {
+		User string `json:"user"`
+		ID   int    `json:"id"`
+	}
+	assert.Nil(t, payload.Decode(&typed))
+	assert.Equal(t, "bob", typed.User)
+	assert.Equal(t, 42, typed.ID)
+
+	payload, ok = DetectJSON(`{"user": }`)
+	assert.True(t, ok)
+	_, err = payload.Fields()
+	assert.NotNil(t, err)
+}
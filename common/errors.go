@@ -0,0 +1,12 @@
+package common
+
+import "errors"
+
+var (
+	ErrInvalidPRI = errors.New("invalid PRI")
+	// ErrInvalidFacility is returned by a parser when a facility above 23, the highest facility a PRI can hold, was
+	// passed to its facility filter.
+	ErrInvalidFacility = errors.New("invalid facility")
+	// ErrFiltered is returned when a message is discarded by one of the filter options of a parser.
+	ErrFiltered = errors.New("message filtered")
+)
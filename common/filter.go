@@ -0,0 +1,95 @@
+package common
+
+import "regexp"
+
+// Filter decides whether a message should be kept based on its header fields. Each check is meant to be called as
+// soon as the relevant field has been parsed, so a parser can stop reading a message that is discarded anyway.
+// The zero value keeps every message.
+type Filter struct {
+	hasMinSeverity bool
+	minSeverity    byte
+	facilities     uint32
+	hostname       *regexp.Regexp
+	appNames       map[string]struct{}
+	err            error
+}
+
+// SetMinSeverity keeps only messages with a severity at least as important as the given severity. As lower values
+// are more important, this keeps messages with a severity value less than or equal to the given value.
+func (f *Filter) SetMinSeverity(severity byte) {
+	f.hasMinSeverity = true
+	f.minSeverity = severity
+}
+
+// AddFacilities keeps only messages with one of the given facilities. Calling it multiple times adds to the set. A
+// facility above 23 can not occur in a PRI, it is recorded as an error that is returned by Err.
+func (f *Filter) AddFacilities(facilities ...byte) {
+	for _, facility := range facilities {
+		if facility > 23 {
+			f.err = ErrInvalidFacility
+			continue
+		}
+		f.facilities |= 1 << facility
+	}
+}
+
+// Err returns ErrInvalidFacility if an invalid facility was passed to AddFacilities. A parser returns it before
+// parsing, so a misconfigured filter is reported instead of silently discarding messages.
+func (f *Filter) Err() error {
+	return f.err
+}
+
+// SetHostnameMatch keeps only messages of which the hostname matches the given regular expression.
+func (f *Filter) SetHostnameMatch(re *regexp.Regexp) {
+	f.hostname = re
+}
+
+// AddAppNames keeps only messages with one of the given application names. Calling it multiple times adds to the set.
+func (f *Filter) AddAppNames(names ...string) {
+	if f.appNames == nil {
+		f.appNames = make(map[string]struct{}, len(names))
+	}
+	for _, name := range names {
+		f.appNames[name] = struct{}{}
+	}
+}
+
+// HasHostnameMatch returns whether the filter needs the hostname of a message.
+func (f *Filter) HasHostnameMatch() bool {
+	return f.hostname != nil
+}
+
+// HasAppNames returns whether the filter needs the application name of a message.
+func (f *Filter) HasAppNames() bool {
+	return f.appNames != nil
+}
+
+// CheckPRI returns ErrFiltered if the message should be discarded based on its PRI.
+func (f *Filter) CheckPRI(pri PRI) error {
+	if f.hasMinSeverity && pri.Severity() > f.minSeverity {
+		return ErrFiltered
+	}
+	if f.facilities != 0 && f.facilities&(1<<pri.Facility()) == 0 {
+		return ErrFiltered
+	}
+	return nil
+}
+
+// CheckHostname returns ErrFiltered if the message should be discarded based on its hostname.
+func (f *Filter) CheckHostname(hostname string) error {
+	if f.hostname != nil && !f.hostname.MatchString(hostname) {
+		return ErrFiltered
+	}
+	return nil
+}
+
+// CheckAppName returns ErrFiltered if the message should be discarded based on its application name.
+func (f *Filter) CheckAppName(appName string) error {
+	if f.appNames == nil {
+		return nil
+	}
+	if _, ok := f.appNames[appName]; !ok {
+		return ErrFiltered
+	}
+	return nil
+}
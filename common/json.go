@@ -0,0 +1,47 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ceeCookie is the prefix used by rsyslog and others to mark a JSON payload in the message body.
+const ceeCookie = "@cee:"
+
+// JSONPayload is a JSON object embedded in the body of a syslog message. It is only detected while parsing, the JSON
+// is decoded when one of its methods is called.
+type JSONPayload struct {
+	raw string
+}
+
+// DetectJSON checks whether the body starts with a JSON object, optionally preceded by the "@cee:" cookie. Leading and
+// trailing whitespace is ignored. The JSON itself is not validated.
+func DetectJSON(body string) (*JSONPayload, bool) {
+	body = strings.TrimSpace(body)
+	if strings.HasPrefix(body, ceeCookie) {
+		body = strings.TrimSpace(body[len(ceeCookie):])
+	}
+	if !strings.HasPrefix(body, "{") || !strings.HasSuffix(body, "}") {
+		return nil, false
+	}
+	return &JSONPayload{raw: body}, true
+}
+
+// Raw returns the JSON object as it appeared in the message body, without the "@cee:" cookie.
+func (p *JSONPayload) Raw() string {
+	return p.raw
+}
+
+// Fields decodes the JSON object into a map. The object is decoded on every call.
+func (p *JSONPayload) Fields() (map[string]any, error) {
+	var fields map[string]any
+	if err := p.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Decode decodes the JSON object into v, in the same way as json.Unmarshal.
+func (p *JSONPayload) Decode(v any) error {
+	return json.Unmarshal([]byte(p.raw), v)
+}
@@ -0,0 +1,120 @@
+package common
+
+import "strconv"
+
+// PRI represents the Priority value of a syslog message.
+// The PRI is a single byte that encodes the facility and severity of the message.
+type PRI struct {
+	value byte
+}
+
+// NewPRI creates a new PRI from its raw value. Values above 191 are invalid.
+func NewPRI(value byte) (PRI, error) {
+	if value > 191 {
+		return PRI{}, ErrInvalidPRI
+	}
+	return PRI{value: value}, nil
+}
+
+// MustPRI is like NewPRI but panics if the value is above 191. It is intended for constants and tests.
+func MustPRI(value byte) PRI {
+	pri, err := NewPRI(value)
+	if err != nil {
+		panic(err)
+	}
+	return pri
+}
+
+// NewPRIFromParts creates a new PRI from a facility and severity. The facility must be below 24 and the severity
+// below 8.
+func NewPRIFromParts(facility, severity byte) (PRI, error) {
+	if facility > 23 || severity > 7 {
+		return PRI{}, ErrInvalidPRI
+	}
+	return PRI{value: facility<<3 | severity}, nil
+}
+
+// NewPRIFromNames creates a new PRI from a facility and severity keyword as returned by FacilityName and SeverityName.
+func NewPRIFromNames(facility, severity string) (PRI, error) {
+	f, ok := ParseFacilityName(facility)
+	if !ok {
+		return PRI{}, ErrInvalidPRI
+	}
+	s, ok := ParseSeverityName(severity)
+	if !ok {
+		return PRI{}, ErrInvalidPRI
+	}
+	return NewPRIFromParts(f, s)
+}
+
+// Value returns the raw value of the PRI.
+func (p PRI) Value() byte {
+	return p.value
+}
+
+// Facility returns the facility value of the PRI.
+func (p PRI) Facility() byte {
+	return p.value & 0xF8 >> 3
+}
+
+// Severity returns the severity value of the PRI.
+func (p PRI) Severity() byte {
+	return p.value & 0x07
+}
+
+var facilityNames = [...]string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp", "ntp",
+	"security", "console", "solaris-cron", "local0", "local1", "local2", "local3", "local4", "local5", "local6",
+	"local7",
+}
+
+var severityNames = [...]string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// FacilityName returns the keyword commonly used for the facility of the PRI, e.g. "kern" or "local0".
+func (p PRI) FacilityName() string {
+	return facilityNames[p.Facility()]
+}
+
+// SeverityName returns the keyword commonly used for the severity of the PRI, e.g. "err" or "info".
+func (p PRI) SeverityName() string {
+	return severityNames[p.Severity()]
+}
+
+// ParseFacilityName returns the facility for a keyword as returned by FacilityName.
+func ParseFacilityName(name string) (byte, bool) {
+	for i, n := range facilityNames {
+		if n == name {
+			return byte(i), true
+		}
+	}
+	return 0, false
+}
+
+// ParseSeverityName returns the severity for a keyword as returned by SeverityName.
+func ParseSeverityName(name string) (byte, bool) {
+	for i, n := range severityNames {
+		if n == name {
+			return byte(i), true
+		}
+	}
+	return 0, false
+}
+
+// MarshalJSON encodes the PRI as its raw value.
+func (p PRI) MarshalJSON() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(p.value), 10), nil
+}
+
+// UnmarshalJSON decodes a PRI from its raw value.
+func (p *PRI) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseUint(string(data), 10, 8)
+	if err != nil {
+		return ErrInvalidPRI
+	}
+	pri, err := NewPRI(byte(value))
+	if err != nil {
+		return err
+	}
+	*p = pri
+	return nil
+}
@@ -0,0 +1,180 @@
+// Package convert converts messages between the RFC3164 and RFC5424 formats. Converting to RFC5424 keeps all fields,
+// but the year and time zone of the timestamp are inferred and the tag is split into APP-NAME and PROCID by its usual
+// shape. Converting to RFC3164 reports the information that RFC3164 can not hold.
+package convert
+
+import (
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Lengths defined by the RFCs.
+const (
+	maxAppName = 48
+	maxProcID  = 128
+	maxTag     = 32
+)
+
+type Converter struct {
+	location *time.Location
+	now      func() time.Time
+	originIP netip.Addr
+}
+
+// NewConverter creates a new Converter with the provided options.
+func NewConverter(options ...option) Converter {
+	c := Converter{
+		location: time.UTC,
+		now:      time.Now,
+	}
+	for _, option := range options {
+		option(&c)
+	}
+	return c
+}
+
+var defaultConverter = NewConverter()
+
+// ToRFC5424 converts an RFC3164 message to RFC5424 using a Converter with the default options.
+func ToRFC5424(m rfc3164.Message) rfc5424.Message {
+	return defaultConverter.ToRFC5424(m)
+}
+
+// ToRFC3164 converts an RFC5424 message to RFC3164 using a Converter with the default options.
+func ToRFC3164(m rfc5424.Message) (rfc3164.Message, Loss) {
+	return defaultConverter.ToRFC3164(m)
+}
+
+// ToRFC5424 converts an RFC3164 message to RFC5424. The tag becomes the APP-NAME and a PID following it in square
+// brackets the PROCID. A tag that is not a valid APP-NAME, such as a tag containing spaces, is kept in the MSG. The
+// timestamp is placed in the location of the Converter and in the year that brings it closest to the current time.
+func (c Converter) ToRFC5424(m rfc3164.Message) rfc5424.Message {
+	r := rfc5424.Message{
+		PRI:       m.PRI,
+		Version:   1,
+		Timestamp: c.inferTimestamp(m.Timestamp),
+		Hostname:  m.Hostname,
+	}
+	r.AppName, r.ProcID, r.Message = splitTag(m.Tag, m.Content)
+
+	if c.originIP.IsValid() {
+		elements := []rfc5424.StructuredDataElement{{
+			ID:         "origin",
+			Parameters: map[string]string{"ip": c.originIP.String()},
+		}}
+		r.StructuredDataElements = &elements
+		r.StructuredData = rfc5424.FormatStructuredData(elements)
+	}
+	return r
+}
+
+// ToRFC3164 converts an RFC5424 message to RFC3164 and reports the information that was lost. The APP-NAME becomes
+// the tag, followed by the PROCID in square brackets. The timestamp is set in year zero, as the RFC3164 parser does.
+func (c Converter) ToRFC3164(m rfc5424.Message) (rfc3164.Message, Loss) {
+	var loss Loss
+	r := rfc3164.Message{
+		PRI:      m.PRI,
+		Hostname: m.Hostname,
+		Tag:      m.AppName,
+	}
+
+	if !m.Timestamp.IsZero() {
+		loss |= LossYear | LossTimeZone
+		if m.Timestamp.Nanosecond() != 0 {
+			loss |= LossFraction
+		}
+		t := m.Timestamp.In(c.location)
+		r.Timestamp = time.Date(0, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, c.location)
+	}
+
+	if len(r.Tag) > maxTag {
+		r.Tag = r.Tag[:maxTag]
+		loss |= LossAppName
+	}
+	switch {
+	case r.Tag == "":
+		r.Content = m.Message
+		if m.ProcID != "" {
+			loss |= LossProcID
+		}
+	case m.ProcID != "":
+		r.Content = "[" + m.ProcID + "]: " + m.Message
+	default:
+		r.Content = ": " + m.Message
+	}
+
+	if m.MsgID != "" {
+		loss |= LossMsgID
+	}
+	if m.StructuredData != "" || (m.StructuredDataElements != nil && len(*m.StructuredDataElements) > 0) {
+		loss |= LossStructuredData
+	}
+	return r, loss
+}
+
+// inferTimestamp places a timestamp without year and zone in the location of the Converter, in the year closest to
+// the current time. This handles both messages from the end of the previous year received just after new year and
+// senders with a clock that is slightly ahead.
+func (c Converter) inferTimestamp(timestamp time.Time) time.Time {
+	if timestamp.IsZero() {
+		return time.Time{}
+	}
+	if timestamp.Year() != 0 {
+		return timestamp
+	}
+
+	now := c.now().In(c.location)
+	var best time.Time
+	var bestDistance time.Duration
+	for year := now.Year() - 1; year <= now.Year()+1; year++ {
+		candidate := time.Date(year, timestamp.Month(), timestamp.Day(), timestamp.Hour(), timestamp.Minute(),
+			timestamp.Second(), timestamp.Nanosecond(), c.location)
+		distance := candidate.Sub(now).Abs()
+		if best.IsZero() || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// splitTag splits the tag and content of an RFC3164 message into the APP-NAME, PROCID and MSG.
+//
+//	TAG[PID]: MSG
+//	TAG: MSG
+func splitTag(tag, content string) (appName, procID, msg string) {
+	if !validName(tag, maxAppName) {
+		return "", "", tag + content
+	}
+
+	rest := content
+	if strings.HasPrefix(rest, "[") {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 || !validName(rest[1:end], maxProcID) {
+			return "", "", tag + content
+		}
+		procID, rest = rest[1:end], rest[end+1:]
+	}
+	rest, ok := strings.CutPrefix(rest, ":")
+	if !ok {
+		return "", "", tag + content
+	}
+	return tag, procID, strings.TrimPrefix(rest, " ")
+}
+
+// validName checks whether the value consists of 1 to max printable US-ASCII characters, as required for the
+// APP-NAME and PROCID.
+func validName(value string, max int) bool {
+	if value == "" || len(value) > max {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < 33 || value[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+//nolint:lll
+package convert
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestToRFC5424(t *testing.T) {
+	t.Parallel()
+
+	cet := time.FixedZone("CET", 3600)
+	now := func() time.Time { return time.Date(2024, time.January, 1, 0, 5, 0, 0, time.UTC) }
+
+	testcases := []struct {
+		name            string
+		input           string
+		options         []option
+		expectedMessage rfc5424.Message
+	}{
+		{
+			name:    "tag with pid and origin",
+			input:   "<38>Jan  1 00:04:00 web01 sshd[1234]: Accepted publickey for alice",
+			options: []option{WithNow(now), WithOriginIP(netip.MustParseAddr("192.0.2.1"))},
+			expectedMessage: rfc5424.Message{
+				PRI:            common.MustPRI(38),
+				Version:        1,
+				Timestamp:      time.Date(2024, time.January, 1, 0, 4, 0, 0, time.UTC),
+				Hostname:       "web01",
+				AppName:        "sshd",
+				ProcID:         "1234",
+				StructuredData: `[origin ip="192.0.2.1"]`,
+				StructuredDataElements: &[]rfc5424.StructuredDataElement{
+					{ID: "origin", Parameters: map[string]string{"ip": "192.0.2.1"}},
+				},
+				Message: "Accepted publickey for alice",
+			},
+		},
+		{
+			name:    "previous year and location",
+			input:   "<34>Dec 31 23:59:00 mymachine su: 'su root' failed",
+			options: []option{WithNow(now), WithLocation(cet)},
+			expectedMessage: rfc5424.Message{
+				PRI:       common.MustPRI(34),
+				Version:   1,
+				Timestamp: time.Date(2023, time.December, 31, 23, 59, 0, 0, cet),
+				Hostname:  "mymachine",
+				AppName:   "su",
+				Message:   "'su root' failed",
+			},
+		},
+		{
+			name:    "next year",
+			input:   "<34>Jan  1 00:01:00 mymachine su: ok",
+			options: []option{WithNow(func() time.Time { return time.Date(2023, time.December, 31, 23, 59, 0, 0, time.UTC) })},
+			expectedMessage: rfc5424.Message{
+				PRI:       common.MustPRI(34),
+				Version:   1,
+				Timestamp: time.Date(2024, time.January, 1, 0, 1, 0, 0, time.UTC),
+				Hostname:  "mymachine",
+				AppName:   "su",
+				Message:   "ok",
+			},
+		},
+		{
+			name:    "tag that is not an APP-NAME",
+			input:   "<13>Feb  5 17:32:18 10.0.0.99 Use the BFG: now",
+			options: []option{WithNow(now)},
+			expectedMessage: rfc5424.Message{
+				PRI:       common.MustPRI(13),
+				Version:   1,
+				Timestamp: time.Date(2024, time.February, 5, 17, 32, 18, 0, time.UTC),
+				Hostname:  "10.0.0.99",
+				Message:   "Use the BFG: now",
+			},
+		},
+		{
+			name:    "no tag",
+			input:   "<13>Feb  5 17:32:18 10.0.0.99 no tag here",
+			options: []option{WithNow(now)},
+			expectedMessage: rfc5424.Message{
+				PRI:       common.MustPRI(13),
+				Version:   1,
+				Timestamp: time.Date(2024, time.February, 5, 17, 32, 18, 0, time.UTC),
+				Hostname:  "10.0.0.99",
+				Message:   "no tag here",
+			},
+		},
+		{
+			name:  "no timestamp",
+			input: "<13> 10.0.0.99 app: message",
+			expectedMessage: rfc5424.Message{
+				PRI:      common.MustPRI(13),
+				Version:  1,
+				Hostname: "10.0.0.99",
+				AppName:  "app",
+				Message:  "message",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := rfc3164.NewParser().Parse(bytes.NewReader([]byte(tc.input)))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedMessage, NewConverter(tc.options...).ToRFC5424(m))
+		})
+	}
+}
+
+func TestToRFC3164(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		input           string
+		options         []option
+		expectedMessage rfc3164.Message
+		expectedLoss    Loss
+	}{
+		{
+			name:  "full message",
+			input: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 42 ID47 [exampleSDID@32473 iut="3"] An application event`,
+			expectedMessage: rfc3164.Message{
+				PRI:       common.MustPRI(165),
+				Timestamp: time.Date(0, time.October, 11, 22, 14, 15, 0, time.UTC),
+				Hostname:  "mymachine.example.com",
+				Tag:       "evntslog",
+				Content:   "[42]: An application event",
+			},
+			expectedLoss: LossYear | LossTimeZone | LossFraction | LossMsgID | LossStructuredData,
+		},
+		{
+			name:    "location",
+			input:   "<34>1 2003-10-11T22:14:15Z mymachine su - - - 'su root' failed",
+			options: []option{WithLocation(time.FixedZone("CET", 3600))},
+			expectedMessage: rfc3164.Message{
+				PRI:       common.MustPRI(34),
+				Timestamp: time.Date(0, time.October, 11, 23, 14, 15, 0, time.FixedZone("CET", 3600)),
+				Hostname:  "mymachine",
+				Tag:       "su",
+				Content:   ": 'su root' failed",
+			},
+			expectedLoss: LossYear | LossTimeZone,
+		},
+		{
+			name:  "nil values",
+			input: "<34>1 - - - - - - message",
+			expectedMessage: rfc3164.Message{
+				PRI:     common.MustPRI(34),
+				Content: "message",
+			},
+		},
+		{
+			name:  "PROCID without APP-NAME",
+			input: "<34>1 - - - 42 - - message",
+			expectedMessage: rfc3164.Message{
+				PRI:     common.MustPRI(34),
+				Content: "message",
+			},
+			expectedLoss: LossProcID,
+		},
+		{
+			name:  "long APP-NAME",
+			input: "<34>1 - - abcdefghijklmnopqrstuvwxyz0123456789 - - - message",
+			expectedMessage: rfc3164.Message{
+				PRI:     common.MustPRI(34),
+				Tag:     "abcdefghijklmnopqrstuvwxyz012345",
+				Content: ": message",
+			},
+			expectedLoss: LossAppName,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := rfc5424.NewParser().Parse(bytes.NewReader([]byte(tc.input)))
+			assert.NoError(t, err)
+			r, loss := NewConverter(tc.options...).ToRFC3164(m)
+			assert.Equal(t, tc.expectedMessage, r)
+			assert.Equal(t, tc.expectedLoss, loss)
+		})
+	}
+}
+
+func TestDefaultConverter(t *testing.T) {
+	t.Parallel()
+
+	m, err := rfc5424.NewParser().Parse(bytes.NewReader([]byte("<34>1 2003-10-11T22:14:15Z host su 42 - - message")))
+	assert.NoError(t, err)
+	legacy, loss := ToRFC3164(m)
+	assert.Equal(t, "su", legacy.Tag)
+	assert.Equal(t, "[42]: message", legacy.Content)
+	assert.Equal(t, LossYear|LossTimeZone, loss)
+
+	r := ToRFC5424(legacy)
+	assert.Equal(t, "su", r.AppName)
+	assert.Equal(t, "42", r.ProcID)
+	assert.Equal(t, "message", r.Message)
+	assert.Equal(t, time.UTC, r.Timestamp.Location())
+}
+
+func TestLoss(t *testing.T) {
+	t.Parallel()
+
+	loss := LossYear | LossMsgID | LossStructuredData
+	assert.True(t, loss.Has(LossYear|LossMsgID))
+	assert.False(t, loss.Has(LossYear|LossFraction))
+	assert.Equal(t, "year,msgid,structured data", loss.String())
+	assert.Equal(t, "", Loss(0).String())
+}
@@ -0,0 +1,41 @@
+package convert
+
+import "strings"
+
+// Loss identifies the information of an RFC5424 message that could not be represented in RFC3164. Losses can be
+// combined using a bitwise or.
+type Loss uint16
+
+const (
+	// LossYear is set for every timestamp, as RFC3164 timestamps have no year.
+	LossYear Loss = 1 << iota
+	// LossTimeZone is set for every timestamp, as RFC3164 timestamps have no offset. The timestamp is converted to
+	// the location of the Converter.
+	LossTimeZone
+	// LossFraction is set when the timestamp had a fraction of a second.
+	LossFraction
+	// LossAppName is set when the APP-NAME was truncated to the 32 characters allowed for an RFC3164 tag.
+	LossAppName
+	LossMsgID
+	LossStructuredData
+	// LossProcID is set when the message had a PROCID but no APP-NAME, as the PROCID is part of the tag.
+	LossProcID
+)
+
+var lossNames = [...]string{"year", "time zone", "fraction", "app-name", "msgid", "structured data", "procid"}
+
+// Has reports whether all losses in other are part of l.
+func (l Loss) Has(other Loss) bool {
+	return l&other == other
+}
+
+// String returns the names of the losses separated by commas, e.g. "year,time zone,msgid".
+func (l Loss) String() string {
+	var names []string
+	for i, name := range lossNames {
+		if l&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,32 @@
+package convert
+
+import (
+	"net/netip"
+	"time"
+)
+
+type option func(*Converter)
+
+// WithLocation sets the time zone of RFC3164 timestamps, which do not include one. Timestamps converted to RFC3164
+// are written in this location as well. It defaults to time.UTC.
+func WithLocation(loc *time.Location) option {
+	return func(c *Converter) {
+		c.location = loc
+	}
+}
+
+// WithNow sets the function returning the current time, which is used to infer the year of RFC3164 timestamps. It
+// defaults to time.Now.
+func WithNow(now func() time.Time) option {
+	return func(c *Converter) {
+		c.now = now
+	}
+}
+
+// WithOriginIP adds an origin structured data element with the given address to messages converted to RFC5424, as a
+// relay should to identify where the message was converted.
+func WithOriginIP(ip netip.Addr) option {
+	return func(c *Converter) {
+		c.originIP = ip
+	}
+}
@@ -0,0 +1,283 @@
+// Package cisco parses the syslog dialect of Cisco IOS, IOS XE, NX-OS and ASA devices, which the RFC3164 parser
+// misreads due to the sequence number, timestamp flags, milliseconds and the %FACILITY-SEVERITY-MNEMONIC tag.
+package cisco
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+var months = [...]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+type Parser struct {
+	location *time.Location
+}
+
+// NewParser creates a new Parser with the provided options.
+func NewParser(options ...parseOption) Parser {
+	p := Parser{location: time.UTC}
+	for _, option := range options {
+		option(&p)
+	}
+	return p
+}
+
+// Parse tries to parse a Cisco syslog message from the input. The sequence number, hostname and timestamp are
+// optional, the %FACILITY-SEVERITY-MNEMONIC is required.
+func (p Parser) Parse(input io.ByteScanner) (Message, error) {
+	var m Message
+
+	pri, err := common.ParsePRI(input)
+	if err != nil {
+		return Message{}, err
+	}
+	m.PRI = pri
+
+	builder := strings.Builder{}
+	for {
+		b, err := input.ReadByte()
+		if err != nil {
+			break
+		}
+		builder.WriteByte(b)
+	}
+	rest := builder.String()
+
+	rest, err = p.parseHeader(&m, rest)
+	if err != nil {
+		return Message{}, err
+	}
+
+	rest, err = parseMnemonic(&m, rest)
+	if err != nil {
+		return Message{}, err
+	}
+	m.Text = rest
+	return m, nil
+}
+
+// Detect reports whether the frame looks like a Cisco syslog message, which is the case when it contains a
+// %FACILITY-SEVERITY-MNEMONIC: at the start of a word. The header fields before it are not checked.
+func (p Parser) Detect(frame []byte) bool {
+	for i := bytes.IndexByte(frame, '%'); i >= 0; {
+		if i > 0 && (frame[i-1] == '>' || frame[i-1] == ' ') {
+			if end := bytes.IndexByte(frame[i:], ':'); end > 0 && isMnemonic(frame[i+1:i+end]) {
+				return true
+			}
+		}
+		next := bytes.IndexByte(frame[i+1:], '%')
+		if next < 0 {
+			return false
+		}
+		i += next + 1
+	}
+	return false
+}
+
+// isMnemonic checks whether the tag has the form FACILITY-SEVERITY-MNEMONIC, in the same way as parseMnemonic.
+func isMnemonic(tag []byte) bool {
+	if bytes.ContainsAny(tag, " \t") {
+		return false
+	}
+	last := bytes.LastIndexByte(tag, '-')
+	if last < 0 || last == len(tag)-1 {
+		return false
+	}
+	tag = tag[:last]
+	last = bytes.LastIndexByte(tag, '-')
+	return last >= 1 && len(tag)-last == 2 && tag[last+1] >= '0' && tag[last+1] <= '7'
+}
+
+// parseHeader parses the optional sequence number, hostname and timestamp and returns the remainder of the input.
+func (p Parser) parseHeader(m *Message, rest string) (string, error) {
+	// The sequence number is a number terminated by ": ".
+	if field, after, ok := cutField(rest); ok {
+		if sequence, err := strconv.ParseUint(field, 10, 64); err == nil {
+			m.Sequence, m.HasSequence = sequence, true
+			rest = after
+		}
+	}
+
+	// Devices configured with an origin-id send their hostname before the timestamp.
+	if field, after, ok := cutField(rest); ok && !isTimestamp(field) && !strings.HasPrefix(field, "%") {
+		m.Hostname = field
+		rest = after
+	}
+
+	if isTimestamp(rest) {
+		var err error
+		rest, err = p.parseTimestamp(m, rest)
+		if err != nil {
+			return "", err
+		}
+		// The ASA places its hostname after the timestamp, terminated by ": " or " : ".
+		if !strings.HasPrefix(rest, "%") {
+			if field, after, ok := cutField(rest); ok {
+				m.Hostname = strings.TrimSuffix(field, " ")
+				rest = after
+			}
+		}
+	}
+	return rest, nil
+}
+
+// parseTimestamp parses a timestamp in one of the following formats, terminated by ": " or a space.
+// [*|.]MMM dd [yyyy] hh:mm:ss[.fff][ ZONE]
+func (p Parser) parseTimestamp(m *Message, input string) (string, error) {
+	rest := input
+	if rest[0] == '*' || rest[0] == '.' {
+		m.TimestampFlag = rest[0]
+		rest = rest[1:]
+	}
+
+	month := 0
+	for i, name := range months {
+		if strings.HasPrefix(rest, name) {
+			month = i + 1
+		}
+	}
+	rest = strings.TrimLeft(rest[3:], " ")
+
+	day, rest, ok := cutNumber(rest, 2)
+	if !ok || !strings.HasPrefix(rest, " ") {
+		return input, ErrInvalidTimestamp
+	}
+	rest = rest[1:]
+
+	year := 0
+	if y, after, ok := cutNumber(rest, 4); ok && strings.HasPrefix(after, " ") && len(rest)-len(after) == 4 {
+		year = y
+		rest = after[1:]
+	}
+
+	var clock [3]int
+	for i := range clock {
+		if i > 0 {
+			if !strings.HasPrefix(rest, ":") {
+				return input, ErrInvalidTimestamp
+			}
+			rest = rest[1:]
+		}
+		clock[i], rest, ok = cutNumber(rest, 2)
+		if !ok {
+			return input, ErrInvalidTimestamp
+		}
+	}
+
+	nsec := 0
+	if strings.HasPrefix(rest, ".") {
+		start := len(rest)
+		var frac int
+		frac, rest, ok = cutNumber(rest[1:], 9)
+		if !ok {
+			return input, ErrInvalidTimestamp
+		}
+		digits := start - len(rest) - 1
+		nsec = frac
+		for ; digits < 9; digits++ {
+			nsec *= 10
+		}
+	}
+
+	// An optional zone name, followed by the end of the timestamp.
+	if zone, after, ok := strings.Cut(rest, ":"); ok && strings.HasPrefix(zone, " ") && isZone(zone[1:]) {
+		m.Zone = zone[1:]
+		rest = after
+	}
+	rest = strings.TrimPrefix(rest, ":")
+	rest = strings.TrimPrefix(rest, " ")
+
+	loc := p.location
+	if m.Zone == "UTC" || m.Zone == "GMT" {
+		loc = time.UTC
+	}
+	m.Timestamp = time.Date(year, time.Month(month), day, clock[0], clock[1], clock[2], nsec, loc)
+	if m.Timestamp.Month() != time.Month(month) || m.Timestamp.Day() != day || clock[0] > 23 || clock[1] > 59 ||
+		clock[2] > 60 {
+		return input, ErrInvalidTimestamp
+	}
+	return rest, nil
+}
+
+// parseMnemonic parses the %FACILITY-SEVERITY-MNEMONIC: tag and returns the remainder of the input. The facility can
+// contain dashes itself, e.g. %IP-SNMP-4-NOTRAPIP, so the severity is the last single digit element before the
+// mnemonic.
+func parseMnemonic(m *Message, input string) (string, error) {
+	if !strings.HasPrefix(input, "%") {
+		return "", ErrInvalidMnemonic
+	}
+	tag, rest, ok := strings.Cut(input[1:], ":")
+	if !ok {
+		return "", ErrInvalidMnemonic
+	}
+
+	last := strings.LastIndexByte(tag, '-')
+	if last < 0 {
+		return "", ErrInvalidMnemonic
+	}
+	mnemonic := tag[last+1:]
+	tag = tag[:last]
+	last = strings.LastIndexByte(tag, '-')
+	if last < 1 || len(tag)-last != 2 || tag[last+1] < '0' || tag[last+1] > '7' {
+		return "", ErrInvalidMnemonic
+	}
+	if mnemonic == "" || strings.ContainsAny(mnemonic, " \t") || strings.ContainsAny(tag, " \t") {
+		return "", ErrInvalidMnemonic
+	}
+
+	m.Facility = tag[:last]
+	m.Severity = tag[last+1] - '0'
+	m.Mnemonic = mnemonic
+	return strings.TrimPrefix(rest, " "), nil
+}
+
+// cutField returns the text up to the first ": " if it contains no spaces, apart from a single trailing space that is
+// used by the ASA. ok is false if there is no such field.
+func cutField(input string) (field, rest string, ok bool) {
+	field, rest, ok = strings.Cut(input, ": ")
+	if !ok || field == "" || strings.Contains(strings.TrimSuffix(field, " "), " ") {
+		return "", input, false
+	}
+	return field, rest, true
+}
+
+// isTimestamp checks whether the input starts with a month name, optionally preceded by a timestamp flag.
+func isTimestamp(input string) bool {
+	input = strings.TrimLeft(input, "*.")
+	if len(input) < 4 || input[3] != ' ' {
+		return false
+	}
+	for _, name := range months {
+		if input[:3] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isZone checks whether the name looks like a time zone abbreviation, e.g. "UTC" or "CEST".
+func isZone(name string) bool {
+	if len(name) < 2 || len(name) > 6 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] < 'A' || name[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// cutNumber parses up to max leading digits of the input.
+func cutNumber(input string, max int) (int, string, bool) {
+	n, i := 0, 0
+	for ; i < len(input) && i < max && input[i] >= '0' && input[i] <= '9'; i++ {
+		n = n*10 + int(input[i]-'0')
+	}
+	return n, input[i:], i > 0
+}
@@ -0,0 +1,146 @@
+//nolint:lll
+package cisco
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/common"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		msg             []byte
+		options         []parseOption
+		expectedMessage Message
+		expectedError   error
+	}{
+		{
+			name: "IOS - sequence, flag, milliseconds and zone",
+			msg:  []byte("<189>123: *Mar  1 18:46:11.123 UTC: %SYS-5-CONFIG_I: Configured from console by vty0 (10.0.0.1)"),
+			expectedMessage: Message{
+				PRI:           common.MustPRI(189),
+				Sequence:      123,
+				HasSequence:   true,
+				Timestamp:     time.Date(0, time.March, 1, 18, 46, 11, 123000000, time.UTC),
+				Zone:          "UTC",
+				TimestampFlag: '*',
+				Facility:      "SYS",
+				Severity:      5,
+				Mnemonic:      "CONFIG_I",
+				Text:          "Configured from console by vty0 (10.0.0.1)",
+			},
+		},
+		{
+			name: "IOS - hostname and year",
+			msg:  []byte("<187>45: router1: .Jan 12 2024 03:04:05: %LINEPROTO-5-UPDOWN: Line protocol on Interface Gi0/1, changed state to down"),
+			expectedMessage: Message{
+				PRI:           common.MustPRI(187),
+				Sequence:      45,
+				HasSequence:   true,
+				Hostname:      "router1",
+				Timestamp:     time.Date(2024, time.January, 12, 3, 4, 5, 0, time.UTC),
+				TimestampFlag: '.',
+				Facility:      "LINEPROTO",
+				Severity:      5,
+				Mnemonic:      "UPDOWN",
+				Text:          "Line protocol on Interface Gi0/1, changed state to down",
+			},
+		},
+		{
+			name:    "IOS - local zone",
+			msg:     []byte("<189>7: Mar  1 18:46:11.123456 CET: %IP-SNMP-4-NOTRAPIP: SNMP trap source has no ip address"),
+			options: []parseOption{WithLocation(time.FixedZone("CET", 3600))},
+			expectedMessage: Message{
+				PRI:         common.MustPRI(189),
+				Sequence:    7,
+				HasSequence: true,
+				Timestamp:   time.Date(0, time.March, 1, 18, 46, 11, 123456000, time.FixedZone("CET", 3600)),
+				Zone:        "CET",
+				Facility:    "IP-SNMP",
+				Severity:    4,
+				Mnemonic:    "NOTRAPIP",
+				Text:        "SNMP trap source has no ip address",
+			},
+		},
+		{
+			name: "ASA - no header",
+			msg:  []byte("<166>%ASA-6-302013: Built outbound TCP connection 123 for outside:10.0.0.1/443 (10.0.0.1/443) to inside:192.168.1.2/5000 (192.168.1.2/5000)"),
+			expectedMessage: Message{
+				PRI:      common.MustPRI(166),
+				Facility: "ASA",
+				Severity: 6,
+				Mnemonic: "302013",
+				Text:     "Built outbound TCP connection 123 for outside:10.0.0.1/443 (10.0.0.1/443) to inside:192.168.1.2/5000 (192.168.1.2/5000)",
+			},
+		},
+		{
+			name: "ASA - timestamp and hostname",
+			msg:  []byte("<164>Mar 01 2024 18:46:11 asa-fw01 : %ASA-4-106023: Deny tcp src outside:1.2.3.4/1234 dst inside:10.0.0.1/22"),
+			expectedMessage: Message{
+				PRI:       common.MustPRI(164),
+				Hostname:  "asa-fw01",
+				Timestamp: time.Date(2024, time.March, 1, 18, 46, 11, 0, time.UTC),
+				Facility:  "ASA",
+				Severity:  4,
+				Mnemonic:  "106023",
+				Text:      "Deny tcp src outside:1.2.3.4/1234 dst inside:10.0.0.1/22",
+			},
+		},
+		{
+			name: "ASA - timestamp without hostname",
+			msg:  []byte("<164>Mar 01 2024 18:46:11: %ASA-4-106023: Deny"),
+			expectedMessage: Message{
+				PRI:       common.MustPRI(164),
+				Timestamp: time.Date(2024, time.March, 1, 18, 46, 11, 0, time.UTC),
+				Facility:  "ASA",
+				Severity:  4,
+				Mnemonic:  "106023",
+				Text:      "Deny",
+			},
+		},
+		{
+			name:          "invalid - PRI",
+			msg:           []byte("123: %SYS-5-CONFIG_I: Configured"),
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "invalid - no mnemonic",
+			msg:           []byte("<13>Oct 11 22:14:15 mymachine su: 'su root' failed"),
+			expectedError: ErrInvalidMnemonic,
+		},
+		{
+			name:          "invalid - severity",
+			msg:           []byte("<189>123: %SYS-9-CONFIG_I: Configured"),
+			expectedError: ErrInvalidMnemonic,
+		},
+		{
+			name:          "invalid - timestamp",
+			msg:           []byte("<189>123: *Mar 41 18:46:11.123 UTC: %SYS-5-CONFIG_I: Configured"),
+			expectedError: ErrInvalidTimestamp,
+		},
+	}
+
+	for _, tc := range testcases {
+		msg, err := NewParser(tc.options...).Parse(bytes.NewReader(tc.msg))
+		assert.Equal(t, tc.expectedMessage, msg, tc.name)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	p := NewParser()
+	assert.True(t, p.Detect([]byte("<189>123: *Mar  1 18:46:11.123 UTC: %SYS-5-CONFIG_I: Configured")))
+	assert.True(t, p.Detect([]byte("<166>%ASA-6-302013: Built outbound TCP connection")))
+	assert.False(t, p.Detect([]byte("<13>Oct 11 22:14:15 mymachine su: 50% done")))
+	assert.False(t, p.Detect([]byte("<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - msg")))
+	assert.False(t, p.Detect([]byte("<13>Oct 11 22:14:15 mymachine app: at 50%SYS-5-CONFIG_I: done")))
+	assert.False(t, p.Detect([]byte("<13>Oct 11 22:14:15 mymachine app: %SYS-9-CONFIG_I: done")))
+}
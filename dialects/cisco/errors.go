@@ -0,0 +1,13 @@
+package cisco
+
+import (
+	"errors"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+var (
+	ErrInvalidPRI       = common.ErrInvalidPRI
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	ErrInvalidMnemonic  = errors.New("invalid %FACILITY-SEVERITY-MNEMONIC")
+)
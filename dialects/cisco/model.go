@@ -0,0 +1,30 @@
+package cisco
+
+import (
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+// Message represents a syslog message as sent by Cisco IOS, IOS XE, NX-OS and ASA devices.
+// <PRI>[SEQUENCE: ][HOSTNAME: ][*|.]TIMESTAMP[ ZONE]: %FACILITY-SEVERITY-MNEMONIC: TEXT
+type Message struct {
+	PRI common.PRI
+	// Sequence is the message sequence number, which is only present if HasSequence is set.
+	Sequence    uint64
+	HasSequence bool
+	Hostname    string
+	// Timestamp is the time at which the message was generated. Timestamps without a year are in year zero.
+	Timestamp time.Time
+	// Zone is the time zone name as sent by the device, e.g. "UTC" or "CET".
+	Zone string
+	// TimestampFlag is '*' if the clock of the device is not authoritative, '.' if the clock was synchronised but
+	// lost its synchronisation, and zero otherwise.
+	TimestampFlag byte
+	// Facility is the Cisco facility, e.g. "SYS" or "ASA", which is unrelated to the syslog facility in the PRI.
+	Facility string
+	// Severity is the Cisco severity, which uses the same values as the syslog severity.
+	Severity byte
+	Mnemonic string
+	Text     string
+}
@@ -0,0 +1,13 @@
+package cisco
+
+import "time"
+
+type parseOption func(*Parser)
+
+// WithLocation sets the location of timestamps without a zone or with a zone other than UTC or GMT. As zone
+// abbreviations are ambiguous, their offset can not be derived from the name. It defaults to time.UTC.
+func WithLocation(loc *time.Location) parseOption {
+	return func(p *Parser) {
+		p.location = loc
+	}
+}
@@ -0,0 +1,102 @@
+// Package dialects selects the vendor parser for a frame. Each vendor package parses its dialect on top of the
+// RFC3164 or RFC5424 header it is sent with; the registry detects which one applies.
+package dialects
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/ysmilda/syslog/dialects/cisco"
+	"github.com/ysmilda/syslog/dialects/fortinet"
+	"github.com/ysmilda/syslog/dialects/juniper"
+	"github.com/ysmilda/syslog/dialects/paloalto"
+)
+
+var ErrUnknownDialect = errors.New("no dialect detected")
+
+// Dialect is a vendor specific parser.
+type Dialect interface {
+	// Name returns the name of the dialect, e.g. "juniper".
+	Name() string
+	// Detect reports whether the frame is in this dialect. It should be cheap, as it is called for every registered
+	// dialect until one matches.
+	Detect(frame []byte) bool
+	// Parse parses the frame into the message type of the vendor package.
+	Parse(frame []byte) (any, error)
+}
+
+// Parser is the parser interface implemented by the vendor packages.
+type Parser[M any] interface {
+	Parse(input io.ByteScanner) (M, error)
+	Detect(frame []byte) bool
+}
+
+type dialect[M any] struct {
+	name   string
+	parser Parser[M]
+}
+
+// New wraps a vendor parser as a Dialect.
+func New[M any](name string, parser Parser[M]) Dialect {
+	return dialect[M]{name: name, parser: parser}
+}
+
+func (d dialect[M]) Name() string {
+	return d.name
+}
+
+func (d dialect[M]) Detect(frame []byte) bool {
+	return d.parser.Detect(frame)
+}
+
+func (d dialect[M]) Parse(frame []byte) (any, error) {
+	return d.parser.Parse(bytes.NewReader(frame))
+}
+
+// Registry holds dialects in the order in which they are detected.
+type Registry struct {
+	dialects []Dialect
+}
+
+// NewRegistry creates a Registry with the provided dialects.
+func NewRegistry(dialects ...Dialect) *Registry {
+	return &Registry{dialects: dialects}
+}
+
+// Default returns a Registry with all vendor dialects of this module. Dialects with the most specific detection
+// are checked first.
+func Default() *Registry {
+	return NewRegistry(
+		New("juniper", juniper.NewParser()),
+		New("fortinet", fortinet.NewParser()),
+		New("paloalto", paloalto.NewParser()),
+		New("cisco", cisco.NewParser()),
+	)
+}
+
+// Register adds a dialect, which is detected after the already registered ones.
+func (r *Registry) Register(d Dialect) {
+	r.dialects = append(r.dialects, d)
+}
+
+// Detect returns the first dialect that detects the frame.
+func (r *Registry) Detect(frame []byte) (Dialect, bool) {
+	for _, d := range r.dialects {
+		if d.Detect(frame) {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// Parse parses the frame with the first dialect that detects it and returns the name of that dialect with the
+// message.
+func (r *Registry) Parse(frame []byte) (string, any, error) {
+	d, ok := r.Detect(frame)
+	if !ok {
+		return "", nil, ErrUnknownDialect
+	}
+	m, err := d.Parse(frame)
+	return d.Name(), m, err
+}
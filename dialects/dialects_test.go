@@ -0,0 +1,68 @@
+package dialects
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestGolden parses every line of testdata/<dialect>.log and compares the JSON encoded messages with
+// testdata/<dialect>.golden. Run with -update to regenerate the golden files after an intended change.
+func TestGolden(t *testing.T) {
+	t.Parallel()
+
+	registry := Default()
+
+	for _, name := range []string{"juniper", "paloalto", "fortinet", "cisco"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			input, err := os.ReadFile(filepath.Join("testdata", name+".log"))
+			assert.NoError(t, err)
+
+			var messages []any
+			scanner := bufio.NewScanner(bytes.NewReader(input))
+			for scanner.Scan() {
+				dialect, m, err := registry.Parse(scanner.Bytes())
+				assert.NoError(t, err, scanner.Text())
+				assert.Equal(t, name, dialect, scanner.Text())
+				messages = append(messages, m)
+			}
+
+			actual, err := json.MarshalIndent(messages, "", "\t")
+			assert.NoError(t, err)
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				assert.NoError(t, os.WriteFile(golden, append(actual, '\n'), 0o600))
+			}
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	_, _, err := registry.Parse([]byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed"))
+	assert.ErrorIs(t, err, ErrUnknownDialect)
+
+	registry.Register(Default().dialects[0])
+	dialect, ok := registry.Detect([]byte(`<14>1 - - - - - [junos@2636.1.1.1.2.18 username="root"]`))
+	assert.True(t, ok)
+	assert.Equal(t, "juniper", dialect.Name())
+
+	_, ok = registry.Detect([]byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed"))
+	assert.False(t, ok)
+}
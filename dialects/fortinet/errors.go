@@ -0,0 +1,5 @@
+package fortinet
+
+import "errors"
+
+var ErrNotFortiOS = errors.New("not a FortiOS log")
@@ -0,0 +1,103 @@
+// Package fortinet parses FortiOS logs, which are sent as key=value pairs in the body of a syslog message. FortiGate
+// devices usually omit the syslog header and start the body directly after the PRI.
+package fortinet
+
+import (
+	"bytes"
+	"io"
+	"net/netip"
+	"time"
+
+	"github.com/ysmilda/syslog/dialects/internal/envelope"
+	"github.com/ysmilda/syslog/dialects/internal/port"
+	"github.com/ysmilda/syslog/logfmt"
+)
+
+// maxPairs is the maximum number of fields in a log. Traffic logs of recent FortiOS versions contain more than the
+// default of the logfmt parser.
+const maxPairs = 256
+
+type Parser struct {
+	location *time.Location
+	fields   logfmt.Parser
+}
+
+// NewParser creates a new Parser with the provided options.
+func NewParser(options ...parseOption) Parser {
+	p := Parser{
+		location: time.UTC,
+		fields:   logfmt.NewParser(logfmt.WithMaxPairs(maxPairs)),
+	}
+	for _, option := range options {
+		option(&p)
+	}
+	return p
+}
+
+// Parse parses a FortiOS log from the input. The log can directly follow the PRI or be wrapped in an RFC3164 or
+// RFC5424 header.
+func (p Parser) Parse(input io.ByteScanner) (Message, error) {
+	header, body, err := envelope.Open(envelope.ReadFrame(input), isBare)
+	if err != nil {
+		return Message{}, err
+	}
+	fields, err := p.fields.Parse(body)
+	if err != nil {
+		return Message{}, err
+	}
+	if fields["logid"] == "" || fields["type"] == "" {
+		return Message{}, ErrNotFortiOS
+	}
+
+	m := Message{
+		PRI:        header.PRI,
+		Hostname:   header.Hostname,
+		Timestamp:  header.Timestamp,
+		DeviceName: fields["devname"],
+		DeviceID:   fields["devid"],
+		LogID:      fields["logid"],
+		Type:       fields["type"],
+		Subtype:    fields["subtype"],
+		Level:      fields["level"],
+		Fields:     fields,
+		Action:     fields["action"],
+		User:       fields["user"],
+	}
+	if timestamp, ok := p.parseTimestamp(fields); ok {
+		m.Timestamp = timestamp
+	}
+	if m.Hostname == "" {
+		m.Hostname = m.DeviceName
+	}
+	m.SourceIP, _ = netip.ParseAddr(fields["srcip"])
+	m.DestinationIP, _ = netip.ParseAddr(fields["dstip"])
+	m.SourcePort = port.Parse(fields["srcport"])
+	m.DestinationPort = port.Parse(fields["dstport"])
+	return m, nil
+}
+
+// Detect reports whether the frame contains a FortiOS log.
+func (p Parser) Detect(frame []byte) bool {
+	return bytes.Contains(frame, []byte("logid=")) && bytes.Contains(frame, []byte("devid="))
+}
+
+// parseTimestamp combines the date, time and optional tz fields, e.g. date=2019-05-10 time=11:37:47 tz="-0700".
+func (p Parser) parseTimestamp(fields map[string]string) (time.Time, bool) {
+	date, clock := fields["date"], fields["time"]
+	if date == "" || clock == "" {
+		return time.Time{}, false
+	}
+	if tz := fields["tz"]; tz != "" {
+		if t, err := time.Parse("2006-01-02 15:04:05 -0700", date+" "+clock+" "+tz); err == nil {
+			return t, true
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", date+" "+clock, p.location)
+	return t, err == nil
+}
+
+// isBare reports whether the log directly follows the PRI.
+func isBare(body []byte) bool {
+	return bytes.HasPrefix(body, []byte("date=")) || bytes.HasPrefix(body, []byte("logver=")) ||
+		bytes.HasPrefix(body, []byte("devname="))
+}
@@ -0,0 +1,26 @@
+package fortinet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("CET", 3600)
+
+	m, err := NewParser(WithLocation(loc)).Parse(bytes.NewReader([]byte(
+		`<189>date=2023-03-14 time=10:20:30 devname="FGT" devid="FGT60F" logid="0000000013" type="traffic" srcport=70000`)))
+	assert.NoError(t, err)
+	assert.Equal(t, "FGT", m.Hostname)
+	assert.Equal(t, time.Date(2023, time.March, 14, 10, 20, 30, 0, loc), m.Timestamp)
+	assert.Equal(t, uint16(0), m.SourcePort)
+	assert.Equal(t, "70000", m.Fields["srcport"])
+
+	_, err = NewParser().Parse(bytes.NewReader([]byte(`<189>date=2023-03-14 time=10:20:30 devname="FGT"`)))
+	assert.ErrorIs(t, err, ErrNotFortiOS)
+}
@@ -0,0 +1,33 @@
+package fortinet
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+// Message represents a FortiOS log, which is sent as key=value pairs in the body of a syslog message.
+type Message struct {
+	PRI      common.PRI
+	Hostname string
+	// Timestamp is composed of the date, time and tz fields.
+	Timestamp  time.Time
+	DeviceName string
+	DeviceID   string
+	LogID      string
+	Type       string
+	Subtype    string
+	Level      string
+	Fields     map[string]string
+
+	// The known fields below are converted to their types when present. If a value can not be converted, the typed
+	// field is left empty. The raw value is always available in Fields.
+
+	SourceIP        netip.Addr
+	SourcePort      uint16
+	DestinationIP   netip.Addr
+	DestinationPort uint16
+	Action          string
+	User            string
+}
@@ -0,0 +1,12 @@
+package fortinet
+
+import "time"
+
+type parseOption func(*Parser)
+
+// WithLocation sets the location of timestamps without a tz field. Defaults to UTC.
+func WithLocation(loc *time.Location) parseOption {
+	return func(p *Parser) {
+		p.location = loc
+	}
+}
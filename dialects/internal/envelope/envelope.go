@@ -0,0 +1,74 @@
+// Package envelope extracts the body of a vendor message from the syslog header it is wrapped in, which may be an
+// RFC5424 header, an RFC3164 header or only a PRI.
+package envelope
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+var ErrNoBody = errors.New("no message body found")
+
+// Header holds the syslog header fields that were present around the body.
+type Header struct {
+	PRI       common.PRI
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+}
+
+// Open returns the syslog header and the body of the frame. If the body starts directly after the PRI, which is
+// checked using bare, only the PRI is parsed. Otherwise the frame is parsed as RFC5424 if it has a version after
+// the PRI, and as RFC3164 if not. For RFC3164 the tag and content are joined again, as the tag detection splits
+// bodies that contain a ':'.
+func Open(frame []byte, bare func(body []byte) bool) (Header, string, error) {
+	end := bytes.IndexByte(frame, '>')
+	if end < 0 {
+		return Header{}, "", common.ErrInvalidPRI
+	}
+	rest := frame[end+1:]
+
+	if bare != nil && bare(rest) {
+		pri, err := common.ParsePRI(bytes.NewReader(frame[:end+1]))
+		if err != nil {
+			return Header{}, "", err
+		}
+		return Header{PRI: pri}, string(rest), nil
+	}
+
+	if len(rest) > 1 && rest[0] >= '1' && rest[0] <= '9' && rest[1] == ' ' {
+		m, err := rfc5424.NewParser(rfc5424.WithFields(rfc5424.FieldPRI | rfc5424.FieldTimestamp |
+			rfc5424.FieldHostname | rfc5424.FieldAppName | rfc5424.FieldMessage)).Parse(bytes.NewReader(frame))
+		if err == nil {
+			return Header{PRI: m.PRI, Timestamp: m.Timestamp, Hostname: m.Hostname, AppName: m.AppName}, m.Message, nil
+		}
+	}
+
+	m, err := rfc3164.NewParser().Parse(bytes.NewReader(frame))
+	if err != nil {
+		return Header{}, "", err
+	}
+	body := m.Tag + m.Content
+	if body == "" {
+		return Header{}, "", ErrNoBody
+	}
+	return Header{PRI: m.PRI, Timestamp: m.Timestamp, Hostname: m.Hostname}, body, nil
+}
+
+// ReadFrame reads the remainder of the input.
+func ReadFrame(input io.ByteScanner) []byte {
+	var frame []byte
+	for {
+		b, err := input.ReadByte()
+		if err != nil {
+			return frame
+		}
+		frame = append(frame, b)
+	}
+}
@@ -0,0 +1,13 @@
+// Package port parses the port numbers in the fields of vendor messages.
+package port
+
+import "strconv"
+
+// Parse returns the port number in the value, or 0 if the value is not a valid port number.
+func Parse(value string) uint16 {
+	port, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return 0
+	}
+	return uint16(port)
+}
@@ -0,0 +1,5 @@
+package juniper
+
+import "errors"
+
+var ErrNotJunos = errors.New("no junos structured data element")
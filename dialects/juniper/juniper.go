@@ -0,0 +1,67 @@
+// Package juniper parses Junos structured syslog messages, which are RFC5424 messages carrying the event attributes
+// in a [junos@2636.*] structured data element.
+package juniper
+
+import (
+	"bytes"
+	"io"
+	"net/netip"
+	"strings"
+
+	"github.com/ysmilda/syslog/dialects/internal/port"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// sdIDPrefix is the start of the SD-ID used by Junos, followed by the private enterprise number of Juniper Networks
+// and the platform specific OID.
+const sdIDPrefix = "junos@2636"
+
+type Parser struct {
+	syslog rfc5424.Parser
+}
+
+// NewParser creates a new Parser.
+func NewParser() Parser {
+	return Parser{syslog: rfc5424.NewParser(rfc5424.WithParseStructuredDataElements())}
+}
+
+// Parse parses a Junos structured syslog message from the input.
+func (p Parser) Parse(input io.ByteScanner) (Message, error) {
+	m, err := p.syslog.Parse(input)
+	if err != nil {
+		return Message{}, err
+	}
+	return p.FromRFC5424(m)
+}
+
+// FromRFC5424 extracts the Junos event from a parsed RFC5424 message.
+func (p Parser) FromRFC5424(m rfc5424.Message) (Message, error) {
+	elements, err := m.Elements()
+	if err != nil {
+		return Message{}, err
+	}
+	for _, element := range elements {
+		if !strings.HasPrefix(element.ID, sdIDPrefix) {
+			continue
+		}
+		j := Message{
+			Syslog:     m,
+			Process:    m.AppName,
+			Event:      m.MsgID,
+			Platform:   strings.TrimPrefix(strings.TrimPrefix(element.ID, sdIDPrefix), "."),
+			Attributes: element.Parameters,
+			Username:   element.Parameters["username"],
+		}
+		j.SourceAddress, _ = netip.ParseAddr(element.Parameters["source-address"])
+		j.DestinationAddress, _ = netip.ParseAddr(element.Parameters["destination-address"])
+		j.SourcePort = port.Parse(element.Parameters["source-port"])
+		j.DestinationPort = port.Parse(element.Parameters["destination-port"])
+		return j, nil
+	}
+	return Message{}, ErrNotJunos
+}
+
+// Detect reports whether the frame contains a Junos structured data element.
+func (p Parser) Detect(frame []byte) bool {
+	return bytes.Contains(frame, []byte("["+sdIDPrefix))
+}
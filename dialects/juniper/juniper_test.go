@@ -0,0 +1,53 @@
+//nolint:lll
+package juniper
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		msg           []byte
+		expected      Message
+		expectedError error
+	}{
+		{
+			name: "attributes",
+			msg:  []byte(`<14>1 - fw RT_FLOW - RT_FLOW_SESSION_CREATE [junos@2636.1.1.1.2.129 source-address="10.0.0.1" source-port="51234" destination-port="nope"] created`),
+			expected: Message{
+				Process:       "RT_FLOW",
+				Event:         "RT_FLOW_SESSION_CREATE",
+				Platform:      "1.1.1.2.129",
+				SourceAddress: netip.MustParseAddr("10.0.0.1"),
+				SourcePort:    51234,
+			},
+		},
+		{
+			name:          "no junos element",
+			msg:           []byte(`<14>1 - fw RT_FLOW - - [origin ip="10.0.0.1"] created`),
+			expectedError: ErrNotJunos,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewParser().Parse(bytes.NewReader(tc.msg))
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expected.Process, m.Process)
+			assert.Equal(t, tc.expected.Event, m.Event)
+			assert.Equal(t, tc.expected.Platform, m.Platform)
+			assert.Equal(t, tc.expected.SourceAddress, m.SourceAddress)
+			assert.Equal(t, tc.expected.SourcePort, m.SourcePort)
+			assert.Equal(t, tc.expected.DestinationPort, m.DestinationPort)
+		})
+	}
+}
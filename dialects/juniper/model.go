@@ -0,0 +1,28 @@
+package juniper
+
+import (
+	"net/netip"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Message represents a Junos structured syslog message.
+type Message struct {
+	Syslog rfc5424.Message
+	// Process is the Junos process that generated the event, taken from the APP-NAME, e.g. "RT_FLOW".
+	Process string
+	// Event is the Junos event tag, taken from the MSGID, e.g. "RT_FLOW_SESSION_CREATE".
+	Event string
+	// Platform is the OID following the enterprise number in the SD-ID, e.g. "1.1.1.2.129".
+	Platform   string
+	Attributes map[string]string
+
+	// The known attributes below are converted to their types when present. If a value can not be converted, the
+	// typed field is left empty. The raw value is always available in Attributes.
+
+	SourceAddress      netip.Addr
+	SourcePort         uint16
+	DestinationAddress netip.Addr
+	DestinationPort    uint16
+	Username           string
+}
@@ -0,0 +1,8 @@
+package paloalto
+
+import "errors"
+
+var (
+	ErrNotPANOS      = errors.New("not a PAN-OS log")
+	ErrInvalidFields = errors.New("invalid PAN-OS CSV fields")
+)
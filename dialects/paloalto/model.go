@@ -0,0 +1,39 @@
+package paloalto
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+// Message represents a PAN-OS log sent as CSV in the body of a syslog message.
+type Message struct {
+	PRI      common.PRI
+	Hostname string
+	// Fields holds all CSV fields of the log, in the order defined by the PAN-OS syslog field descriptions.
+	Fields        []string
+	ReceiveTime   time.Time
+	SerialNumber  string
+	Type          string
+	Subtype       string
+	GeneratedTime time.Time
+
+	// The fields below are only set for TRAFFIC and THREAT logs. If a value can not be converted, the typed field is
+	// left empty. The raw value is always available in Fields.
+
+	Source          netip.Addr
+	Destination     netip.Addr
+	Rule            string
+	SourceUser      string
+	DestinationUser string
+	Application     string
+	VirtualSystem   string
+	SourceZone      string
+	DestinationZone string
+	SessionID       uint64
+	SourcePort      uint16
+	DestinationPort uint16
+	Protocol        string
+	Action          string
+}
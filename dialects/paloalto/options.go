@@ -0,0 +1,13 @@
+package paloalto
+
+import "time"
+
+type parseOption func(*Parser)
+
+// WithLocation sets the location used for the receive and generated times, as PAN-OS does not include a zone in
+// them. Defaults to UTC.
+func WithLocation(loc *time.Location) parseOption {
+	return func(p *Parser) {
+		p.location = loc
+	}
+}
@@ -0,0 +1,180 @@
+// Package paloalto parses PAN-OS logs, which are sent as comma separated values in the body of a syslog message.
+package paloalto
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ysmilda/syslog/dialects/internal/envelope"
+	"github.com/ysmilda/syslog/dialects/internal/port"
+)
+
+// timeLayout is the layout of the timestamps in the CSV fields.
+const timeLayout = "2006/01/02 15:04:05"
+
+// Indexes of the CSV fields shared by all log types.
+const (
+	fieldReceiveTime   = 1
+	fieldSerialNumber  = 2
+	fieldType          = 3
+	fieldSubtype       = 4
+	fieldGeneratedTime = 6
+)
+
+// Indexes of the CSV fields of TRAFFIC and THREAT logs.
+const (
+	fieldSource          = 7
+	fieldDestination     = 8
+	fieldRule            = 11
+	fieldSourceUser      = 12
+	fieldDestinationUser = 13
+	fieldApplication     = 14
+	fieldVirtualSystem   = 15
+	fieldSourceZone      = 16
+	fieldDestinationZone = 17
+	fieldSessionID       = 22
+	fieldSourcePort      = 24
+	fieldDestinationPort = 25
+	fieldProtocol        = 29
+	fieldAction          = 30
+)
+
+type Parser struct {
+	location *time.Location
+}
+
+// NewParser creates a new Parser with the provided options.
+func NewParser(options ...parseOption) Parser {
+	p := Parser{location: time.UTC}
+	for _, option := range options {
+		option(&p)
+	}
+	return p
+}
+
+// Parse parses a PAN-OS log from the input. The log can be wrapped in either an RFC3164 or an RFC5424 header.
+func (p Parser) Parse(input io.ByteScanner) (Message, error) {
+	header, body, err := envelope.Open(envelope.ReadFrame(input), nil)
+	if err != nil {
+		return Message{}, err
+	}
+	if !isLog(body) {
+		return Message{}, ErrNotPANOS
+	}
+
+	r := csv.NewReader(strings.NewReader(body))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	fields, err := r.Read()
+	if err != nil || len(fields) <= fieldGeneratedTime {
+		return Message{}, ErrInvalidFields
+	}
+
+	m := Message{
+		PRI:          header.PRI,
+		Hostname:     header.Hostname,
+		Fields:       fields,
+		SerialNumber: fields[fieldSerialNumber],
+		Type:         fields[fieldType],
+		Subtype:      fields[fieldSubtype],
+	}
+	m.ReceiveTime, _ = time.ParseInLocation(timeLayout, fields[fieldReceiveTime], p.location)
+	m.GeneratedTime, _ = time.ParseInLocation(timeLayout, fields[fieldGeneratedTime], p.location)
+
+	if (m.Type == "TRAFFIC" || m.Type == "THREAT") && len(fields) > fieldAction {
+		m.Source, _ = netip.ParseAddr(fields[fieldSource])
+		m.Destination, _ = netip.ParseAddr(fields[fieldDestination])
+		m.Rule = fields[fieldRule]
+		m.SourceUser = fields[fieldSourceUser]
+		m.DestinationUser = fields[fieldDestinationUser]
+		m.Application = fields[fieldApplication]
+		m.VirtualSystem = fields[fieldVirtualSystem]
+		m.SourceZone = fields[fieldSourceZone]
+		m.DestinationZone = fields[fieldDestinationZone]
+		m.SessionID, _ = strconv.ParseUint(fields[fieldSessionID], 10, 64)
+		m.SourcePort = port.Parse(fields[fieldSourcePort])
+		m.DestinationPort = port.Parse(fields[fieldDestinationPort])
+		m.Protocol = fields[fieldProtocol]
+		m.Action = fields[fieldAction]
+	}
+	return m, nil
+}
+
+// Detect reports whether the frame contains a PAN-OS log. The syslog header is not parsed: the start of a log is
+// looked for directly after the PRI and after every space.
+func (p Parser) Detect(frame []byte) bool {
+	start := bytes.IndexByte(frame, '>')
+	if start < 0 {
+		return false
+	}
+	for i := start; i < len(frame); i++ {
+		if (i == start || frame[i] == ' ') && hasLogPrefix(frame[i+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// logPrefixShape is the shape of the receive time and the comma following it, where '0' stands for any digit.
+const logPrefixShape = "0000/00/00 00:00:00,"
+
+// hasLogPrefix checks the shape of the start of a PAN-OS log without allocating. It accepts the same logs as isLog,
+// apart from the receive time being checked for its digits only.
+func hasLogPrefix(b []byte) bool {
+	n := 0
+	for n < len(b) && b[n] >= '0' && b[n] <= '9' {
+		n++
+	}
+	if n == 0 || n == len(b) || b[n] != ',' {
+		return false
+	}
+	b = b[n+1:]
+
+	if len(b) < len(logPrefixShape) {
+		return false
+	}
+	for i := 0; i < len(logPrefixShape); i++ {
+		if logPrefixShape[i] == '0' && (b[i] < '0' || b[i] > '9') || logPrefixShape[i] != '0' && b[i] != logPrefixShape[i] {
+			return false
+		}
+	}
+	b = b[len(logPrefixShape):]
+
+	serial := bytes.IndexByte(b, ',')
+	if serial < 0 {
+		return false
+	}
+	b = b[serial+1:]
+	end := bytes.IndexByte(b, ',')
+	if end <= 0 {
+		return false
+	}
+	for _, c := range b[:end] {
+		if c >= 'a' && c <= 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isLog checks whether the body starts like a PAN-OS log: a number, the receive time, the serial number and an upper
+// case log type.
+// FUTURE_USE,Receive Time,Serial Number,Type,...
+func isLog(body string) bool {
+	parts := strings.SplitN(body, ",", 5)
+	if len(parts) < 5 {
+		return false
+	}
+	if _, err := strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return false
+	}
+	if _, err := time.Parse(timeLayout, parts[fieldReceiveTime]); err != nil {
+		return false
+	}
+	return parts[fieldType] != "" && strings.ToUpper(parts[fieldType]) == parts[fieldType]
+}
@@ -0,0 +1,43 @@
+package paloalto
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("CET", 3600)
+
+	m, err := NewParser(WithLocation(loc)).Parse(bytes.NewReader([]byte(
+		"<14>Mar 14 10:25:00 PA-3220 1,2023/03/14 10:25:00,013201001234,SYSTEM,general,2560,2023/03/14 10:24:59,,general")))
+	assert.NoError(t, err)
+	assert.Equal(t, "SYSTEM", m.Type)
+	assert.Equal(t, "general", m.Subtype)
+	assert.Equal(t, time.Date(2023, time.March, 14, 10, 25, 0, 0, loc), m.ReceiveTime)
+	assert.Equal(t, time.Date(2023, time.March, 14, 10, 24, 59, 0, loc), m.GeneratedTime)
+
+	_, err = NewParser().Parse(bytes.NewReader([]byte("<14>Mar 14 10:25:00 PA-3220 hello, world")))
+	assert.ErrorIs(t, err, ErrNotPANOS)
+
+	_, err = NewParser().Parse(bytes.NewReader([]byte("<14>Mar 14 10:25:00 PA-3220 1,2023/03/14 10:25:00,0132,SYSTEM,general")))
+	assert.ErrorIs(t, err, ErrInvalidFields)
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	p := NewParser()
+	assert.True(t, p.Detect([]byte("<14>Mar 14 10:25:00 PA-3220 1,2023/03/14 10:25:00,013201001234,SYSTEM,general,2560")))
+	assert.True(t, p.Detect([]byte(
+		"<14>1 2023-03-14T10:25:00Z PA-3220 - - - - 1,2023/03/14 10:25:00,013201001234,TRAFFIC,end")))
+	assert.True(t, p.Detect([]byte("<14>1,2023/03/14 10:25:00,013201001234,THREAT,url")))
+	assert.False(t, p.Detect([]byte("<14>Mar 14 10:25:00 PA-3220 hello, world")))
+	assert.False(t, p.Detect([]byte("<14>Mar 14 10:25:00 PA-3220 1,2023/03/14,013201001234,SYSTEM,general")))
+	assert.False(t, p.Detect([]byte("<14>Mar 14 10:25:00 PA-3220 1,2023/03/14 10:25:00,013201001234,system,general")))
+	assert.False(t, p.Detect([]byte("Mar 14 10:25:00 PA-3220 1,2023/03/14 10:25:00,013201001234,SYSTEM,general")))
+}
@@ -0,0 +1,9 @@
+package filesink
+
+import "errors"
+
+var (
+	ErrInvalidTemplate = errors.New("path template contains an unterminated variable")
+	ErrUnknownVariable = errors.New("path template contains an unknown variable")
+	ErrClosed          = errors.New("sink is closed")
+)
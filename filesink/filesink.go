@@ -0,0 +1,202 @@
+// Package filesink writes syslog messages to files whose paths are derived from the messages, such as
+// /var/log/remote/%HOST%/%APP%.log. Files are rotated by size or time, rotated files can be compressed and a limited
+// number of them is kept.
+//
+// The path template supports the variables %HOST%, %APP%, %FACILITY% and %SEVERITY%, taken from the message, and
+// %YEAR%, %MONTH%, %DAY% and %HOUR%, taken from the time the message is written.
+package filesink
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Sink writes messages to files. It is safe for concurrent use.
+type Sink struct {
+	template     pathTemplate
+	format       Format
+	maxSize      int64
+	interval     time.Duration
+	compress     bool
+	maxBackups   int
+	maxOpenFiles int
+	now          func() time.Time
+
+	mu sync.Mutex
+	// files holds the open files by path. The elements of lru hold the same files, most recently written first.
+	files  map[string]*list.Element
+	lru    *list.List
+	buf    []byte
+	closed bool
+}
+
+// file is an open file of the sink.
+type file struct {
+	path string
+	f    *os.File
+	size int64
+	// created is the time the current file was started, which determines its rotation interval.
+	created time.Time
+}
+
+// New creates a Sink that writes to the paths produced by the template.
+func New(template string, options ...option) (*Sink, error) {
+	t, err := compileTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sink{
+		template:     t,
+		format:       FormatRFC5424,
+		maxBackups:   10,
+		maxOpenFiles: 64,
+		now:          time.Now,
+		files:        map[string]*list.Element{},
+		lru:          list.New(),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s, nil
+}
+
+// Write writes the message to its file. The frame is the message as it was received and is only used by FormatRaw;
+// it may be nil.
+func (s *Sink) Write(m rfc5424.Message, frame []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	line, err := s.appendLine(s.buf[:0], m, frame)
+	if err != nil {
+		return err
+	}
+	s.buf = line
+
+	now := s.now()
+	f, err := s.open(s.template.expand(m, now), now)
+	if err != nil {
+		return err
+	}
+	if s.needsRotation(f, int64(len(line)), now) {
+		if f, err = s.rotate(f, now); err != nil {
+			return err
+		}
+	}
+	n, err := f.f.Write(line)
+	f.size += int64(n)
+	return err
+}
+
+// appendLine appends the message in the format of the sink, followed by a newline. A newline within the message is
+// escaped as #012, as rsyslog does, so every message is written as a single line.
+func (s *Sink) appendLine(dst []byte, m rfc5424.Message, frame []byte) ([]byte, error) {
+	switch {
+	case s.format == FormatRaw && frame != nil:
+		dst = appendEscaped(dst, frame)
+	case s.format == FormatJSON:
+		data, err := json.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, data...)
+	default:
+		start := len(dst)
+		dst = m.Append(dst)
+		if bytes.IndexByte(dst[start:], '\n') >= 0 {
+			dst = appendEscaped(dst[:start], bytes.Clone(dst[start:]))
+		}
+	}
+	return append(dst, '\n'), nil
+}
+
+func appendEscaped(dst, line []byte) []byte {
+	for {
+		i := bytes.IndexByte(line, '\n')
+		if i < 0 {
+			return append(dst, line...)
+		}
+		dst = append(dst, line[:i]...)
+		dst = append(dst, "#012"...)
+		line = line[i+1:]
+	}
+}
+
+// open returns the open file for the path, opening it if needed.
+func (s *Sink) open(path string, now time.Time) (*file, error) {
+	if element, ok := s.files[path]; ok {
+		s.lru.MoveToFront(element)
+		return element.Value.(*file), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	created := now
+	if info.Size() > 0 {
+		// The file was written before it was last closed.
+		created = info.ModTime()
+	}
+
+	for s.lru.Len() >= s.maxOpenFiles {
+		s.evict(s.lru.Back())
+	}
+	opened := &file{path: path, f: f, size: info.Size(), created: created}
+	s.files[path] = s.lru.PushFront(opened)
+	return opened, nil
+}
+
+// evict closes a file.
+func (s *Sink) evict(element *list.Element) {
+	f := s.lru.Remove(element).(*file)
+	delete(s.files, f.path)
+	f.f.Close()
+}
+
+func (s *Sink) needsRotation(f *file, n int64, now time.Time) bool {
+	if f.size == 0 {
+		return false
+	}
+	if s.maxSize > 0 && f.size+n > s.maxSize {
+		return true
+	}
+	return s.interval > 0 && !now.Truncate(s.interval).Equal(f.created.Truncate(s.interval))
+}
+
+// Close closes all files.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	s.closed = true
+	var err error
+	for element := s.lru.Front(); element != nil; element = element.Next() {
+		if closeErr := element.Value.(*file).f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	s.files = nil
+	s.lru.Init()
+	return err
+}
@@ -0,0 +1,249 @@
+//nolint:lll
+package filesink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func message(t *testing.T, hostname, appName, text string) rfc5424.Message {
+	t.Helper()
+	pri, err := rfc5424.NewPRI(165)
+	assert.Nil(t, err)
+	return rfc5424.Message{
+		PRI:       pri,
+		Version:   1,
+		Timestamp: time.Date(2003, 10, 11, 22, 14, 15, 0, time.UTC),
+		Hostname:  hostname,
+		AppName:   appName,
+		Message:   text,
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		template string
+		message  rfc5424.Message
+		expected string
+		err      error
+	}{
+		{
+			template: "/var/log/remote/%HOST%/%APP%.log",
+			message:  message(t, "web-01.example.com", "sshd", ""),
+			expected: "/var/log/remote/web-01.example.com/sshd.log",
+		},
+		{
+			template: "/var/log/%YEAR%/%MONTH%/%DAY%/%HOUR%/%FACILITY%.%SEVERITY%",
+			message:  message(t, "host", "app", ""),
+			expected: "/var/log/2024/03/04/05/local4.notice",
+		},
+		{
+			template: "/var/log/100%%/%HOST%%%",
+			message:  message(t, "host", "app", ""),
+			expected: "/var/log/100%/host%",
+		},
+		{
+			template: "/var/log/%HOST%/%APP%.log",
+			message:  message(t, "..", "../../etc/passwd", ""),
+			expected: "/var/log/_/.._.._etc_passwd.log",
+		},
+		{
+			template: "/var/log/%HOST%/%APP%.log",
+			message:  message(t, "", "ä b", ""),
+			expected: "/var/log/-/___b.log",
+		},
+		{
+			template: "/var/log/%HOSTNAME%.log",
+			err:      ErrUnknownVariable,
+		},
+		{
+			template: "/var/log/%HOST.log",
+			err:      ErrInvalidTemplate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			t.Parallel()
+
+			template, err := compileTemplate(tt.template)
+			assert.Equal(t, tt.err, err)
+			if err == nil {
+				assert.Equal(t, tt.expected, template.expand(tt.message, now))
+			}
+		})
+	}
+}
+
+func TestFormats(t *testing.T) {
+	t.Parallel()
+
+	m := message(t, "host", "app", "hello")
+	frame := []byte("<165>1 2003-10-11T22:14:15Z host app - - -  hello as received")
+
+	tests := []struct {
+		format   Format
+		frame    []byte
+		expected string
+	}{
+		{FormatRaw, frame, string(frame) + "\n"},
+		{FormatRaw, nil, "<165>1 2003-10-11T22:14:15Z host app - - - hello\n"},
+		{FormatRFC5424, frame, "<165>1 2003-10-11T22:14:15Z host app - - - hello\n"},
+		{FormatJSON, frame, `{"pri":165,"facility":"local4","severity":"notice","version":1,"timestamp":"2003-10-11T22:14:15Z","hostname":"host","app_name":"app","message":"hello"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		s, err := New(filepath.Join(dir, "%HOST%.log"), WithFormat(tt.format))
+		assert.Nil(t, err)
+		assert.Nil(t, s.Write(m, tt.frame))
+		assert.Nil(t, s.Close())
+		assert.Equal(t, tt.expected, read(t, filepath.Join(dir, "host.log")))
+	}
+}
+
+func TestMultilineMessage(t *testing.T) {
+	t.Parallel()
+
+	m := message(t, "host", "app", "first\nsecond\n")
+	frame := []byte("<165>1 2003-10-11T22:14:15Z host app - - - first\nsecond\n")
+
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{FormatRaw, "<165>1 2003-10-11T22:14:15Z host app - - - first#012second#012\n"},
+		{FormatRFC5424, "<165>1 2003-10-11T22:14:15Z host app - - - first#012second#012\n"},
+		{FormatJSON, `{"pri":165,"facility":"local4","severity":"notice","version":1,"timestamp":"2003-10-11T22:14:15Z","hostname":"host","app_name":"app","message":"first\nsecond\n"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		s, err := New(filepath.Join(dir, "%HOST%.log"), WithFormat(tt.format))
+		assert.Nil(t, err)
+		assert.Nil(t, s.Write(m, frame))
+		assert.Nil(t, s.Close())
+		assert.Equal(t, tt.expected, read(t, filepath.Join(dir, "host.log")))
+	}
+}
+
+func TestSizeRotation(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s, err := New(filepath.Join(dir, "%APP%.log"), WithFormat(FormatRaw), WithMaxSize(10), WithMaxBackups(2))
+	assert.Nil(t, err)
+
+	for _, line := range []string{"one", "two", "three", "four", "five", "six"} {
+		assert.Nil(t, s.Write(message(t, "host", "app", ""), []byte(line)))
+	}
+	// A message that is larger than the maximum size is written to an empty file.
+	assert.Nil(t, s.Write(message(t, "host", "app", ""), []byte("larger than ten")))
+	assert.Nil(t, s.Close())
+
+	assert.Equal(t, "larger than ten\n", read(t, filepath.Join(dir, "app.log")))
+	assert.Equal(t, "six\n", read(t, filepath.Join(dir, "app.log.1")))
+	assert.Equal(t, "four\nfive\n", read(t, filepath.Join(dir, "app.log.2")))
+	assert.NoFileExists(t, filepath.Join(dir, "app.log.3"))
+}
+
+func TestTimeRotation(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	now := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+	s, err := New(filepath.Join(dir, "%HOST%.log"), WithFormat(FormatRaw), WithRotateInterval(time.Hour), WithCompress(),
+		WithNow(func() time.Time { return now }))
+	assert.Nil(t, err)
+	defer s.Close()
+
+	write := func(line string) {
+		assert.Nil(t, s.Write(message(t, "host", "app", ""), []byte(line)))
+	}
+	write("05:06")
+	now = now.Add(50 * time.Minute)
+	write("05:56")
+	now = now.Add(10 * time.Minute)
+	write("06:06")
+	now = now.Add(24 * time.Hour)
+	write("next day")
+
+	assert.Equal(t, "next day\n", read(t, filepath.Join(dir, "host.log")))
+	assert.Equal(t, "06:06\n", decompress(t, filepath.Join(dir, "host.log.1.gz")))
+	assert.Equal(t, "05:06\n05:56\n", decompress(t, filepath.Join(dir, "host.log.2.gz")))
+	assert.NoFileExists(t, filepath.Join(dir, "host.log.1"))
+}
+
+func TestNoBackups(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s, err := New(filepath.Join(dir, "%HOST%.log"), WithFormat(FormatRaw), WithMaxSize(4), WithMaxBackups(0))
+	assert.Nil(t, err)
+	defer s.Close()
+
+	assert.Nil(t, s.Write(message(t, "host", "app", ""), []byte("one")))
+	assert.Nil(t, s.Write(message(t, "host", "app", ""), []byte("two")))
+	assert.Equal(t, "two\n", read(t, filepath.Join(dir, "host.log")))
+	assert.NoFileExists(t, filepath.Join(dir, "host.log.1"))
+}
+
+func TestMaxOpenFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s, err := New(filepath.Join(dir, "%HOST%", "%APP%.log"), WithFormat(FormatRaw), WithMaxOpenFiles(2))
+	assert.Nil(t, err)
+
+	for _, host := range []string{"a", "b", "c", "a", "c", "b"} {
+		assert.Nil(t, s.Write(message(t, host, "app", ""), []byte(host)))
+		assert.LessOrEqual(t, s.lru.Len(), 2)
+	}
+	assert.Equal(t, "b", filepath.Base(filepath.Dir(s.lru.Front().Value.(*file).path)))
+	assert.Nil(t, s.Close())
+
+	for _, host := range []string{"a", "b", "c"} {
+		assert.Equal(t, host+"\n"+host+"\n", read(t, filepath.Join(dir, host, "app.log")))
+	}
+}
+
+func TestClosed(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(filepath.Join(t.TempDir(), "%HOST%.log"))
+	assert.Nil(t, err)
+	assert.Nil(t, s.Close())
+	assert.Equal(t, ErrClosed, s.Write(message(t, "host", "app", ""), nil))
+	assert.Equal(t, ErrClosed, s.Close())
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	return string(data)
+}
+
+func decompress(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if !assert.Nil(t, err) {
+		return ""
+	}
+	out, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	return string(out)
+}
@@ -0,0 +1,13 @@
+package filesink
+
+// Format is the representation in which messages are written, one per line.
+type Format byte
+
+const (
+	// FormatRaw writes the frame as it was received. Messages written without a frame are formatted as RFC5424.
+	FormatRaw Format = iota
+	// FormatRFC5424 writes the message formatted as RFC5424.
+	FormatRFC5424
+	// FormatJSON writes the message as a JSON object, see rfc5424.Message.MarshalJSON.
+	FormatJSON
+)
@@ -0,0 +1,59 @@
+package filesink
+
+import "time"
+
+type option func(*Sink)
+
+// WithFormat sets the format of the written messages. It defaults to FormatRFC5424.
+func WithFormat(format Format) option {
+	return func(s *Sink) {
+		s.format = format
+	}
+}
+
+// WithMaxSize rotates a file before a message would make it exceed size bytes. Files are not rotated by size by
+// default.
+func WithMaxSize(size int64) option {
+	return func(s *Sink) {
+		s.maxSize = size
+	}
+}
+
+// WithRotateInterval rotates a file when a message is written in a different interval than the previous one, with
+// intervals aligned to UTC, e.g. every hour or every day at midnight UTC. Files are not rotated by time by default.
+func WithRotateInterval(interval time.Duration) option {
+	return func(s *Sink) {
+		s.interval = interval
+	}
+}
+
+// WithCompress compresses rotated files using gzip.
+func WithCompress() option {
+	return func(s *Sink) {
+		s.compress = true
+	}
+}
+
+// WithMaxBackups sets the number of rotated files that are kept per file, named with the suffixes .1 (the most
+// recent) to .N. Zero removes files on rotation. It defaults to 10.
+func WithMaxBackups(count int) option {
+	return func(s *Sink) {
+		s.maxBackups = count
+	}
+}
+
+// WithMaxOpenFiles limits the number of files that are kept open. The least recently written file is closed when the
+// limit is reached. It defaults to 64.
+func WithMaxOpenFiles(count int) option {
+	return func(s *Sink) {
+		s.maxOpenFiles = max(count, 1)
+	}
+}
+
+// WithNow sets the clock used for the date variables of the path template and for rotation. It defaults to
+// time.Now.
+func WithNow(now func() time.Time) option {
+	return func(s *Sink) {
+		s.now = now
+	}
+}
@@ -0,0 +1,87 @@
+package filesink
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"time"
+)
+
+const gzipExtension = ".gz"
+
+// rotate moves the file to its first backup and returns a new, empty file for the same path.
+func (s *Sink) rotate(f *file, now time.Time) (*file, error) {
+	s.evict(s.files[f.path])
+
+	if s.maxBackups == 0 {
+		if err := os.Remove(f.path); err != nil {
+			return nil, err
+		}
+		return s.open(f.path, now)
+	}
+
+	// Shift the backups, dropping the oldest. Both extensions are handled, so compression can be changed.
+	for i := s.maxBackups; i >= 1; i-- {
+		for _, extension := range []string{"", gzipExtension} {
+			from := backupPath(f.path, i) + extension
+			var err error
+			if i == s.maxBackups {
+				err = os.Remove(from)
+			} else {
+				err = os.Rename(from, backupPath(f.path, i+1)+extension)
+			}
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	backup := backupPath(f.path, 1)
+	if err := os.Rename(f.path, backup); err != nil {
+		return nil, err
+	}
+	if s.compress {
+		if err := compress(backup); err != nil {
+			return nil, err
+		}
+	}
+	return s.open(f.path, now)
+}
+
+func backupPath(path string, i int) string {
+	return path + "." + strconv.Itoa(i)
+}
+
+// compress replaces the file with a gzip compressed copy.
+func compress(path string) (err error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(path+gzipExtension, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path + gzipExtension)
+		}
+	}()
+
+	w := gzip.NewWriter(out)
+	if _, err := io.Copy(w, in); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
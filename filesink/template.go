@@ -0,0 +1,100 @@
+package filesink
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// pathTemplate is a compiled path template. Literal parts are kept as is, variables are replaced per message.
+type pathTemplate struct {
+	parts []pathPart
+}
+
+type pathPart struct {
+	literal string
+	value   func(m rfc5424.Message, now time.Time) string
+}
+
+var variables = map[string]func(m rfc5424.Message, now time.Time) string{
+	"HOST":     func(m rfc5424.Message, _ time.Time) string { return m.Hostname },
+	"APP":      func(m rfc5424.Message, _ time.Time) string { return m.AppName },
+	"FACILITY": func(m rfc5424.Message, _ time.Time) string { return m.PRI.FacilityName() },
+	"SEVERITY": func(m rfc5424.Message, _ time.Time) string { return m.PRI.SeverityName() },
+	"YEAR":     func(_ rfc5424.Message, now time.Time) string { return strconv.Itoa(now.Year()) },
+	"MONTH":    func(_ rfc5424.Message, now time.Time) string { return now.Format("01") },
+	"DAY":      func(_ rfc5424.Message, now time.Time) string { return now.Format("02") },
+	"HOUR":     func(_ rfc5424.Message, now time.Time) string { return now.Format("15") },
+}
+
+// compileTemplate parses a template such as "/var/log/remote/%HOST%/%APP%.log". A literal percent sign is written
+// as %%.
+func compileTemplate(template string) (pathTemplate, error) {
+	var (
+		t       pathTemplate
+		literal strings.Builder
+	)
+	for rest := template; rest != ""; {
+		before, after, found := strings.Cut(rest, "%")
+		literal.WriteString(before)
+		if !found {
+			break
+		}
+		name, after, found := strings.Cut(after, "%")
+		if !found {
+			return pathTemplate{}, ErrInvalidTemplate
+		}
+		rest = after
+		if name == "" {
+			literal.WriteByte('%')
+			continue
+		}
+		value, ok := variables[name]
+		if !ok {
+			return pathTemplate{}, ErrUnknownVariable
+		}
+		if literal.Len() > 0 {
+			t.parts = append(t.parts, pathPart{literal: literal.String()})
+			literal.Reset()
+		}
+		t.parts = append(t.parts, pathPart{value: value})
+	}
+	if literal.Len() > 0 {
+		t.parts = append(t.parts, pathPart{literal: literal.String()})
+	}
+	return t, nil
+}
+
+// expand returns the path for the message. Values are sanitized, so a message cannot select a path outside of the
+// template.
+func (t pathTemplate) expand(m rfc5424.Message, now time.Time) string {
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.value == nil {
+			b.WriteString(part.literal)
+			continue
+		}
+		b.WriteString(sanitize(part.value(m, now)))
+	}
+	return b.String()
+}
+
+// sanitize replaces the characters of a value that are not letters, digits, '.', '-' or '_' with '_'. An empty value
+// is replaced with "-", and "." and ".." with "_".
+func sanitize(value string) string {
+	switch value {
+	case "":
+		return "-"
+	case ".", "..":
+		return "_"
+	}
+	b := []byte(value)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,9 @@
+package gelf
+
+import "errors"
+
+var (
+	ErrTooManyChunks      = errors.New("message needs more than 128 chunks")
+	ErrInvalidChunkSize   = errors.New("invalid chunk size")
+	ErrUnknownCompression = errors.New("unknown compression")
+)
@@ -0,0 +1,128 @@
+// Package gelf converts parsed syslog messages to the Graylog Extended Log Format (GELF) 1.1 and sends them using the
+// chunked and compressed GELF UDP encodings.
+package gelf
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// FromRFC5424 converts an RFC5424 message to a GELF message. The first line of the MSG is used as the short message,
+// and the full MSG is included as the full message if it spans multiple lines. APP-NAME, PROCID and MSGID are added
+// as additional fields, as are the parameters of the structured data, named <SD-ID>_<PARAM-NAME>. Characters that
+// are not allowed in GELF field names are replaced by underscores.
+func FromRFC5424(m rfc5424.Message) (Message, error) {
+	g := Message{
+		Host:       nilValue(m.Hostname),
+		Timestamp:  m.Timestamp,
+		Level:      m.PRI.Severity(),
+		Facility:   m.PRI.FacilityName(),
+		Additional: map[string]any{},
+	}
+	g.ShortMessage, g.FullMessage = splitMessage(m.Message)
+
+	addField(g.Additional, "app_name", m.AppName)
+	addField(g.Additional, "procid", m.ProcID)
+	addField(g.Additional, "msgid", m.MsgID)
+
+	elements, err := m.Elements()
+	if err != nil {
+		return Message{}, err
+	}
+	for _, element := range elements {
+		for name, value := range element.Parameters {
+			addField(g.Additional, element.ID+"_"+name, value)
+		}
+	}
+	return g, nil
+}
+
+// converter holds the options of FromRFC3164.
+type converter struct {
+	now func() time.Time
+}
+
+// FromRFC3164 converts an RFC3164 message to a GELF message. The tag is added as an additional field, as is the PID
+// that follows it in square brackets. As RFC3164 timestamps have no year, a timestamp in year zero is assumed to be in
+// the current year.
+func FromRFC3164(m rfc3164.Message, options ...convertOption) Message {
+	c := converter{now: time.Now}
+	for _, option := range options {
+		option(&c)
+	}
+
+	timestamp := m.Timestamp
+	if !timestamp.IsZero() && timestamp.Year() == 0 {
+		timestamp = timestamp.AddDate(c.now().Year(), 0, 0)
+	}
+
+	g := Message{
+		Host:       nilValue(m.Hostname),
+		Timestamp:  timestamp,
+		Level:      m.PRI.Severity(),
+		Facility:   m.PRI.FacilityName(),
+		Additional: map[string]any{},
+	}
+	// The content starts at the character that ended the tag, which is not part of the message itself, optionally
+	// preceded by the PID: "[42]: message".
+	content, procID := m.Content, ""
+	if strings.HasPrefix(content, "[") {
+		if end := strings.Index(content, "]:"); end > 0 {
+			procID, content = content[1:end], content[end+1:]
+		}
+	}
+	if strings.HasPrefix(content, ":") {
+		content = strings.TrimLeft(content[1:], " ")
+	}
+	g.ShortMessage, g.FullMessage = splitMessage(content)
+	addField(g.Additional, "tag", m.Tag)
+	addField(g.Additional, "procid", procID)
+	return g
+}
+
+// splitMessage returns the first line of msg as the short message, and msg as the full message if it has more lines.
+// GELF requires a short message, so an empty message is replaced by "-".
+func splitMessage(msg string) (short string, full string) {
+	msg = strings.TrimRight(msg, "\r\n")
+	short, _, multiline := strings.Cut(msg, "\n")
+	short = strings.TrimRight(short, "\r")
+	if short == "" {
+		short = "-"
+	}
+	if multiline {
+		full = msg
+	}
+	return short, full
+}
+
+// addField adds a non-empty value to the additional fields, sanitizing the name to match ^[\w\.\-]*$. The field "id"
+// is reserved, so it is renamed to "id_".
+func addField(fields map[string]any, name, value string) {
+	if value == "" {
+		return
+	}
+	builder := strings.Builder{}
+	for _, r := range name {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteByte('_')
+		}
+	}
+	name = builder.String()
+	if name == "id" {
+		name = "id_"
+	}
+	fields[name] = value
+}
+
+// nilValue returns "-" for an empty string, as GELF requires a host.
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
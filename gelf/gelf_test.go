@@ -0,0 +1,230 @@
+//nolint:lll
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestFromRFC5424(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 42 ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\"][origin id=\"1\"] An application event log entry...\nwith a second line")
+	m, err := rfc5424.NewParser().Parse(bytes.NewReader(input))
+	assert.Nil(t, err)
+
+	g, err := FromRFC5424(m)
+	assert.Nil(t, err)
+	assert.Equal(t, Message{
+		Host:         "mymachine.example.com",
+		ShortMessage: "An application event log entry...",
+		FullMessage:  "An application event log entry...\nwith a second line",
+		Timestamp:    time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+		Level:        5,
+		Facility:     "local4",
+		Additional: map[string]any{
+			"app_name":                      "evntslog",
+			"procid":                        "42",
+			"msgid":                         "ID47",
+			"exampleSDID_32473_iut":         "3",
+			"exampleSDID_32473_eventSource": "Application",
+			"origin_id":                     "1",
+		},
+	}, g)
+
+	_, err = FromRFC5424(rfc5424.Message{StructuredData: "[invalid"})
+	assert.Equal(t, rfc5424.ErrInvalidStructuredData, err)
+}
+
+func TestFromRFC3164(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8")
+	m, err := rfc3164.NewParser().Parse(bytes.NewReader(input))
+	assert.Nil(t, err)
+
+	now := func() time.Time { return time.Date(2024, time.March, 4, 5, 6, 7, 0, time.UTC) }
+	g := FromRFC3164(m, WithNow(now))
+	assert.Equal(t, Message{
+		Host:         "mymachine",
+		ShortMessage: "'su root' failed for lonvick on /dev/pts/8",
+		Timestamp:    time.Date(2024, time.October, 11, 22, 14, 15, 0, time.UTC),
+		Level:        2,
+		Facility:     "auth",
+		Additional:   map[string]any{"tag": "su"},
+	}, g)
+
+	m, err = rfc3164.NewParser().Parse(bytes.NewReader([]byte("<38>Oct 11 22:14:15 mymachine sshd[42]: Accepted publickey for bob")))
+	assert.Nil(t, err)
+	g = FromRFC3164(m, WithNow(now))
+	assert.Equal(t, "Accepted publickey for bob", g.ShortMessage)
+	assert.Equal(t, map[string]any{"tag": "sshd", "procid": "42"}, g.Additional)
+
+	g = FromRFC3164(rfc3164.Message{})
+	assert.Equal(t, "-", g.Host)
+	assert.Equal(t, "-", g.ShortMessage)
+	assert.True(t, g.Timestamp.IsZero())
+}
+
+func TestMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	g := Message{
+		Host:         "example.org",
+		ShortMessage: "A short message",
+		FullMessage:  "A short message\nwith more",
+		Timestamp:    time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC),
+		Level:        1,
+		Facility:     "kern",
+		Additional:   map[string]any{"user_id": 9001, "some_info": "foo"},
+	}
+	data, err := json.Marshal(g)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"version": "1.1",
+		"host": "example.org",
+		"short_message": "A short message",
+		"full_message": "A short message\nwith more",
+		"timestamp": 1704164645.678,
+		"level": 1,
+		"facility": "kern",
+		"_user_id": 9001,
+		"_some_info": "foo"
+	}`, string(data))
+
+	data, err = json.Marshal(Message{Host: "h", ShortMessage: "s"})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"version": "1.1", "host": "h", "short_message": "s", "level": 0}`, string(data))
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	g := Message{Host: "h", ShortMessage: "s"}
+	expected, err := json.Marshal(g)
+	assert.Nil(t, err)
+
+	for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZlib} {
+		data, err := Encode(g, compression)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, decompress(t, data), compression)
+	}
+
+	_, err = Encode(g, Compression(42))
+	assert.Equal(t, ErrUnknownCompression, err)
+}
+
+func TestChunk(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(strings.Repeat("0123456789", 10))
+
+	chunks, err := Chunk(data, 200)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{data}, chunks)
+
+	chunks, err = Chunk(data, 42)
+	assert.Nil(t, err)
+	assert.Len(t, chunks, 4)
+	for i, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), 42)
+		assert.Equal(t, chunkMagic, chunk[:2])
+		assert.Equal(t, chunks[0][2:10], chunk[2:10])
+		assert.Equal(t, []byte{byte(i), 4}, chunk[10:12])
+	}
+	assert.Equal(t, data, reassemble(t, chunks))
+
+	_, err = Chunk(data, 12)
+	assert.Equal(t, ErrInvalidChunkSize, err)
+	_, err = Chunk(data, 65508)
+	assert.Equal(t, ErrInvalidChunkSize, err)
+
+	_, err = Chunk(make([]byte, 129*8), 20)
+	assert.Equal(t, ErrTooManyChunks, err)
+}
+
+func TestDialInvalidChunkSize(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{0, 12, 65508} {
+		_, err := Dial("127.0.0.1:12201", WithChunkSize(size))
+		assert.Equal(t, ErrInvalidChunkSize, err, size)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	w, err := Dial(conn.LocalAddr().String(), WithCompression(CompressionZlib), WithChunkSize(64))
+	assert.Nil(t, err)
+	defer w.Close()
+
+	g := Message{Host: "h", ShortMessage: "s", Additional: map[string]any{"payload": strings.Repeat("abc", 200)}}
+	assert.Nil(t, w.Write(g))
+
+	var chunks [][]byte
+	assert.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		buf := make([]byte, 128)
+		n, _, err := conn.ReadFrom(buf)
+		if !assert.Nil(t, err) {
+			return
+		}
+		chunks = append(chunks, buf[:n])
+		if len(chunks) == int(chunks[0][11]) {
+			break
+		}
+	}
+
+	var received map[string]any
+	assert.Nil(t, json.Unmarshal(decompress(t, reassemble(t, chunks)), &received))
+	assert.Equal(t, "h", received["host"])
+	assert.Equal(t, strings.Repeat("abc", 200), received["_payload"])
+}
+
+// reassemble joins chunks that were received in order.
+func reassemble(t *testing.T, chunks [][]byte) []byte {
+	t.Helper()
+	var data []byte
+	for i, chunk := range chunks {
+		assert.Equal(t, byte(i), chunk[10])
+		data = append(data, chunk[chunkHeaderSize:]...)
+	}
+	return data
+}
+
+// decompress detects the compression of the data in the same way as a GELF input does.
+func decompress(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var (
+		r   io.Reader
+		err error
+	)
+	switch {
+	case data[0] == 0x1f && data[1] == 0x8b:
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	case data[0] == 0x78:
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	default:
+		return data
+	}
+	assert.Nil(t, err)
+	out, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	return out
+}
@@ -0,0 +1,48 @@
+package gelf
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// Version is the GELF version produced by this package.
+const Version = "1.1"
+
+// Message represents a GELF 1.1 message.
+type Message struct {
+	Host         string
+	ShortMessage string
+	FullMessage  string
+	Timestamp    time.Time
+	// Level is the syslog severity of the message.
+	Level byte
+	// Facility is the name of the syslog facility. It is deprecated in GELF 1.1, but still accepted by Graylog.
+	Facility string
+	// Additional holds the additional fields. The keys are written with a leading underscore, which must not be
+	// included here.
+	Additional map[string]any
+}
+
+// MarshalJSON encodes the message as a GELF 1.1 JSON object with the additional fields at the top level.
+func (m Message) MarshalJSON() ([]byte, error) {
+	object := make(map[string]any, len(m.Additional)+7)
+	for key, value := range m.Additional {
+		object["_"+key] = value
+	}
+	object["version"] = Version
+	object["host"] = m.Host
+	object["short_message"] = m.ShortMessage
+	object["level"] = m.Level
+	if m.FullMessage != "" {
+		object["full_message"] = m.FullMessage
+	}
+	if !m.Timestamp.IsZero() {
+		// Seconds since the Unix epoch with millisecond precision.
+		object["timestamp"] = math.Round(float64(m.Timestamp.UnixNano())/1e6) / 1e3
+	}
+	if m.Facility != "" {
+		object["facility"] = m.Facility
+	}
+	return json.Marshal(object)
+}
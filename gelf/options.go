@@ -0,0 +1,29 @@
+package gelf
+
+import "time"
+
+type writerOption func(*Writer)
+
+// WithCompression sets the compression of the messages sent by the writer. It defaults to CompressionGzip.
+func WithCompression(compression Compression) writerOption {
+	return func(w *Writer) {
+		w.compression = compression
+	}
+}
+
+// WithChunkSize sets the maximum size of the datagrams sent by the writer. Larger messages are chunked. It defaults to
+// ChunkSizeWAN. Dial returns ErrInvalidChunkSize for a size of 12 bytes or less, or above 65507 bytes.
+func WithChunkSize(size int) writerOption {
+	return func(w *Writer) {
+		w.chunkSize = size
+	}
+}
+
+type convertOption func(*converter)
+
+// WithNow sets the clock that FromRFC3164 uses to determine the year of a timestamp. It defaults to time.Now.
+func WithNow(now func() time.Time) convertOption {
+	return func(c *converter) {
+		c.now = now
+	}
+}
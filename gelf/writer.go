@@ -0,0 +1,160 @@
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"net"
+)
+
+// Compression selects the compression applied to an encoded GELF message.
+type Compression int
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionZlib
+)
+
+const (
+	// ChunkSizeWAN is the datagram size recommended for sending GELF over the internet.
+	ChunkSizeWAN = 1420
+	// ChunkSizeLAN is the datagram size recommended for sending GELF within a local network.
+	ChunkSizeLAN = 8154
+
+	chunkHeaderSize = 12
+	maxChunks       = 128
+	// maxDatagramSize is the largest payload of a UDP datagram over IPv4.
+	maxDatagramSize = 65507
+)
+
+// chunkMagic is the start of every GELF chunk.
+var chunkMagic = []byte{0x1e, 0x0f}
+
+// Encode encodes the message as GELF JSON and compresses it.
+func Encode(m Message, compression Compression) ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		buf bytes.Buffer
+		w   interface {
+			Write([]byte) (int, error)
+			Close() error
+		}
+	)
+	switch compression {
+	case CompressionNone:
+		return data, nil
+	case CompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressionZlib:
+		w = zlib.NewWriter(&buf)
+	default:
+		return nil, ErrUnknownCompression
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Chunk splits the data into GELF chunks that each fit in a datagram of the given size. Data that already fits is
+// returned as a single unchunked datagram. A message can be split into at most 128 chunks. The size must be larger
+// than the 12 byte chunk header and fit in a UDP datagram.
+func Chunk(data []byte, size int) ([][]byte, error) {
+	if err := validChunkSize(size); err != nil {
+		return nil, err
+	}
+	if len(data) <= size {
+		return [][]byte{data}, nil
+	}
+
+	payload := size - chunkHeaderSize
+	count := (len(data) + payload - 1) / payload
+	if count > maxChunks {
+		return nil, ErrTooManyChunks
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*payload, len(data))
+		chunk := make([]byte, 0, chunkHeaderSize+end-i*payload)
+		chunk = append(chunk, chunkMagic...)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data[i*payload:end]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func validChunkSize(size int) error {
+	if size <= chunkHeaderSize || size > maxDatagramSize {
+		return ErrInvalidChunkSize
+	}
+	return nil
+}
+
+// Writer sends GELF messages over UDP.
+type Writer struct {
+	conn        net.Conn
+	compression Compression
+	chunkSize   int
+}
+
+// Dial creates a Writer that sends messages to the GELF UDP input at the given address. ErrInvalidChunkSize is
+// returned if the chunk size can not be used.
+func Dial(address string, options ...writerOption) (*Writer, error) {
+	w := &Writer{
+		compression: CompressionGzip,
+		chunkSize:   ChunkSizeWAN,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if err := validChunkSize(w.chunkSize); err != nil {
+		return nil, err
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	w.conn = conn
+	return w, nil
+}
+
+// Write encodes the message and sends it, chunked if needed.
+func (w *Writer) Write(m Message) error {
+	data, err := Encode(m, w.compression)
+	if err != nil {
+		return err
+	}
+	chunks, err := Chunk(data, w.chunkSize)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying connection.
+func (w *Writer) Close() error {
+	return w.conn.Close()
+}
@@ -0,0 +1,10 @@
+package journal
+
+import "errors"
+
+var (
+	ErrInvalidFieldName = errors.New("invalid field name")
+	ErrTruncated        = errors.New("truncated entry")
+	ErrInvalidPriority  = errors.New("invalid PRIORITY or SYSLOG_FACILITY")
+	ErrInvalidTimestamp = errors.New("invalid __REALTIME_TIMESTAMP")
+)
@@ -0,0 +1,232 @@
+// Package journal parses the systemd journal export format, as written by journalctl -o export, and converts the
+// entries to RFC5424 messages.
+package journal
+
+import (
+	"encoding/binary"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// SDID is the SD-ID of the structured data element that ToRFC5424 stores the unmapped fields in. It uses the private
+// enterprise number reserved for documentation by RFC 5612, so receivers should not rely on its uniqueness.
+const SDID = "journal@32473"
+
+// The defaults used when an entry has no PRIORITY or SYSLOG_FACILITY, which matches the behaviour of rsyslog.
+const (
+	defaultSeverity = 5
+	defaultFacility = 1
+)
+
+// mapped holds the fields that ToRFC5424 maps onto the header or MSG instead of the structured data.
+var mapped = map[string]bool{
+	"PRIORITY":             true,
+	"SYSLOG_FACILITY":      true,
+	"SYSLOG_IDENTIFIER":    true,
+	"_PID":                 true,
+	"_HOSTNAME":            true,
+	"__REALTIME_TIMESTAMP": true,
+	"MESSAGE":              true,
+}
+
+// The maximum lengths of the header fields, as defined in RFC5424.
+const (
+	maxHostname = 255
+	maxAppName  = 48
+	maxProcID   = 128
+)
+
+type Parser struct{}
+
+// NewParser creates a new Parser.
+func NewParser() Parser {
+	return Parser{}
+}
+
+// Parse parses a single entry from the input. Entries are terminated by an empty line, so consecutive entries can
+// be parsed from the same input. When the input holds no further entries io.EOF is returned.
+//
+// Fields are either written as text or, when the value contains control characters, in binary form:
+//
+//	NAME=value\n
+//	NAME\n<64 bit little endian length><value>\n
+func (p Parser) Parse(input io.ByteScanner) (Entry, error) {
+	var e Entry
+	for {
+		b, err := input.ReadByte()
+		if err != nil {
+			if len(e.Fields) == 0 {
+				return Entry{}, io.EOF
+			}
+			return e, nil
+		}
+		if b == '\n' {
+			if len(e.Fields) == 0 {
+				// Skip additional empty lines between entries.
+				continue
+			}
+			return e, nil
+		}
+		_ = input.UnreadByte()
+
+		field, err := parseField(input)
+		if err != nil {
+			return Entry{}, err
+		}
+		e.Fields = append(e.Fields, field)
+	}
+}
+
+func parseField(input io.ByteScanner) (Field, error) {
+	var name []byte
+	for {
+		b, err := input.ReadByte()
+		if err != nil {
+			return Field{}, ErrTruncated
+		}
+		if b == '=' || b == '\n' {
+			if !validFieldName(name) {
+				return Field{}, ErrInvalidFieldName
+			}
+			if b == '=' {
+				value, err := readLine(input)
+				return Field{Name: string(name), Value: value}, err
+			}
+			value, err := readBinary(input)
+			return Field{Name: string(name), Value: value}, err
+		}
+		name = append(name, b)
+	}
+}
+
+// readLine reads a text value up to the newline. The last line of the input may lack the newline.
+func readLine(input io.ByteScanner) (string, error) {
+	var value []byte
+	for {
+		b, err := input.ReadByte()
+		if err != nil || b == '\n' {
+			return string(value), nil
+		}
+		value = append(value, b)
+	}
+}
+
+// readBinary reads a length prefixed value and the newline following it. The value is read incrementally, so a
+// corrupt length does not cause a large allocation.
+func readBinary(input io.ByteScanner) (string, error) {
+	var length [8]byte
+	for i := range length {
+		b, err := input.ReadByte()
+		if err != nil {
+			return "", ErrTruncated
+		}
+		length[i] = b
+	}
+
+	n := binary.LittleEndian.Uint64(length[:])
+	var value []byte
+	for i := uint64(0); i < n; i++ {
+		b, err := input.ReadByte()
+		if err != nil {
+			return "", ErrTruncated
+		}
+		value = append(value, b)
+	}
+	if b, err := input.ReadByte(); err != nil || b != '\n' {
+		return "", ErrTruncated
+	}
+	return string(value), nil
+}
+
+// validFieldName checks the rules journald applies to field names: upper case letters, digits and underscores, not
+// starting with a digit.
+func validFieldName(name []byte) bool {
+	if len(name) == 0 || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, b := range name {
+		if (b < 'A' || b > 'Z') && (b < '0' || b > '9') && b != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// ToRFC5424 converts a journal entry to an RFC5424 message. PRIORITY and SYSLOG_FACILITY form the PRI, defaulting
+// to user.notice, and __REALTIME_TIMESTAMP, _HOSTNAME, SYSLOG_IDENTIFIER, _PID and MESSAGE fill the header and MSG.
+// The remaining fields are stored as parameters of an SDID element. When a field occurs more than once its last
+// value is used. Fields whose name exceeds the 32 characters of an SD-NAME or whose value is not valid UTF-8 are
+// left out. A _HOSTNAME, SYSLOG_IDENTIFIER or _PID that is not a valid header field, such as an identifier
+// containing a space, leaves the header field empty and is stored in the SDID element instead.
+func ToRFC5424(e Entry) (rfc5424.Message, error) {
+	m := rfc5424.Message{Version: 1}
+
+	severity, ok := parseNumber(e, "PRIORITY", defaultSeverity)
+	facility, ok2 := parseNumber(e, "SYSLOG_FACILITY", defaultFacility)
+	pri, err := common.NewPRIFromParts(facility, severity)
+	if !ok || !ok2 || err != nil {
+		return rfc5424.Message{}, ErrInvalidPriority
+	}
+	m.PRI = pri
+
+	if value, ok := e.Value("__REALTIME_TIMESTAMP"); ok {
+		usec, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return rfc5424.Message{}, ErrInvalidTimestamp
+		}
+		m.Timestamp = time.UnixMicro(usec).UTC()
+	}
+	invalid := map[string]bool{}
+	m.Hostname = headerField(e, "_HOSTNAME", maxHostname, invalid)
+	m.AppName = headerField(e, "SYSLOG_IDENTIFIER", maxAppName, invalid)
+	m.ProcID = headerField(e, "_PID", maxProcID, invalid)
+	m.Message, _ = e.Value("MESSAGE")
+
+	parameters := map[string]string{}
+	for _, field := range e.Fields {
+		if (mapped[field.Name] && !invalid[field.Name]) || len(field.Name) > 32 || !utf8.ValidString(field.Value) {
+			continue
+		}
+		parameters[field.Name] = field.Value
+	}
+	if len(parameters) > 0 {
+		elements := []rfc5424.StructuredDataElement{{ID: SDID, Parameters: parameters}}
+		m.StructuredDataElements = &elements
+		m.StructuredData = rfc5424.FormatStructuredData(elements)
+	}
+	return m, nil
+}
+
+// headerField returns the last value of the field if it consists of 1 to max printable US-ASCII characters, as
+// required for the HOSTNAME, APP-NAME and PROCID. Otherwise the name is added to invalid and an empty string is
+// returned.
+func headerField(e Entry, name string, max int, invalid map[string]bool) string {
+	value, ok := e.Value(name)
+	if !ok {
+		return ""
+	}
+	valid := value != "" && len(value) <= max
+	for i := 0; i < len(value) && valid; i++ {
+		valid = value[i] >= 33 && value[i] <= 126
+	}
+	if !valid {
+		invalid[name] = true
+		return ""
+	}
+	return value
+}
+
+// parseNumber parses the last value of the field, returning def if the field is not present.
+func parseNumber(e Entry, name string, def byte) (byte, bool) {
+	value, ok := e.Value(name)
+	if !ok {
+		return def, true
+	}
+	n, err := strconv.ParseUint(value, 10, 8)
+	return byte(n), err == nil
+}
@@ -0,0 +1,215 @@
+//nolint:lll
+package journal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// binaryField encodes a field in the binary form of the export format.
+func binaryField(name, value string) []byte {
+	field := append([]byte(name), '\n')
+	field = binary.LittleEndian.AppendUint64(field, uint64(len(value)))
+	field = append(field, value...)
+	return append(field, '\n')
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		input         []byte
+		expectedEntry Entry
+		expectedError error
+	}{
+		{
+			name:  "text fields",
+			input: []byte("__CURSOR=s=739ad463348b4ceca5a9e69c95a3c93f;i=4ece7\n__REALTIME_TIMESTAMP=1342540861416351\nMESSAGE=Started Login Service.\n\n"),
+			expectedEntry: Entry{Fields: []Field{
+				{Name: "__CURSOR", Value: "s=739ad463348b4ceca5a9e69c95a3c93f;i=4ece7"},
+				{Name: "__REALTIME_TIMESTAMP", Value: "1342540861416351"},
+				{Name: "MESSAGE", Value: "Started Login Service."},
+			}},
+		},
+		{
+			name:  "binary field",
+			input: append(append([]byte("PRIORITY=6\n"), binaryField("MESSAGE", "first line\nsecond line")...), "_PID=1\n"...),
+			expectedEntry: Entry{Fields: []Field{
+				{Name: "PRIORITY", Value: "6"},
+				{Name: "MESSAGE", Value: "first line\nsecond line"},
+				{Name: "_PID", Value: "1"},
+			}},
+		},
+		{
+			name:  "empty value and repeated name",
+			input: []byte("TAG=\nTAG=a\nTAG=b"),
+			expectedEntry: Entry{Fields: []Field{
+				{Name: "TAG", Value: ""},
+				{Name: "TAG", Value: "a"},
+				{Name: "TAG", Value: "b"},
+			}},
+		},
+		{
+			name:          "empty input",
+			input:         []byte("\n\n"),
+			expectedError: io.EOF,
+		},
+		{
+			name:          "lower case name",
+			input:         []byte("message=hello\n"),
+			expectedError: ErrInvalidFieldName,
+		},
+		{
+			name:          "name starting with a digit",
+			input:         []byte("1MESSAGE=hello\n"),
+			expectedError: ErrInvalidFieldName,
+		},
+		{
+			name:          "truncated name",
+			input:         []byte("MESSAGE"),
+			expectedError: ErrTruncated,
+		},
+		{
+			name:          "truncated binary value",
+			input:         binaryField("MESSAGE", "hello")[:12],
+			expectedError: ErrTruncated,
+		},
+		{
+			name:          "binary value without newline",
+			input:         binaryField("MESSAGE", "hello")[:16],
+			expectedError: ErrTruncated,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := NewParser().Parse(bytes.NewReader(tc.input))
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedEntry, e)
+		})
+	}
+}
+
+func TestParseConsecutive(t *testing.T) {
+	t.Parallel()
+
+	input := bytes.NewReader([]byte("MESSAGE=first\n\nMESSAGE=second\n\n"))
+	p := NewParser()
+
+	e, err := p.Parse(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []Field{{Name: "MESSAGE", Value: "first"}}, e.Fields)
+
+	e, err = p.Parse(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []Field{{Name: "MESSAGE", Value: "second"}}, e.Fields)
+
+	_, err = p.Parse(input)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestToRFC5424(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		entry           Entry
+		expectedMessage rfc5424.Message
+		expectedError   error
+	}{
+		{
+			name: "mapped and remaining fields",
+			entry: Entry{Fields: []Field{
+				{Name: "__REALTIME_TIMESTAMP", Value: "1342540861416351"},
+				{Name: "_BOOT_ID", Value: "dd6a3f1a3b294d79a14be6e3a1ac1d38"},
+				{Name: "PRIORITY", Value: "3"},
+				{Name: "SYSLOG_FACILITY", Value: "4"},
+				{Name: "SYSLOG_IDENTIFIER", Value: "sshd"},
+				{Name: "_PID", Value: "1234"},
+				{Name: "_HOSTNAME", Value: "web01"},
+				{Name: "_SYSTEMD_UNIT", Value: "ssh.service"},
+				{Name: "COREDUMP_THIS_NAME_IS_LONGER_THAN_32", Value: "skipped"},
+				{Name: "BINARY", Value: "\xff\xfe"},
+				{Name: "MESSAGE", Value: "Failed password for root"},
+			}},
+			expectedMessage: rfc5424.Message{
+				PRI:            common.MustPRI(35),
+				Version:        1,
+				Timestamp:      time.Date(2012, time.July, 17, 16, 1, 1, 416351000, time.UTC),
+				Hostname:       "web01",
+				AppName:        "sshd",
+				ProcID:         "1234",
+				StructuredData: `[journal@32473 _BOOT_ID="dd6a3f1a3b294d79a14be6e3a1ac1d38" _SYSTEMD_UNIT="ssh.service"]`,
+				StructuredDataElements: &[]rfc5424.StructuredDataElement{{
+					ID:         SDID,
+					Parameters: map[string]string{"_BOOT_ID": "dd6a3f1a3b294d79a14be6e3a1ac1d38", "_SYSTEMD_UNIT": "ssh.service"},
+				}},
+				Message: "Failed password for root",
+			},
+		},
+		{
+			name:  "defaults",
+			entry: Entry{Fields: []Field{{Name: "MESSAGE", Value: "hello"}}},
+			expectedMessage: rfc5424.Message{
+				PRI:     common.MustPRI(13),
+				Version: 1,
+				Message: "hello",
+			},
+		},
+		{
+			name: "invalid header fields",
+			entry: Entry{Fields: []Field{
+				{Name: "_HOSTNAME", Value: strings.Repeat("a", 256)},
+				{Name: "SYSLOG_IDENTIFIER", Value: "my app"},
+				{Name: "_PID", Value: "12\n34"},
+				{Name: "MESSAGE", Value: "hello"},
+			}},
+			expectedMessage: rfc5424.Message{
+				PRI:            common.MustPRI(13),
+				Version:        1,
+				StructuredData: `[journal@32473 SYSLOG_IDENTIFIER="my app" _HOSTNAME="` + strings.Repeat("a", 256) + "\" _PID=\"12\n34\"]",
+				StructuredDataElements: &[]rfc5424.StructuredDataElement{{
+					ID:         SDID,
+					Parameters: map[string]string{"_HOSTNAME": strings.Repeat("a", 256), "SYSLOG_IDENTIFIER": "my app", "_PID": "12\n34"},
+				}},
+				Message: "hello",
+			},
+		},
+		{
+			name:          "invalid priority",
+			entry:         Entry{Fields: []Field{{Name: "PRIORITY", Value: "8"}}},
+			expectedError: ErrInvalidPriority,
+		},
+		{
+			name:          "invalid facility",
+			entry:         Entry{Fields: []Field{{Name: "SYSLOG_FACILITY", Value: "local0"}}},
+			expectedError: ErrInvalidPriority,
+		},
+		{
+			name:          "invalid timestamp",
+			entry:         Entry{Fields: []Field{{Name: "__REALTIME_TIMESTAMP", Value: "yesterday"}}},
+			expectedError: ErrInvalidTimestamp,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := ToRFC5424(tc.entry)
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedMessage, m)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package journal
+
+// Entry represents a journal entry in the export format.
+type Entry struct {
+	// Fields holds the fields in the order of the export. A field name may occur more than once.
+	Fields []Field
+}
+
+// Field is a single field of an entry. The value can contain binary data.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Value returns the value of the last field with the name.
+func (e Entry) Value(name string) (string, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Name == name {
+			return e.Fields[i].Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,16 @@
+package kmsg
+
+import (
+	"errors"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+var (
+	ErrInvalidPRI        = common.ErrInvalidPRI
+	ErrInvalidSequence   = errors.New("invalid sequence number")
+	ErrInvalidTimestamp  = errors.New("invalid timestamp")
+	ErrInvalidFlag       = errors.New("invalid flag")
+	ErrInvalidRecord     = errors.New("invalid record")
+	ErrInvalidDictionary = errors.New("invalid dictionary line")
+)
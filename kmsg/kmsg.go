@@ -0,0 +1,184 @@
+// Package kmsg parses records read from the Linux /dev/kmsg interface.
+//
+// Each record consists of a prefix, the message and optional dictionary lines:
+//
+//	6,339,5140900,-;NET: Registered protocol family 10
+//	 SUBSYSTEM=net
+//	 DEVICE=n1
+package kmsg
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// DictionarySDID is the SD-ID of the structured data element that ToRFC5424 stores the dictionary in. It uses the
+// private enterprise number reserved for documentation by RFC 5612, so receivers should not rely on its uniqueness.
+const DictionarySDID = "kmsg@32473"
+
+type Parser struct{}
+
+// NewParser creates a new Parser.
+func NewParser() Parser {
+	return Parser{}
+}
+
+// Parse parses a single record from the input. Parsing stops after the last dictionary line, so consecutive records
+// can be parsed from the same input.
+func (p Parser) Parse(input io.ByteScanner) (Message, error) {
+	var m Message
+
+	prefix, err := readUntil(input, ';')
+	if err != nil {
+		return Message{}, ErrInvalidRecord
+	}
+	if err := parsePrefix(&m, prefix); err != nil {
+		return Message{}, err
+	}
+
+	// The message ends at a newline or at the end of the input, as a single read of /dev/kmsg may omit the newline
+	// after the last line.
+	line, _ := readUntil(input, '\n')
+	m.Message = unescape(line)
+
+	for {
+		b, err := input.ReadByte()
+		if err != nil {
+			break
+		}
+		if b != ' ' {
+			_ = input.UnreadByte()
+			break
+		}
+		line, _ := readUntil(input, '\n')
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || key == "" {
+			return Message{}, ErrInvalidDictionary
+		}
+		if m.Dictionary == nil {
+			m.Dictionary = map[string]string{}
+		}
+		m.Dictionary[key] = unescape(value)
+	}
+	return m, nil
+}
+
+// parsePrefix parses the comma separated prefix of a record.
+// PREFIX = PRI "," SEQUENCE "," TIMESTAMP "," FLAG *("," FIELD)
+func parsePrefix(m *Message, prefix string) error {
+	fields := strings.Split(prefix, ",")
+	if len(fields) < 4 {
+		return ErrInvalidRecord
+	}
+
+	value, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return ErrInvalidPRI
+	}
+	m.PRI, err = common.NewPRI(byte(value))
+	if err != nil {
+		return ErrInvalidPRI
+	}
+
+	m.Sequence, err = strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return ErrInvalidSequence
+	}
+
+	usec, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || usec < 0 {
+		return ErrInvalidTimestamp
+	}
+	m.Timestamp = time.Duration(usec) * time.Microsecond
+
+	if len(fields[3]) != 1 {
+		return ErrInvalidFlag
+	}
+	switch fields[3][0] {
+	case FlagNone, FlagContinuationStart, FlagContinuation:
+		m.Flag = fields[3][0]
+	default:
+		return ErrInvalidFlag
+	}
+
+	// Later kernels may append fields, of which only the caller is known.
+	for _, field := range fields[4:] {
+		if caller, ok := strings.CutPrefix(field, "caller="); ok {
+			m.Caller = caller
+		}
+	}
+	return nil
+}
+
+// ToRFC5424 converts a record to an RFC5424 message. As the record only holds the time since boot, the timestamp is
+// derived from boot, and left empty if boot is the zero time. The APP-NAME is set to "kernel" for the kernel
+// facility. The sequence number is stored in the meta element, the dictionary in a DictionarySDID element.
+func ToRFC5424(m Message, boot time.Time, hostname string) rfc5424.Message {
+	r := rfc5424.Message{
+		PRI:      m.PRI,
+		Version:  1,
+		Hostname: hostname,
+		Message:  m.Message,
+	}
+	if !boot.IsZero() {
+		r.Timestamp = boot.Add(m.Timestamp)
+	}
+	if m.PRI.Facility() == 0 {
+		r.AppName = "kernel"
+	}
+
+	elements := []rfc5424.StructuredDataElement{{
+		ID:         "meta",
+		Parameters: map[string]string{"sequenceId": strconv.FormatUint(m.Sequence, 10)},
+	}}
+	if len(m.Dictionary) > 0 {
+		parameters := make(map[string]string, len(m.Dictionary))
+		for key, value := range m.Dictionary {
+			parameters[key] = value
+		}
+		elements = append(elements, rfc5424.StructuredDataElement{ID: DictionarySDID, Parameters: parameters})
+	}
+	r.StructuredDataElements = &elements
+	r.StructuredData = rfc5424.FormatStructuredData(elements)
+	return r
+}
+
+// readUntil reads the input up to the delimiter, which is consumed but not returned. At the end of the input the
+// bytes read so far are returned together with io.EOF.
+func readUntil(input io.ByteScanner, delimiter byte) (string, error) {
+	builder := strings.Builder{}
+	for {
+		b, err := input.ReadByte()
+		if err != nil {
+			return builder.String(), io.EOF
+		}
+		if b == delimiter {
+			return builder.String(), nil
+		}
+		builder.WriteByte(b)
+	}
+}
+
+// unescape decodes the \xNN escapes the kernel uses for non-printable characters and backslashes.
+func unescape(value string) string {
+	if !strings.Contains(value, `\x`) {
+		return value
+	}
+	builder := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+3 < len(value) && value[i+1] == 'x' {
+			if b, err := strconv.ParseUint(value[i+2:i+4], 16, 8); err == nil {
+				builder.WriteByte(byte(b))
+				i += 3
+				continue
+			}
+		}
+		builder.WriteByte(value[i])
+	}
+	return builder.String()
+}
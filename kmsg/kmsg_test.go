@@ -0,0 +1,152 @@
+//nolint:lll
+package kmsg
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		input           []byte
+		expectedMessage Message
+		expectedError   error
+	}{
+		{
+			name:  "message",
+			input: []byte("6,339,5140900,-;NET: Registered protocol family 10\n"),
+			expectedMessage: Message{
+				PRI:       common.MustPRI(6),
+				Sequence:  339,
+				Timestamp: 5140900 * time.Microsecond,
+				Flag:      FlagNone,
+				Message:   "NET: Registered protocol family 10",
+			},
+		},
+		{
+			name:  "dictionary",
+			input: []byte("7,160,424069,-;pci_root PNP0A03:00: host bridge window [io  0x0000-0x0cf7] (ignored)\n SUBSYSTEM=acpi\n DEVICE=+acpi:PNP0A03:00\n"),
+			expectedMessage: Message{
+				PRI:        common.MustPRI(7),
+				Sequence:   160,
+				Timestamp:  424069 * time.Microsecond,
+				Flag:       FlagNone,
+				Message:    "pci_root PNP0A03:00: host bridge window [io  0x0000-0x0cf7] (ignored)",
+				Dictionary: map[string]string{"SUBSYSTEM": "acpi", "DEVICE": "+acpi:PNP0A03:00"},
+			},
+		},
+		{
+			name:  "escapes, continuation and caller without newline",
+			input: []byte(`12,1000,1,c,caller=T42;tab\x09and backslash\x5c`),
+			expectedMessage: Message{
+				PRI:       common.MustPRI(12),
+				Sequence:  1000,
+				Timestamp: time.Microsecond,
+				Flag:      FlagContinuationStart,
+				Caller:    "T42",
+				Message:   "tab\tand backslash\\",
+			},
+		},
+		{
+			name:          "missing separator",
+			input:         []byte("6,339,5140900,-"),
+			expectedError: ErrInvalidRecord,
+		},
+		{
+			name:          "missing fields",
+			input:         []byte("6,339;message"),
+			expectedError: ErrInvalidRecord,
+		},
+		{
+			name:          "invalid PRI",
+			input:         []byte("192,339,5140900,-;message"),
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "invalid sequence",
+			input:         []byte("6,-1,5140900,-;message"),
+			expectedError: ErrInvalidSequence,
+		},
+		{
+			name:          "invalid timestamp",
+			input:         []byte("6,339,abc,-;message"),
+			expectedError: ErrInvalidTimestamp,
+		},
+		{
+			name:          "invalid flag",
+			input:         []byte("6,339,5140900,x;message"),
+			expectedError: ErrInvalidFlag,
+		},
+		{
+			name:          "invalid dictionary line",
+			input:         []byte("6,339,5140900,-;message\n DEVICE\n"),
+			expectedError: ErrInvalidDictionary,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewParser().Parse(bytes.NewReader(tc.input))
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedMessage, m)
+		})
+	}
+}
+
+func TestParseConsecutive(t *testing.T) {
+	t.Parallel()
+
+	input := bytes.NewReader([]byte("6,1,10,-;first\n SUBSYSTEM=net\n6,2,20,+;second\n"))
+	p := NewParser()
+
+	m, err := p.Parse(input)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", m.Message)
+	assert.Equal(t, map[string]string{"SUBSYSTEM": "net"}, m.Dictionary)
+
+	m, err = p.Parse(input)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), m.Sequence)
+	assert.Equal(t, FlagContinuation, m.Flag)
+	assert.Equal(t, "second", m.Message)
+	assert.Nil(t, m.Dictionary)
+}
+
+func TestToRFC5424(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewParser().Parse(bytes.NewReader([]byte("6,339,5140900,-;NET: Registered protocol family 10\n SUBSYSTEM=net\n")))
+	assert.NoError(t, err)
+
+	boot := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	r := ToRFC5424(m, boot, "host")
+	assert.Equal(t, common.MustPRI(6), r.PRI)
+	assert.Equal(t, byte(1), r.Version)
+	assert.Equal(t, boot.Add(5140900*time.Microsecond), r.Timestamp)
+	assert.Equal(t, "host", r.Hostname)
+	assert.Equal(t, "kernel", r.AppName)
+	assert.Equal(t, `[meta sequenceId="339"][kmsg@32473 SUBSYSTEM="net"]`, r.StructuredData)
+	assert.Equal(t, "NET: Registered protocol family 10", r.Message)
+
+	elements, err := r.Elements()
+	assert.NoError(t, err)
+	assert.Equal(t, []rfc5424.StructuredDataElement{
+		{ID: "meta", Parameters: map[string]string{"sequenceId": "339"}},
+		{ID: DictionarySDID, Parameters: map[string]string{"SUBSYSTEM": "net"}},
+	}, elements)
+
+	m.PRI = common.MustPRI(14)
+	r = ToRFC5424(m, time.Time{}, "")
+	assert.True(t, r.Timestamp.IsZero())
+	assert.Equal(t, "", r.AppName)
+}
@@ -0,0 +1,31 @@
+package kmsg
+
+import (
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+// Flags of a record, which indicate how the kernel assembled the message from multiple printk calls.
+const (
+	FlagNone              byte = '-'
+	FlagContinuationStart byte = 'c'
+	FlagContinuation      byte = '+'
+)
+
+// Message represents a record read from /dev/kmsg.
+type Message struct {
+	// PRI combines the facility and log level, which is the kernel facility for messages of the kernel itself.
+	PRI      common.PRI
+	Sequence uint64
+	// Timestamp is the time since boot on the monotonic clock.
+	Timestamp time.Duration
+	Flag      byte
+	// Caller is the thread or CPU that logged the message, e.g. "T123", if the kernel was built with
+	// CONFIG_PRINTK_CALLER.
+	Caller string
+	// Message is the text of the record, with the \xNN escapes of the kernel decoded.
+	Message string
+	// Dictionary holds the KEY=value continuation lines, e.g. SUBSYSTEM and DEVICE.
+	Dictionary map[string]string
+}
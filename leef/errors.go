@@ -0,0 +1,11 @@
+package leef
+
+import "errors"
+
+var (
+	ErrNotLEEF          = errors.New("not a LEEF message")
+	ErrInvalidVersion   = errors.New("invalid LEEF version")
+	ErrInvalidHeader    = errors.New("invalid LEEF header")
+	ErrInvalidDelimiter = errors.New("invalid LEEF delimiter")
+	ErrInvalidAttribute = errors.New("invalid LEEF attribute")
+)
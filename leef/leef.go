@@ -0,0 +1,229 @@
+// Package leef parses IBM QRadar Log Event Extended Format (LEEF) 1.0 and 2.0 payloads as they are commonly sent in
+// the MSG of a syslog message.
+package leef
+
+import (
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+const prefix = "LEEF:"
+
+type Parser struct{}
+
+// NewParser creates a new Parser.
+func NewParser() Parser {
+	return Parser{}
+}
+
+// Parse parses a LEEF payload. Any text before the "LEEF:" prefix, such as a syslog header that was not stripped, is
+// ignored. If no LEEF payload is found ErrNotLEEF is returned.
+func (p Parser) Parse(input string) (Message, error) {
+	start := strings.Index(input, prefix)
+	if start < 0 {
+		return Message{}, ErrNotLEEF
+	}
+	input = input[start+len(prefix):]
+
+	version, input, ok := strings.Cut(input, "|")
+	if !ok {
+		return Message{}, ErrInvalidHeader
+	}
+
+	var headerFields int
+	switch version {
+	case "1.0":
+		headerFields = 4
+	case "2.0":
+		headerFields = 5
+	default:
+		return Message{}, ErrInvalidVersion
+	}
+
+	header := make([]string, 0, headerFields)
+	for len(header) < headerFields {
+		var field string
+		field, input, ok = strings.Cut(input, "|")
+		if !ok {
+			return Message{}, ErrInvalidHeader
+		}
+		header = append(header, field)
+	}
+
+	delimiter := '\t'
+	if version == "2.0" {
+		var err error
+		delimiter, err = parseDelimiter(header[4])
+		if err != nil {
+			return Message{}, err
+		}
+	}
+
+	attributes, err := parseAttributes(input, delimiter)
+	if err != nil {
+		return Message{}, err
+	}
+
+	m := Message{
+		Version:        version,
+		Vendor:         header[0],
+		Product:        header[1],
+		ProductVersion: header[2],
+		EventID:        header[3],
+		Delimiter:      delimiter,
+		Attributes:     attributes,
+		UsrName:        attributes["usrName"],
+	}
+	m.Src, _ = netip.ParseAddr(attributes["src"])
+	m.Dst, _ = netip.ParseAddr(attributes["dst"])
+	m.DevTime = parseDevTime(attributes["devTime"], attributes["devTimeFormat"])
+	return m, nil
+}
+
+// FromRFC3164 parses the LEEF payload of an RFC3164 message. As the RFC3164 parser splits the content on the first
+// ':', the tag and content are joined again before parsing.
+func (p Parser) FromRFC3164(m rfc3164.Message) (Message, error) {
+	return p.Parse(m.Tag + m.Content)
+}
+
+// FromRFC5424 parses the LEEF payload in the MSG of an RFC5424 message.
+func (p Parser) FromRFC5424(m rfc5424.Message) (Message, error) {
+	return p.Parse(m.Message)
+}
+
+// ParseRFC3164 parses a syslog message using the given RFC3164 parser and then parses its LEEF payload.
+func (p Parser) ParseRFC3164(parser rfc3164.Parser, input io.ByteScanner) (rfc3164.Message, Message, error) {
+	m, err := parser.Parse(input)
+	if err != nil {
+		return m, Message{}, err
+	}
+	l, err := p.FromRFC3164(m)
+	return m, l, err
+}
+
+// ParseRFC5424 parses a syslog message using the given RFC5424 parser and then parses its LEEF payload.
+func (p Parser) ParseRFC5424(parser rfc5424.Parser, input io.ByteScanner) (rfc5424.Message, Message, error) {
+	m, err := parser.Parse(input)
+	if err != nil {
+		return m, Message{}, err
+	}
+	l, err := p.FromRFC5424(m)
+	return m, l, err
+}
+
+// parseDelimiter parses the delimiter header field of LEEF 2.0. The delimiter is either a single character or its
+// hexadecimal code point prefixed by "x" or "0x". An empty field defaults to a tab.
+func parseDelimiter(field string) (rune, error) {
+	if field == "" {
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(field) == 1 {
+		r, _ := utf8.DecodeRuneInString(field)
+		return r, nil
+	}
+	var hex string
+	switch lower := strings.ToLower(field); {
+	case strings.HasPrefix(lower, "0x"):
+		hex = lower[2:]
+	case strings.HasPrefix(lower, "x"):
+		hex = lower[1:]
+	default:
+		return 0, ErrInvalidDelimiter
+	}
+	code, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || code == 0 || !utf8.ValidRune(rune(code)) {
+		return 0, ErrInvalidDelimiter
+	}
+	return rune(code), nil
+}
+
+// parseAttributes parses the key=value attributes separated by the delimiter. Empty attributes are skipped.
+func parseAttributes(input string, delimiter rune) (map[string]string, error) {
+	attributes := map[string]string{}
+	for _, attribute := range strings.Split(input, string(delimiter)) {
+		if strings.TrimSpace(attribute) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(attribute, "=")
+		if !ok || key == "" {
+			return nil, ErrInvalidAttribute
+		}
+		attributes[key] = value
+	}
+	return attributes, nil
+}
+
+// parseDevTime parses the devTime attribute using the Java SimpleDateFormat pattern in format. If format is empty,
+// the value is interpreted as milliseconds since the Unix epoch. On failure the zero time is returned.
+func parseDevTime(value, format string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if format == "" {
+		millis, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}
+		}
+		return time.UnixMilli(millis).UTC()
+	}
+	t, err := time.Parse(javaLayout(format), value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// javaLayouts maps the Java SimpleDateFormat pattern letters commonly used by LEEF senders onto Go layout elements.
+// Longer patterns come first so they take precedence.
+var javaLayouts = []struct {
+	pattern string
+	layout  string
+}{
+	{"yyyy", "2006"}, {"yy", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"dd", "02"}, {"d", "2"},
+	{"EEEE", "Monday"}, {"EEE", "Mon"},
+	{"HH", "15"}, {"hh", "03"}, {"h", "3"},
+	{"mm", "04"}, {"ss", "05"},
+	{"SSS", "000"},
+	{"a", "PM"},
+	{"z", "MST"}, {"XXX", "Z07:00"}, {"Z", "-0700"},
+}
+
+// javaLayout converts a Java SimpleDateFormat pattern to a Go time layout. Quoted literals are copied as is.
+func javaLayout(format string) string {
+	builder := strings.Builder{}
+	for i := 0; i < len(format); {
+		if format[i] == '\'' {
+			end := strings.IndexByte(format[i+1:], '\'')
+			if end < 0 {
+				builder.WriteString(format[i+1:])
+				break
+			}
+			builder.WriteString(format[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		matched := false
+		for _, l := range javaLayouts {
+			if strings.HasPrefix(format[i:], l.pattern) {
+				builder.WriteString(l.layout)
+				i += len(l.pattern)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			builder.WriteByte(format[i])
+			i++
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,181 @@
+//nolint:lll
+package leef
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		msg             string
+		expectedMessage Message
+		expectedError   error
+	}{
+		{
+			name: "valid message - LEEF 1.0",
+			msg:  "LEEF:1.0|Microsoft|MSExchange|4.0 SP1|15345|src=192.0.2.0\tdst=172.50.123.1\tsev=5\tcat=anomaly\tsrcPort=81\tdstPort=21\tusrName=joe.black",
+			expectedMessage: Message{
+				Version:        "1.0",
+				Vendor:         "Microsoft",
+				Product:        "MSExchange",
+				ProductVersion: "4.0 SP1",
+				EventID:        "15345",
+				Delimiter:      '\t',
+				Attributes: map[string]string{
+					"src":     "192.0.2.0",
+					"dst":     "172.50.123.1",
+					"sev":     "5",
+					"cat":     "anomaly",
+					"srcPort": "81",
+					"dstPort": "21",
+					"usrName": "joe.black",
+				},
+				Src:     netip.MustParseAddr("192.0.2.0"),
+				Dst:     netip.MustParseAddr("172.50.123.1"),
+				UsrName: "joe.black",
+			},
+		},
+		{
+			name: "valid message - LEEF 2.0 with character delimiter",
+			msg:  "LEEF:2.0|Lancope|StealthWatch|1.0|41|^|src=10.0.1.8^dst=10.0.0.5^sev=5^srcPort=81^dstPort=21",
+			expectedMessage: Message{
+				Version:        "2.0",
+				Vendor:         "Lancope",
+				Product:        "StealthWatch",
+				ProductVersion: "1.0",
+				EventID:        "41",
+				Delimiter:      '^',
+				Attributes: map[string]string{
+					"src":     "10.0.1.8",
+					"dst":     "10.0.0.5",
+					"sev":     "5",
+					"srcPort": "81",
+					"dstPort": "21",
+				},
+				Src: netip.MustParseAddr("10.0.1.8"),
+				Dst: netip.MustParseAddr("10.0.0.5"),
+			},
+		},
+		{
+			name: "valid message - LEEF 2.0 with hex delimiter and devTime",
+			msg:  "LEEF:2.0|Vendor|Product|1.0|login|0x7C|devTime=Jan 18 2024 10:15:30|devTimeFormat=MMM dd yyyy HH:mm:ss|usrName=alice|src=2001:db8::1",
+			expectedMessage: Message{
+				Version:        "2.0",
+				Vendor:         "Vendor",
+				Product:        "Product",
+				ProductVersion: "1.0",
+				EventID:        "login",
+				Delimiter:      '|',
+				Attributes: map[string]string{
+					"devTime":       "Jan 18 2024 10:15:30",
+					"devTimeFormat": "MMM dd yyyy HH:mm:ss",
+					"usrName":       "alice",
+					"src":           "2001:db8::1",
+				},
+				DevTime: time.Date(2024, time.January, 18, 10, 15, 30, 0, time.UTC),
+				Src:     netip.MustParseAddr("2001:db8::1"),
+				UsrName: "alice",
+			},
+		},
+		{
+			name: "valid message - epoch devTime and invalid address",
+			msg:  "LEEF:2.0|Vendor|Product|1.0|1|x09|devTime=1700000000123\tsrc=not-an-ip",
+			expectedMessage: Message{
+				Version:        "2.0",
+				Vendor:         "Vendor",
+				Product:        "Product",
+				ProductVersion: "1.0",
+				EventID:        "1",
+				Delimiter:      '\t',
+				Attributes: map[string]string{
+					"devTime": "1700000000123",
+					"src":     "not-an-ip",
+				},
+				DevTime: time.UnixMilli(1700000000123).UTC(),
+			},
+		},
+		{
+			name:          "invalid message - no prefix",
+			msg:           "1.0|Vendor|Product|1.0|1|",
+			expectedError: ErrNotLEEF,
+		},
+		{
+			name:          "invalid message - version",
+			msg:           "LEEF:3.0|Vendor|Product|1.0|1|",
+			expectedError: ErrInvalidVersion,
+		},
+		{
+			name:          "invalid message - missing header fields",
+			msg:           "LEEF:2.0|Vendor|Product|1.0|1",
+			expectedError: ErrInvalidHeader,
+		},
+		{
+			name:          "invalid message - delimiter",
+			msg:           "LEEF:2.0|Vendor|Product|1.0|1|abc|a=b",
+			expectedError: ErrInvalidDelimiter,
+		},
+		{
+			name:          "invalid message - attribute",
+			msg:           "LEEF:1.0|Vendor|Product|1.0|1|a=b\tnovalue",
+			expectedError: ErrInvalidAttribute,
+		},
+	}
+
+	for _, tc := range testcases {
+		msg, err := NewParser().Parse(tc.msg)
+		assert.Equal(t, tc.expectedMessage, msg, tc.name)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+	}
+}
+
+func TestJavaLayout(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		format         string
+		expectedLayout string
+	}{
+		{format: "MMM dd yyyy HH:mm:ss", expectedLayout: "Jan 02 2006 15:04:05"},
+		{format: "yyyy-MM-dd'T'HH:mm:ss.SSSZ", expectedLayout: "2006-01-02T15:04:05.000-0700"},
+		{format: "EEE, d MMMM yy hh:mm a z", expectedLayout: "Mon, 2 January 06 03:04 PM MST"},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expectedLayout, javaLayout(tc.format), tc.format)
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<13>Jan 18 11:07:53 192.168.1.1 LEEF:1.0|QRadar|QRM|1.0|NEW_PORT_DISCOVERD|src=172.5.6.67\tdst=172.50.123.1")
+	m, l, err := NewParser().ParseRFC3164(rfc3164.NewParser(), bytes.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, "192.168.1.1", m.Hostname)
+	assert.Equal(t, "NEW_PORT_DISCOVERD", l.EventID)
+	assert.Equal(t, netip.MustParseAddr("172.5.6.67"), l.Src)
+}
+
+func TestParseRFC5424(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<13>1 2024-01-18T11:07:53Z 192.168.1.1 qradar - - - LEEF:2.0|QRadar|QRM|1.0|42|;|usrName=bob;dst=10.0.0.1")
+	m, l, err := NewParser().ParseRFC5424(rfc5424.NewParser(), bytes.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, "qradar", m.AppName)
+	assert.Equal(t, "bob", l.UsrName)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), l.Dst)
+
+	_, err = NewParser().FromRFC3164(rfc3164.Message{Content: "not leef"})
+	assert.Equal(t, ErrNotLEEF, err)
+}
@@ -0,0 +1,31 @@
+package leef
+
+import (
+	"net/netip"
+	"time"
+)
+
+// Message represents an IBM QRadar Log Event Extended Format (LEEF) event.
+// LEEF:1.0|Vendor|Product|Version|EventID|key=value<tab>key=value
+// LEEF:2.0|Vendor|Product|Version|EventID|DelimiterCharacter|key=value<delimiter>key=value
+type Message struct {
+	// Version is either "1.0" or "2.0".
+	Version        string
+	Vendor         string
+	Product        string
+	ProductVersion string
+	EventID        string
+	// Delimiter separates the attributes. It is a tab for LEEF 1.0 and can be chosen by the sender for LEEF 2.0.
+	Delimiter  rune
+	Attributes map[string]string
+
+	// The known attributes below are converted to their types when present. If a value can not be converted, the
+	// typed field is left empty. The raw value is always available in Attributes.
+
+	// DevTime is the devTime attribute, parsed according to the devTimeFormat attribute. Without a format, devTime
+	// is expected to be milliseconds since the Unix epoch.
+	DevTime time.Time
+	Src     netip.Addr
+	Dst     netip.Addr
+	UsrName string
+}
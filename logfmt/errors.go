@@ -0,0 +1,8 @@
+package logfmt
+
+import "errors"
+
+var (
+	ErrTooManyPairs      = errors.New("too many key=value pairs")
+	ErrUnterminatedQuote = errors.New("unterminated quoted value")
+)
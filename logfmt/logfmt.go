@@ -0,0 +1,163 @@
+// Package logfmt extracts logfmt-style key=value pairs, such as "user=bob action=login status=ok", from the message
+// bodies of parsed syslog messages.
+package logfmt
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// DefaultMaxPairs is the default maximum number of pairs that are extracted from a single message.
+const DefaultMaxPairs = 64
+
+type Parser struct {
+	maxPairs int
+}
+
+// NewParser creates a new Parser with the provided options.
+func NewParser(options ...parseOption) Parser {
+	p := Parser{maxPairs: DefaultMaxPairs}
+	for _, option := range options {
+		option(&p)
+	}
+	return p
+}
+
+// Parse extracts the key=value pairs from the input. Values are either bare, ending at the next whitespace, or
+// double quoted, in which case they can contain whitespace and Go escape sequences such as \" and \n. Words that are
+// not part of a pair are skipped, so pairs can be extracted from free text. If a key occurs more than once, the last
+// value is kept. Only the quotes of a quoted value must be terminated, an unmatched quote in free text is skipped as
+// plain text. When the maximum number of pairs is exceeded, the pairs up to the maximum are returned together with
+// ErrTooManyPairs.
+func (p Parser) Parse(input string) (map[string]string, error) {
+	fields := map[string]string{}
+	s := scanner{input: input}
+	for {
+		pair, ok, err := s.next()
+		if err != nil {
+			return fields, err
+		}
+		if !ok {
+			return fields, nil
+		}
+		if _, exists := fields[pair.key]; !exists && p.maxPairs > 0 && len(fields) == p.maxPairs {
+			return fields, ErrTooManyPairs
+		}
+		fields[pair.key] = pair.value
+	}
+}
+
+// FromRFC3164 extracts the pairs from the content of an RFC3164 message. The message is returned with the pairs, also
+// when extracting them fails.
+func (p Parser) FromRFC3164(m rfc3164.Message) (RFC3164Message, error) {
+	fields, err := p.Parse(m.Content)
+	return RFC3164Message{Message: m, Fields: fields}, err
+}
+
+// FromRFC5424 extracts the pairs from the MSG of an RFC5424 message. The message is returned with the pairs, also
+// when extracting them fails.
+func (p Parser) FromRFC5424(m rfc5424.Message) (RFC5424Message, error) {
+	fields, err := p.Parse(m.Message)
+	return RFC5424Message{Message: m, Fields: fields}, err
+}
+
+// ParseRFC3164 parses a syslog message using the given RFC3164 parser and then extracts the pairs from its content.
+func (p Parser) ParseRFC3164(parser rfc3164.Parser, input io.ByteScanner) (RFC3164Message, error) {
+	m, err := parser.Parse(input)
+	if err != nil {
+		return RFC3164Message{Message: m}, err
+	}
+	return p.FromRFC3164(m)
+}
+
+// ParseRFC5424 parses a syslog message using the given RFC5424 parser and then extracts the pairs from its MSG.
+func (p Parser) ParseRFC5424(parser rfc5424.Parser, input io.ByteScanner) (RFC5424Message, error) {
+	m, err := parser.Parse(input)
+	if err != nil {
+		return RFC5424Message{Message: m}, err
+	}
+	return p.FromRFC5424(m)
+}
+
+type pair struct {
+	key   string
+	value string
+}
+
+// scanner reads the pairs from its input one by one.
+type scanner struct {
+	input string
+}
+
+// next returns the next pair in the input, skipping words that are not pairs. At the end of the input ok is false.
+func (s *scanner) next() (p pair, ok bool, err error) {
+	for {
+		s.input = strings.TrimLeft(s.input, " \t\r\n")
+		if s.input == "" {
+			return pair{}, false, nil
+		}
+
+		end := strings.IndexAny(s.input, "= \t\r\n\"")
+		switch {
+		case end < 0:
+			// A final word without a value.
+			s.input = ""
+		case s.input[end] == '"':
+			// A quoted word in free text, which is skipped as a whole. An unmatched quote is plain text.
+			var rest string
+			if _, rest, err = parseQuoted(s.input[end:]); err != nil {
+				rest = s.input[end+1:]
+			}
+			s.input = rest
+		case s.input[end] != '=':
+			s.input = s.input[end:]
+		case end == 0:
+			// A lone '=', which is not a pair.
+			s.input = s.input[1:]
+		default:
+			p.key = s.input[:end]
+			s.input = s.input[end+1:]
+			if strings.HasPrefix(s.input, "\"") {
+				p.value, s.input, err = parseQuoted(s.input)
+				if err != nil {
+					return pair{}, false, err
+				}
+				return p, true, nil
+			}
+			end = strings.IndexAny(s.input, " \t\r\n")
+			if end < 0 {
+				end = len(s.input)
+			}
+			p.value, s.input = s.input[:end], s.input[end:]
+			return p, true, nil
+		}
+	}
+}
+
+// parseQuoted parses a double quoted value from the start of the input and returns it unquoted along with the
+// remainder of the input.
+func parseQuoted(input string) (value, rest string, err error) {
+	escapes := false
+	for i := 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			escapes = true
+			i++
+		case '"':
+			if !escapes {
+				return input[1:i], input[i+1:], nil
+			}
+			value, err := strconv.Unquote(input[:i+1])
+			if err != nil {
+				// Not a valid Go escape sequence, keep the value as written.
+				return input[1:i], input[i+1:], nil //nolint:nilerr
+			}
+			return value, input[i+1:], nil
+		}
+	}
+	return "", "", ErrUnterminatedQuote
+}
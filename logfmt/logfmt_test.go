@@ -0,0 +1,122 @@
+package logfmt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name           string
+		msg            string
+		options        []parseOption
+		expectedFields map[string]string
+		expectedError  error
+	}{
+		{
+			name:           "bare values",
+			msg:            "user=bob action=login status=ok",
+			expectedFields: map[string]string{"user": "bob", "action": "login", "status": "ok"},
+		},
+		{
+			name:           "quoted values",
+			msg:            `msg="login failed" path="C:\\temp" quote="say \"hi\"\n"`,
+			expectedFields: map[string]string{"msg": "login failed", "path": `C:\temp`, "quote": "say \"hi\"\n"},
+		},
+		{
+			name:           "free text is skipped",
+			msg:            `Accepted password for bob "from the outside" = port=22 ssh2 user=bob`,
+			expectedFields: map[string]string{"port": "22", "user": "bob"},
+		},
+		{
+			name:           "empty and duplicate values",
+			msg:            "a= b=1 b=2",
+			expectedFields: map[string]string{"a": "", "b": "2"},
+		},
+		{
+			name:           "invalid escape is kept as written",
+			msg:            `path="C:\dir"`,
+			expectedFields: map[string]string{"path": `C:\dir`},
+		},
+		{
+			name:           "no pairs",
+			msg:            "just some text",
+			expectedFields: map[string]string{},
+		},
+		{
+			name:           "unterminated quote",
+			msg:            `a=1 msg="unterminated`,
+			expectedFields: map[string]string{"a": "1"},
+			expectedError:  ErrUnterminatedQuote,
+		},
+		{
+			name:           "unmatched quote before pairs",
+			msg:            `it"s user=bob action=login`,
+			expectedFields: map[string]string{"user": "bob", "action": "login"},
+		},
+		{
+			name:           "unmatched quote between pairs",
+			msg:            `user=bob said "hi action=login`,
+			expectedFields: map[string]string{"user": "bob", "action": "login"},
+		},
+		{
+			name:           "too many pairs",
+			msg:            "a=1 b=2 a=3 c=4",
+			options:        []parseOption{WithMaxPairs(2)},
+			expectedFields: map[string]string{"a": "3", "b": "2"},
+			expectedError:  ErrTooManyPairs,
+		},
+		{
+			name:           "no limit",
+			msg:            "a=1 b=2 c=3",
+			options:        []parseOption{WithMaxPairs(0)},
+			expectedFields: map[string]string{"a": "1", "b": "2", "c": "3"},
+		},
+	}
+
+	for _, tc := range testcases {
+		fields, err := NewParser(tc.options...).Parse(tc.msg)
+		assert.Equal(t, tc.expectedFields, fields, tc.name)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<38>Oct 11 22:14:15 host app[42]: user=bob action=login status=ok")
+	m, err := NewParser().ParseRFC3164(rfc3164.NewParser(), bytes.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, "app", m.Tag)
+	assert.Equal(t, map[string]string{"user": "bob", "action": "login", "status": "ok"}, m.Fields)
+}
+
+func TestParseRFC5424(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`<38>1 2003-10-11T22:14:15.003Z host app - - - user=bob msg="logged in"`)
+	m, err := NewParser().ParseRFC5424(rfc5424.NewParser(), bytes.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, "app", m.AppName)
+	assert.Equal(t, map[string]string{"user": "bob", "msg": "logged in"}, m.Fields)
+
+	_, err = NewParser().ParseRFC5424(rfc5424.NewParser(), bytes.NewReader([]byte("invalid")))
+	assert.Equal(t, rfc5424.ErrInvalidPRI, err)
+}
+
+func BenchmarkParse(b *testing.B) {
+	p := NewParser()
+	msg := `level=info user=bob action=login status=ok msg="user logged in" duration=12ms`
+	for i := 0; i < b.N; i++ {
+		_, err := p.Parse(msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
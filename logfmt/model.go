@@ -0,0 +1,18 @@
+package logfmt
+
+import (
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// RFC3164Message is an RFC3164 message together with the pairs extracted from its content.
+type RFC3164Message struct {
+	rfc3164.Message
+	Fields map[string]string
+}
+
+// RFC5424Message is an RFC5424 message together with the pairs extracted from its MSG.
+type RFC5424Message struct {
+	rfc5424.Message
+	Fields map[string]string
+}
@@ -0,0 +1,11 @@
+package logfmt
+
+type parseOption func(*Parser)
+
+// WithMaxPairs sets the maximum number of pairs that are extracted from a message. It defaults to DefaultMaxPairs.
+// A maximum of zero or less disables the limit.
+func WithMaxPairs(max int) parseOption {
+	return func(p *Parser) {
+		p.maxPairs = max
+	}
+}
@@ -0,0 +1,28 @@
+package pipeline
+
+type option func(*config)
+
+type config struct {
+	workers int
+	buffer  int
+}
+
+// WithWorkers sets the number of goroutines that parse frames concurrently. It defaults to runtime.GOMAXPROCS(0).
+func WithWorkers(workers int) option {
+	return func(c *config) {
+		if workers > 0 {
+			c.workers = workers
+		}
+	}
+}
+
+// WithBufferSize sets the number of frames that can be in flight at once. Once this many frames have been read from
+// the input without their results being received, the pipeline stops reading the input. It defaults to twice the
+// number of workers.
+func WithBufferSize(size int) option {
+	return func(c *config) {
+		if size > 0 {
+			c.buffer = size
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package pipeline parses syslog frames concurrently while keeping the results in the order of the input.
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// Parser is implemented by the parsers of the format packages, e.g. rfc3164.Parser and rfc5424.Parser. As the parsers
+// are stateless value types, a single parser is shared by all workers.
+type Parser[M any] interface {
+	Parse(input io.ByteScanner) (M, error)
+}
+
+// Result is the outcome of parsing a single frame.
+type Result[M any] struct {
+	Frame   []byte
+	Message M
+	Err     error
+}
+
+// Pipeline parses frames across multiple goroutines and emits the results in the order in which the frames were
+// received.
+type Pipeline[M any] struct {
+	parser Parser[M]
+	config config
+}
+
+type job[M any] struct {
+	frame  []byte
+	result chan Result[M]
+}
+
+// New creates a new Pipeline using the given parser and options.
+func New[M any](parser Parser[M], options ...option) *Pipeline[M] {
+	c := config{workers: runtime.GOMAXPROCS(0)}
+	for _, option := range options {
+		option(&c)
+	}
+	if c.buffer == 0 {
+		c.buffer = 2 * c.workers
+	}
+	return &Pipeline[M]{parser: parser, config: c}
+}
+
+// Run starts parsing the frames received on in and returns the channel on which the results are emitted in order.
+// The returned channel is closed once in is closed and all results have been emitted, or once ctx is cancelled. The
+// pipeline applies backpressure: when the results are not received, it stops reading from in once its buffer is full.
+func (p *Pipeline[M]) Run(ctx context.Context, in <-chan []byte) <-chan Result[M] {
+	out := make(chan Result[M])
+	jobs := make(chan job[M])
+	// pending holds the result channels in input order. Its capacity limits the number of frames in flight.
+	pending := make(chan chan Result[M], p.config.buffer)
+
+	var wg sync.WaitGroup
+	wg.Add(p.config.workers)
+	for i := 0; i < p.config.workers; i++ {
+		go func() {
+			defer wg.Done()
+			p.work(jobs)
+		}()
+	}
+
+	go p.dispatch(ctx, in, jobs, pending)
+
+	go func() {
+		defer close(out)
+		defer wg.Wait()
+		for result := range pending {
+			select {
+			case r := <-result:
+				select {
+				case out <- r:
+				case <-ctx.Done():
+					drain(pending)
+					return
+				}
+			case <-ctx.Done():
+				drain(pending)
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// dispatch reads frames from in and hands them to the workers, recording the order of the results in pending.
+func (p *Pipeline[M]) dispatch(ctx context.Context, in <-chan []byte, jobs chan<- job[M], pending chan<- chan Result[M]) {
+	defer close(pending)
+	defer close(jobs)
+	for {
+		var (
+			frame []byte
+			ok    bool
+		)
+		select {
+		case frame, ok = <-in:
+			if !ok {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+
+		// The result channel is buffered so a worker never blocks on a result that is no longer received.
+		j := job[M]{frame: frame, result: make(chan Result[M], 1)}
+		select {
+		case pending <- j.result:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case jobs <- j:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// work parses the frames of the received jobs until jobs is closed.
+func (p *Pipeline[M]) work(jobs <-chan job[M]) {
+	var reader bytes.Reader
+	for j := range jobs {
+		reader.Reset(j.frame)
+		m, err := p.parser.Parse(&reader)
+		j.result <- Result[M]{Frame: j.frame, Message: m, Err: err}
+	}
+}
+
+// drain empties pending until it is closed, so the dispatcher is not blocked on it after a cancellation.
+func drain[M any](pending <-chan chan Result[M]) {
+	for range pending { //nolint:revive // Only draining the channel.
+	}
+}
@@ -0,0 +1,128 @@
+//nolint:lll
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	const n = 1000
+
+	in := make(chan []byte)
+	go func() {
+		defer close(in)
+		for i := 0; i < n; i++ {
+			if i%10 == 0 {
+				in <- []byte("invalid")
+				continue
+			}
+			in <- []byte(fmt.Sprintf("<34>1 2003-10-11T22:14:15.003Z host su - ID47 - message %d", i))
+		}
+	}()
+
+	p := New(rfc5424.NewParser(), WithWorkers(8))
+	i := 0
+	for result := range p.Run(context.Background(), in) {
+		if i%10 == 0 {
+			assert.Equal(t, rfc5424.ErrInvalidPRI, result.Err, i)
+			assert.Equal(t, []byte("invalid"), result.Frame, i)
+		} else {
+			assert.Nil(t, result.Err, i)
+			assert.Equal(t, fmt.Sprintf("message %d", i), result.Message.Message, i)
+		}
+		i++
+	}
+	assert.Equal(t, n, i)
+}
+
+func TestRunRFC3164(t *testing.T) {
+	t.Parallel()
+
+	in := make(chan []byte, 2)
+	in <- []byte("<34>Oct 11 22:14:15 mymachine su: first")
+	in <- []byte("<34>Oct 11 22:14:15 mymachine su: second")
+	close(in)
+
+	p := New(rfc3164.NewParser())
+	results := []string{}
+	for result := range p.Run(context.Background(), in) {
+		assert.Nil(t, result.Err)
+		results = append(results, result.Message.Content)
+	}
+	assert.Equal(t, []string{": first", ": second"}, results)
+}
+
+func TestRunBackpressure(t *testing.T) {
+	t.Parallel()
+
+	const n = 100
+
+	in := make(chan []byte, n)
+	for i := 0; i < n; i++ {
+		in <- []byte("<34>1 2003-10-11T22:14:15.003Z host su - ID47 - message")
+	}
+	close(in)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := New(rfc5424.NewParser(), WithWorkers(2), WithBufferSize(4))
+	out := p.Run(ctx, in)
+
+	// Without receiving results, at most the buffer, the workers and the dispatcher hold frames.
+	time.Sleep(50 * time.Millisecond)
+	assert.GreaterOrEqual(t, len(in), n-4-2-1)
+
+	count := 0
+	for range out {
+		count++
+	}
+	assert.Equal(t, n, count)
+}
+
+func TestRunCancel(t *testing.T) {
+	t.Parallel()
+
+	in := make(chan []byte)
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(rfc5424.NewParser(), WithWorkers(4))
+	out := p.Run(ctx, in)
+
+	in <- []byte("<34>1 2003-10-11T22:14:15.003Z host su - ID47 - message")
+	result := <-out
+	assert.Nil(t, result.Err)
+
+	cancel()
+	select {
+	case _, ok := <-out:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("output was not closed after cancellation")
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	msg := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"] An application event log entry...")
+	in := make(chan []byte)
+	go func() {
+		defer close(in)
+		for i := 0; i < b.N; i++ {
+			in <- msg
+		}
+	}()
+
+	p := New(rfc5424.NewParser(rfc5424.WithParseStructuredDataElements()))
+	for result := range p.Run(context.Background(), in) {
+		if result.Err != nil {
+			b.Fatal(result.Err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package relay
+
+import (
+	"net"
+	"strconv"
+	"time"
+)
+
+// dialTimeout limits the time spent connecting to a TCP destination.
+const dialTimeout = 5 * time.Second
+
+// Destination is a target messages are forwarded to. Send is only called from a single goroutine.
+type Destination interface {
+	Send(frame []byte) error
+	Close() error
+}
+
+// UDPDestination sends one message per datagram.
+type UDPDestination struct {
+	conn net.Conn
+}
+
+// NewUDPDestination creates a UDPDestination for the address, e.g. "collector.example.com:514".
+func NewUDPDestination(address string) (*UDPDestination, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPDestination{conn: conn}, nil
+}
+
+func (d *UDPDestination) Send(frame []byte) error {
+	_, err := d.conn.Write(frame)
+	return err
+}
+
+func (d *UDPDestination) Close() error {
+	return d.conn.Close()
+}
+
+// TCPDestination sends messages over TCP using octet counting framing. The connection is established on the first
+// Send and re-established on the next Send after a failure.
+type TCPDestination struct {
+	address string
+	conn    net.Conn
+	buf     []byte
+}
+
+// NewTCPDestination creates a TCPDestination for the address.
+func NewTCPDestination(address string) *TCPDestination {
+	return &TCPDestination{address: address}
+}
+
+func (d *TCPDestination) Send(frame []byte) error {
+	if d.conn == nil {
+		conn, err := net.DialTimeout("tcp", d.address, dialTimeout)
+		if err != nil {
+			return err
+		}
+		d.conn = conn
+	}
+
+	d.buf = strconv.AppendInt(d.buf[:0], int64(len(frame)), 10)
+	d.buf = append(d.buf, ' ')
+	d.buf = append(d.buf, frame...)
+	if _, err := d.conn.Write(d.buf); err != nil {
+		d.conn.Close()
+		d.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (d *TCPDestination) Close() error {
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
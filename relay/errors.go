@@ -0,0 +1,9 @@
+package relay
+
+import "errors"
+
+var (
+	ErrFrameTooLarge = errors.New("frame exceeds the maximum size")
+	ErrInvalidFrame  = errors.New("invalid octet counting frame")
+	ErrUnparseable   = errors.New("message is neither RFC5424 nor RFC3164")
+)
@@ -0,0 +1,211 @@
+package relay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync"
+)
+
+// maxFrameSize is the maximum size of a received frame.
+const maxFrameSize = 64 * 1024
+
+// Listener receives frames from the network.
+type Listener interface {
+	// Serve receives frames and sends them to frames until ctx is cancelled or the listener fails. A frame that
+	// could not be received, e.g. because its framing is invalid, is sent with Err set.
+	Serve(ctx context.Context, frames chan<- Frame) error
+	// Addr returns the local address of the listener.
+	Addr() net.Addr
+}
+
+// UDPListener receives one message per datagram, as defined in RFC 5426.
+type UDPListener struct {
+	conn net.PacketConn
+}
+
+// ListenUDP creates a UDPListener on the address, e.g. ":514".
+func ListenUDP(address string) (*UDPListener, error) {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPListener{conn: conn}, nil
+}
+
+func (l *UDPListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// Serve receives datagrams until ctx is cancelled, after which the socket is closed. A trailing newline, which some
+// senders add, is removed.
+func (l *UDPListener) Serve(ctx context.Context, frames chan<- Frame) error {
+	stop := context.AfterFunc(ctx, func() { l.conn.Close() })
+	defer stop()
+
+	buf := make([]byte, maxFrameSize)
+	for {
+		n, addr, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		data := buf[:n]
+		if n > 0 && data[n-1] == '\n' {
+			data = data[:n-1]
+		}
+		frame := Frame{Data: append([]byte(nil), data...), Remote: remoteAddr(addr)}
+		select {
+		case frames <- frame:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// TCPListener receives messages over TCP, as described in RFC 6587. The framing is detected per message: octet
+// counting when the frame starts with a digit, and newline terminated otherwise.
+type TCPListener struct {
+	listener net.Listener
+}
+
+// ListenTCP creates a TCPListener on the address, e.g. ":514".
+func ListenTCP(address string) (*TCPListener, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &TCPListener{listener: listener}, nil
+}
+
+func (l *TCPListener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Serve accepts connections until ctx is cancelled, after which the listener and all connections are closed.
+// Connections that send an invalid frame are closed, after the error is sent as a Frame with Err set.
+func (l *TCPListener) Serve(ctx context.Context, frames chan<- Frame) error {
+	var (
+		mu    sync.Mutex
+		conns = map[net.Conn]bool{}
+		wg    sync.WaitGroup
+	)
+	stop := context.AfterFunc(ctx, func() {
+		l.listener.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for conn := range conns {
+			conn.Close()
+		}
+	})
+	defer stop()
+	defer wg.Wait()
+
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		if ctx.Err() != nil {
+			mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		conns[conn] = true
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				mu.Lock()
+				delete(conns, conn)
+				mu.Unlock()
+				conn.Close()
+			}()
+			readFrames(ctx, conn, remoteAddr(conn.RemoteAddr()), frames)
+		}()
+	}
+}
+
+// readFrames reads frames from the connection until it is closed or sends an invalid frame. An invalid frame is sent
+// as a Frame with Err set.
+func readFrames(ctx context.Context, conn io.Reader, remote netip.Addr, frames chan<- Frame) {
+	reader := bufio.NewReader(conn)
+	for {
+		data, err := readFrame(reader)
+		if err != nil && !errors.Is(err, ErrInvalidFrame) && !errors.Is(err, ErrFrameTooLarge) {
+			return
+		}
+		select {
+		case frames <- Frame{Data: data, Remote: remote, Err: err}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readFrame reads a frame using octet counting ("LENGTH SP MSG") or non-transparent framing ("MSG LF").
+func readFrame(reader *bufio.Reader) ([]byte, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if first[0] >= '1' && first[0] <= '9' {
+		prefix, err := reader.ReadSlice(' ')
+		if err != nil {
+			return nil, ErrInvalidFrame
+		}
+		length, err := strconv.Atoi(string(prefix[:len(prefix)-1]))
+		if err != nil {
+			return nil, ErrInvalidFrame
+		}
+		if length > maxFrameSize {
+			return nil, ErrFrameTooLarge
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	var data []byte
+	for {
+		line, err := reader.ReadSlice('\n')
+		data = append(data, line...)
+		if len(data) > maxFrameSize {
+			return nil, ErrFrameTooLarge
+		}
+		if err == nil {
+			return data[:len(data)-1], nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			if len(data) > 0 && errors.Is(err, io.EOF) {
+				return data, nil
+			}
+			return nil, err
+		}
+	}
+}
+
+func remoteAddr(addr net.Addr) netip.Addr {
+	if addrPort, err := netip.ParseAddrPort(addr.String()); err == nil {
+		return addrPort.Addr().Unmap()
+	}
+	return netip.Addr{}
+}
@@ -0,0 +1,64 @@
+package relay
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Frame is a single message received by a Listener.
+type Frame struct {
+	Data []byte
+	// Remote is the address of the sender.
+	Remote netip.Addr
+	// Err is set instead of Data when the frame could not be received. It is passed to the error handler of the
+	// relay.
+	Err error
+}
+
+// Origin holds the parameters of the origin structured data element that the relay adds, see RFC 5424 section 7.2.
+// Empty parameters are left out.
+type Origin struct {
+	IP           netip.Addr
+	EnterpriseID string
+	Software     string
+	// SoftwareVersion is the swVersion parameter.
+	SoftwareVersion string
+}
+
+func (o Origin) element() rfc5424.StructuredDataElement {
+	parameters := map[string]string{}
+	if o.IP.IsValid() {
+		parameters["ip"] = o.IP.String()
+	}
+	optional := map[string]string{
+		"enterpriseId": o.EnterpriseID,
+		"software":     o.Software,
+		"swVersion":    o.SoftwareVersion,
+	}
+	for name, value := range optional {
+		if value = strings.TrimSpace(value); value != "" {
+			parameters[name] = value
+		}
+	}
+	return rfc5424.StructuredDataElement{ID: "origin", Parameters: parameters}
+}
+
+// Stats holds the counters of a Relay.
+type Stats struct {
+	Received uint64
+	// Invalid is the number of received frames that could not be read or parsed and were not forwarded.
+	Invalid uint64
+	// Destinations holds the counters of each destination, in the order in which they were passed to New.
+	Destinations []DestinationStats
+}
+
+// DestinationStats holds the counters of a single destination.
+type DestinationStats struct {
+	Forwarded uint64
+	// Dropped is the number of messages discarded because the queue of the destination was full.
+	Dropped uint64
+	// Failed is the number of messages that could not be sent.
+	Failed uint64
+}
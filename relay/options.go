@@ -0,0 +1,57 @@
+package relay
+
+import (
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+type option func(*Relay)
+
+// WithStampHostname sets the HOSTNAME of messages without one to the address of the sender.
+func WithStampHostname() option {
+	return func(r *Relay) {
+		r.stampHostname = true
+	}
+}
+
+// WithPRI overrides the PRI of every forwarded message.
+func WithPRI(pri common.PRI) option {
+	return func(r *Relay) {
+		r.pri = &pri
+	}
+}
+
+// WithOrigin adds an origin element to messages that do not have one yet. Existing origin elements are never
+// changed, as they describe the originator.
+func WithOrigin(origin Origin) option {
+	return func(r *Relay) {
+		element := origin.element()
+		r.origin = &element
+	}
+}
+
+// WithLocation sets the time zone of RFC3164 timestamps, which are converted to RFC5424 before forwarding. It
+// defaults to time.UTC.
+func WithLocation(loc *time.Location) option {
+	return func(r *Relay) {
+		r.location = loc
+	}
+}
+
+// WithQueueSize sets the number of messages that are buffered per destination. Messages are dropped when the queue
+// is full. It defaults to 1024.
+func WithQueueSize(size int) option {
+	return func(r *Relay) {
+		r.queueSize = size
+	}
+}
+
+// WithErrorHandler sets a function that is called with errors that occur while receiving, rewriting and sending
+// messages. These errors do not stop the relay. As every destination is sent to from its own goroutine, the handler
+// is called concurrently and must be safe for concurrent use.
+func WithErrorHandler(handler func(error)) option {
+	return func(r *Relay) {
+		r.onError = handler
+	}
+}
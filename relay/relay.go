@@ -0,0 +1,231 @@
+// Package relay receives syslog messages from listeners, optionally rewrites them and forwards them to one or more
+// destinations, each with its own queue.
+//
+// Following RFC 5424 section 4.3, messages are forwarded unchanged unless a rewrite is configured. RFC3164 messages
+// are converted to RFC5424 before they are forwarded.
+package relay
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/convert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Relay forwards the messages received by its listeners to its destinations.
+type Relay struct {
+	listeners []Listener
+	queues    []*queue
+
+	stampHostname bool
+	pri           *common.PRI
+	origin        *rfc5424.StructuredDataElement
+	location      *time.Location
+	queueSize     int
+	onError       func(error)
+
+	// The parsers and converter are built once by New, as rewrite is called for every frame.
+	rfc5424Parser rfc5424.Parser
+	rfc3164Parser rfc3164.Parser
+	converter     convert.Converter
+
+	received atomic.Uint64
+	invalid  atomic.Uint64
+}
+
+// queue holds the messages for a single destination.
+type queue struct {
+	destination Destination
+	frames      chan []byte
+
+	forwarded atomic.Uint64
+	dropped   atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// New creates a Relay that forwards the messages of the listeners to the destinations.
+func New(listeners []Listener, destinations []Destination, options ...option) *Relay {
+	r := &Relay{
+		listeners: listeners,
+		location:  time.UTC,
+		queueSize: 1024,
+		onError:   func(error) {},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	r.rfc5424Parser = rfc5424.NewParser()
+	r.rfc3164Parser = rfc3164.NewParser()
+	r.converter = convert.NewConverter(convert.WithLocation(r.location))
+	for _, destination := range destinations {
+		r.queues = append(r.queues, &queue{destination: destination, frames: make(chan []byte, r.queueSize)})
+	}
+	return r
+}
+
+// Run starts the listeners and forwards messages until ctx is cancelled or a listener fails. The messages that are
+// still queued are sent before the destinations are closed and Run returns. The error of the first listener that
+// failed is returned.
+func (r *Relay) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	frames := make(chan Frame, r.queueSize)
+	var (
+		listeners sync.WaitGroup
+		errOnce   sync.Once
+		runErr    error
+	)
+	for _, listener := range r.listeners {
+		listeners.Add(1)
+		go func() {
+			defer listeners.Done()
+			if err := listener.Serve(ctx, frames); err != nil {
+				errOnce.Do(func() { runErr = err })
+				cancel()
+			}
+		}()
+	}
+
+	var senders sync.WaitGroup
+	for _, q := range r.queues {
+		senders.Add(1)
+		go func() {
+			defer senders.Done()
+			r.send(q)
+		}()
+	}
+
+	go func() {
+		listeners.Wait()
+		close(frames)
+	}()
+	for frame := range frames {
+		r.forward(frame)
+	}
+
+	for _, q := range r.queues {
+		close(q.frames)
+	}
+	senders.Wait()
+	return runErr
+}
+
+// forward rewrites a frame and adds it to the queue of every destination. A frame with Err set is counted as invalid.
+func (r *Relay) forward(frame Frame) {
+	r.received.Add(1)
+	err := frame.Err
+	var data []byte
+	if err == nil {
+		data, err = r.rewrite(frame)
+	}
+	if err != nil {
+		r.invalid.Add(1)
+		r.onError(err)
+		return
+	}
+	for _, q := range r.queues {
+		select {
+		case q.frames <- data:
+		default:
+			q.dropped.Add(1)
+		}
+	}
+}
+
+// send delivers the queued messages to the destination. A failed message is retried once, as TCP destinations
+// reconnect on the next attempt.
+func (r *Relay) send(q *queue) {
+	defer func() {
+		if err := q.destination.Close(); err != nil {
+			r.onError(err)
+		}
+	}()
+	for frame := range q.frames {
+		err := q.destination.Send(frame)
+		if err != nil {
+			err = q.destination.Send(frame)
+		}
+		if err != nil {
+			q.failed.Add(1)
+			r.onError(err)
+			continue
+		}
+		q.forwarded.Add(1)
+	}
+}
+
+// rewrite applies the configured rewrites. RFC5424 messages that need no rewriting are forwarded unchanged.
+func (r *Relay) rewrite(frame Frame) ([]byte, error) {
+	m, err := r.rfc5424Parser.Parse(bytes.NewReader(frame.Data))
+	changed := false
+	if err != nil {
+		legacy, err := r.rfc3164Parser.Parse(bytes.NewReader(frame.Data))
+		if err != nil {
+			return nil, ErrUnparseable
+		}
+		m = r.converter.ToRFC5424(legacy)
+		changed = true
+	}
+
+	if r.stampHostname && m.Hostname == "" && frame.Remote.IsValid() {
+		m.Hostname = frame.Remote.String()
+		changed = true
+	}
+	if r.pri != nil && m.PRI != *r.pri {
+		m.PRI = *r.pri
+		changed = true
+	}
+	if r.origin != nil {
+		added, err := addOrigin(&m, *r.origin)
+		if err != nil {
+			return nil, err
+		}
+		changed = changed || added
+	}
+
+	if !changed {
+		return frame.Data, nil
+	}
+	return m.Append(nil), nil
+}
+
+// addOrigin adds the origin element to the message if it does not have one, as an SD-ID may only occur once.
+func addOrigin(m *rfc5424.Message, origin rfc5424.StructuredDataElement) (bool, error) {
+	elements, err := m.Elements()
+	if err != nil {
+		return false, err
+	}
+	for _, element := range elements {
+		if element.ID == origin.ID {
+			return false, nil
+		}
+	}
+	// The element is appended to the raw structured data, so the existing elements are forwarded unchanged.
+	m.StructuredData += origin.String()
+	m.StructuredDataElements = nil
+	return true, nil
+}
+
+// Stats returns the current counters of the relay.
+func (r *Relay) Stats() Stats {
+	s := Stats{
+		Received:     r.received.Load(),
+		Invalid:      r.invalid.Load(),
+		Destinations: make([]DestinationStats, len(r.queues)),
+	}
+	for i, q := range r.queues {
+		s.Destinations[i] = DestinationStats{
+			Forwarded: q.forwarded.Load(),
+			Dropped:   q.dropped.Load(),
+			Failed:    q.failed.Load(),
+		}
+	}
+	return s
+}
@@ -0,0 +1,309 @@
+//nolint:lll
+package relay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/common"
+)
+
+func TestRewrite(t *testing.T) {
+	t.Parallel()
+
+	remote := netip.MustParseAddr("192.0.2.1")
+	origin := Origin{IP: netip.MustParseAddr("192.0.2.10"), Software: "relay"}
+
+	tests := []struct {
+		name     string
+		options  []option
+		input    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "unchanged",
+			input:    "<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\"]  spacing is kept",
+			expected: "<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\"]  spacing is kept",
+		},
+		{
+			name:     "RFC3164 is converted",
+			input:    "<34>Oct 11 22:14:15 mymachine su: 'su root' failed",
+			expected: "<34>1 " + time.Now().Format("2006") + "-10-11T22:14:15Z mymachine su - - - 'su root' failed",
+		},
+		{
+			name:     "hostname is stamped",
+			options:  []option{WithStampHostname()},
+			input:    "<165>1 2003-10-11T22:14:15Z - app - - - message",
+			expected: "<165>1 2003-10-11T22:14:15Z 192.0.2.1 app - - - message",
+		},
+		{
+			name:     "existing hostname is kept",
+			options:  []option{WithStampHostname()},
+			input:    "<165>1 2003-10-11T22:14:15Z host app - - - message",
+			expected: "<165>1 2003-10-11T22:14:15Z host app - - - message",
+		},
+		{
+			name:     "PRI is overridden",
+			options:  []option{WithPRI(common.MustPRI(16<<3 | 3))},
+			input:    "<165>1 2003-10-11T22:14:15Z host app - - - message",
+			expected: "<131>1 2003-10-11T22:14:15Z host app - - - message",
+		},
+		{
+			name:     "origin is added",
+			options:  []option{WithOrigin(origin)},
+			input:    "<165>1 2003-10-11T22:14:15Z host app - - [a@32473 b=\"c\"] message",
+			expected: "<165>1 2003-10-11T22:14:15Z host app - - [a@32473 b=\"c\"][origin ip=\"192.0.2.10\" software=\"relay\"] message",
+		},
+		{
+			name:     "origin is added to empty structured data",
+			options:  []option{WithOrigin(origin)},
+			input:    "<165>1 2003-10-11T22:14:15Z host app - - - message",
+			expected: "<165>1 2003-10-11T22:14:15Z host app - - [origin ip=\"192.0.2.10\" software=\"relay\"] message",
+		},
+		{
+			name:     "existing origin is kept",
+			options:  []option{WithOrigin(origin)},
+			input:    "<165>1 2003-10-11T22:14:15Z host app - - [origin ip=\"198.51.100.1\"] message",
+			expected: "<165>1 2003-10-11T22:14:15Z host app - - [origin ip=\"198.51.100.1\"] message",
+		},
+		{
+			name:     "existing origin after other elements is kept",
+			options:  []option{WithOrigin(origin)},
+			input:    "<165>1 2003-10-11T22:14:15Z host app - - [a@32473 b=\"c\"][origin ip=\"198.51.100.1\"] message",
+			expected: "<165>1 2003-10-11T22:14:15Z host app - - [a@32473 b=\"c\"][origin ip=\"198.51.100.1\"] message",
+		},
+		{
+			name:     "existing origin is kept when the PRI is rewritten",
+			options:  []option{WithOrigin(origin), WithPRI(common.MustPRI(16<<3 | 3))},
+			input:    "<165>1 2003-10-11T22:14:15Z host app - - [origin ip=\"198.51.100.1\"] message",
+			expected: "<131>1 2003-10-11T22:14:15Z host app - - [origin ip=\"198.51.100.1\"] message",
+		},
+		{
+			name:  "unparseable",
+			input: "not syslog",
+			err:   ErrUnparseable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(nil, nil, tt.options...)
+			output, err := r.rewrite(Frame{Data: []byte(tt.input), Remote: remote})
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, string(output))
+		})
+	}
+}
+
+func TestReadFrame(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		err      error
+	}{
+		{
+			name:     "octet counting",
+			input:    "11 <13>1 - - -12 <13>1 - - \n-",
+			expected: []string{"<13>1 - - -", "<13>1 - - \n-"},
+			err:      nil,
+		},
+		{
+			name:     "non-transparent",
+			input:    "<13>1 a\n<13>1 b\n<13>1 c",
+			expected: []string{"<13>1 a", "<13>1 b", "<13>1 c"},
+		},
+		{
+			name:     "mixed",
+			input:    "7 <13>1 a<13>1 b\n",
+			expected: []string{"<13>1 a", "<13>1 b"},
+		},
+		{
+			name:  "invalid length",
+			input: "1x <13>1 a",
+			err:   ErrInvalidFrame,
+		},
+		{
+			name:  "too large",
+			input: "99999999 <13>1 a",
+			err:   ErrFrameTooLarge,
+		},
+		{
+			name:  "line too large",
+			input: "<13>1 " + strings.Repeat("a", maxFrameSize),
+			err:   ErrFrameTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader := bufio.NewReader(strings.NewReader(tt.input))
+			var frames []string
+			for {
+				data, err := readFrame(reader)
+				if err != nil {
+					if tt.err != nil {
+						assert.Equal(t, tt.err, err)
+					}
+					break
+				}
+				frames = append(frames, string(data))
+			}
+			assert.Equal(t, tt.expected, frames)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	udp, err := ListenUDP("127.0.0.1:0")
+	assert.Nil(t, err)
+	tcp, err := ListenTCP("127.0.0.1:0")
+	assert.Nil(t, err)
+
+	// The relay forwards to a second relay over TCP, which forwards to a recorder.
+	collector, err := ListenTCP("127.0.0.1:0")
+	assert.Nil(t, err)
+	received := &recorder{frames: make(chan []byte, 16)}
+	downstream := New([]Listener{collector}, []Destination{received})
+
+	local := &recorder{frames: make(chan []byte, 16)}
+	errs := &errorLog{}
+	r := New(
+		[]Listener{udp, tcp},
+		[]Destination{NewTCPDestination(collector.Addr().String()), local},
+		WithStampHostname(),
+		WithErrorHandler(errs.handle),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 2)
+	go func() { done <- downstream.Run(ctx) }()
+	go func() { done <- r.Run(ctx) }()
+
+	conn, err := net.Dial("udp", udp.Addr().String())
+	assert.Nil(t, err)
+	_, err = conn.Write([]byte("<13>1 2003-10-11T22:14:15Z - app - - - over udp\n"))
+	assert.Nil(t, err)
+	conn.Close()
+
+	expected := "<13>1 2003-10-11T22:14:15Z 127.0.0.1 app - - - over udp"
+	assert.Equal(t, expected, string(receive(t, local.frames)))
+	assert.Equal(t, expected, string(receive(t, received.frames)))
+
+	conn, err = net.Dial("tcp", tcp.Addr().String())
+	assert.Nil(t, err)
+	_, err = conn.Write([]byte("not syslog\n37 <13>1 2003-10-11T22:14:15Z h a - - - "))
+	assert.Nil(t, err)
+	conn.Close()
+
+	assert.Equal(t, "<13>1 2003-10-11T22:14:15Z h a - - - ", string(receive(t, local.frames)))
+	assert.Equal(t, "<13>1 2003-10-11T22:14:15Z h a - - - ", string(receive(t, received.frames)))
+
+	conn, err = net.Dial("tcp", tcp.Addr().String())
+	assert.Nil(t, err)
+	_, err = conn.Write([]byte("1x <13>1 a"))
+	assert.Nil(t, err)
+	conn.Close()
+	assert.Eventually(t, func() bool { return len(errs.get()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.Nil(t, <-done)
+	assert.Nil(t, <-done)
+	assert.Equal(t, []error{ErrUnparseable, ErrInvalidFrame}, errs.get())
+	assert.True(t, local.closed)
+
+	assert.Equal(t, Stats{
+		Received: 4,
+		Invalid:  2,
+		Destinations: []DestinationStats{
+			{Forwarded: 2},
+			{Forwarded: 2},
+		},
+	}, r.Stats())
+}
+
+func TestForward(t *testing.T) {
+	t.Parallel()
+
+	failing := &recorder{err: errors.New("unreachable")}
+	errs := &errorLog{}
+	r := New(nil, []Destination{failing}, WithQueueSize(1), WithErrorHandler(errs.handle))
+
+	r.forward(Frame{Data: []byte("<13>1 - - - - - - first")})
+	r.forward(Frame{Data: []byte("<13>1 - - - - - - second")})
+	assert.Equal(t, []DestinationStats{{Dropped: 1}}, r.Stats().Destinations)
+
+	close(r.queues[0].frames)
+	r.send(r.queues[0])
+	assert.Equal(t, []DestinationStats{{Dropped: 1, Failed: 1}}, r.Stats().Destinations)
+	assert.Equal(t, 2, failing.attempts)
+	assert.Equal(t, []error{failing.err}, errs.get())
+}
+
+// errorLog collects the errors passed to the error handler, which is called concurrently.
+type errorLog struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (l *errorLog) handle(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errs = append(l.errs, err)
+}
+
+func (l *errorLog) get() []error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]error(nil), l.errs...)
+}
+
+// recorder is a Destination that records the frames it is sent.
+type recorder struct {
+	frames   chan []byte
+	err      error
+	attempts int
+	closed   bool
+}
+
+func (r *recorder) Send(frame []byte) error {
+	r.attempts++
+	if r.err != nil {
+		return r.err
+	}
+	r.frames <- frame
+	return nil
+}
+
+func (r *recorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func receive(t *testing.T, frames <-chan []byte) []byte {
+	t.Helper()
+	select {
+	case frame := <-frames:
+		return frame
+	case <-time.After(5 * time.Second):
+		t.Fatal("no frame received")
+		return nil
+	}
+}
@@ -1,9 +1,15 @@
 package rfc3164
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/ysmilda/syslog/common"
+)
 
 var (
-	ErrInvalidPRI       = errors.New("invalid PRI")
+	ErrInvalidPRI       = common.ErrInvalidPRI
+	ErrFiltered         = common.ErrFiltered
+	ErrInvalidFacility  = common.ErrInvalidFacility
 	ErrInvalidTimestamp = errors.New("invalid timestamp")
 	ErrInvalidHostname  = errors.New("invalid hostname")
 )
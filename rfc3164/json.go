@@ -0,0 +1,75 @@
+package rfc3164
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+// jsonMessage is the JSON representation of a Message, see MarshalJSON for the schema.
+type jsonMessage struct {
+	PRI       *PRI       `json:"pri,omitempty"`
+	Facility  string     `json:"facility,omitempty"`
+	Severity  string     `json:"severity,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Hostname  string     `json:"hostname,omitempty"`
+	Tag       string     `json:"tag,omitempty"`
+	Content   string     `json:"content,omitempty"`
+}
+
+// MarshalJSON encodes the message as a JSON object with the following fields. Empty fields are omitted.
+//
+//	pri        number  The raw PRI value.
+//	facility   string  The facility keyword, e.g. "auth".
+//	severity   string  The severity keyword, e.g. "crit".
+//	timestamp  string  The TIMESTAMP in RFC3339 format with nanoseconds. As RFC3164 has no year, it is year 0000.
+//	hostname   string  The HOSTNAME.
+//	tag        string  The TAG.
+//	content    string  The CONTENT.
+func (m Message) MarshalJSON() ([]byte, error) {
+	pri := m.PRI
+	j := jsonMessage{
+		PRI:      &pri,
+		Facility: pri.FacilityName(),
+		Severity: pri.SeverityName(),
+		Hostname: m.Hostname,
+		Tag:      m.Tag,
+		Content:  m.Content,
+	}
+	if !m.Timestamp.IsZero() {
+		j.Timestamp = &m.Timestamp
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a message in the format written by MarshalJSON. If pri is missing, it is derived from the
+// facility and severity keywords.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var j jsonMessage
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	var pri PRI
+	if j.PRI != nil {
+		pri = *j.PRI
+	} else {
+		var err error
+		pri, err = common.NewPRIFromNames(j.Facility, j.Severity)
+		if err != nil {
+			return err
+		}
+	}
+
+	*m = Message{
+		PRI:      pri,
+		Hostname: j.Hostname,
+		Tag:      j.Tag,
+		Content:  j.Content,
+	}
+	if j.Timestamp != nil {
+		m.Timestamp = *j.Timestamp
+	}
+	return nil
+}
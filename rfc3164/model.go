@@ -2,6 +2,8 @@ package rfc3164
 
 import (
 	"time"
+
+	"github.com/ysmilda/syslog/common"
 )
 
 type Message struct {
@@ -10,27 +12,21 @@ type Message struct {
 	Hostname  string
 	Tag       string
 	Content   string
-}
+	// JSON is set when the content contains a JSON object and the message was parsed using WithDetectJSON.
+	JSON *JSONPayload
 
-// PRI represents the Priority value of a syslog message.
-// The PRI is a single byte that encodes the facility and severity of the message.
-type PRI struct {
-	value byte
+	// buf holds the bytes of the string fields when the message was parsed using ParseInto.
+	buf []byte
 }
 
-func NewPRI(value byte) (PRI, error) {
-	if value > 191 {
-		return PRI{}, ErrInvalidPRI
-	}
-	return PRI{value: value}, nil
-}
+// JSONPayload is a JSON object embedded in the content of a syslog message. It is shared with the other formats, see
+// common.JSONPayload.
+type JSONPayload = common.JSONPayload
 
-// Facility returns the facility value of the PRI.
-func (p PRI) Facility() byte {
-	return p.value & 0xF8 >> 3
-}
+// PRI represents the Priority value of a syslog message. It is shared with the other formats, see common.PRI.
+type PRI = common.PRI
 
-// Severity returns the severity value of the PRI.
-func (p PRI) Severity() byte {
-	return p.value & 0x07
+// NewPRI creates a new PRI from its raw value. Values above 191 are invalid.
+func NewPRI(value byte) (PRI, error) {
+	return common.NewPRI(value)
 }
@@ -0,0 +1,47 @@
+package rfc3164
+
+import "regexp"
+
+type parseOption func(*Parser)
+
+// WithMinSeverity discards messages that are less important than the given severity. As lower values are more
+// important, messages with a severity value above the given value are discarded. The check is done directly after
+// parsing the PRI.
+func WithMinSeverity(severity byte) parseOption {
+	return func(r *Parser) {
+		r.filter.SetMinSeverity(severity)
+	}
+}
+
+// WithFacilities discards messages that do not have one of the given facilities. The check is done directly after
+// parsing the PRI. If a facility is above 23, parsing returns ErrInvalidFacility.
+func WithFacilities(facilities ...byte) parseOption {
+	return func(r *Parser) {
+		r.filter.AddFacilities(facilities...)
+	}
+}
+
+// WithHostnameMatch discards messages of which the hostname does not match the given regular expression. The check
+// is done directly after parsing the hostname.
+func WithHostnameMatch(re *regexp.Regexp) parseOption {
+	return func(r *Parser) {
+		r.filter.SetHostnameMatch(re)
+	}
+}
+
+// WithAppNames discards messages of which the tag is not one of the given names. As the tag is part of the message
+// content, the check is done after reading the full message.
+func WithAppNames(names ...string) parseOption {
+	return func(r *Parser) {
+		r.filter.AddAppNames(names...)
+	}
+}
+
+// WithDetectJSON enables the detection of a JSON object in the message, either directly or preceded by the "@cee:"
+// cookie. The object may follow the tag, e.g. "app[42]: @cee: {...}", or make up the whole message. A detected object
+// is available through the JSON field of the Message. It is only decoded when its fields are requested.
+func WithDetectJSON() parseOption {
+	return func(r *Parser) {
+		r.detectJSON = true
+	}
+}
@@ -1,136 +1,191 @@
 package rfc3164
 
 import (
+	"bytes"
 	"io"
-	"strings"
 	"time"
+	"unsafe"
+
+	"github.com/ysmilda/syslog/common"
 )
 
-type Parser struct{}
+type Parser struct {
+	filter     common.Filter
+	detectJSON bool
+}
 
-// NewParser creates a new Parser.
-func NewParser() Parser {
-	return Parser{}
+// NewParser creates a new Parser with the provided options.
+func NewParser(options ...parseOption) Parser {
+	r := Parser{}
+	for _, option := range options {
+		option(&r)
+	}
+	return r
 }
 
+// Parse tries to parse a syslog message from the input. If the input is not a valid syslog message, an error is returned.
 func (p Parser) Parse(input io.ByteScanner) (Message, error) {
 	var m Message
+	err := p.ParseInto(input, &m)
+	// The buffer is not handed out with the message, so the strings in it are never overwritten.
+	m.buf = nil
+	return m, err
+}
 
-	pri, err := parsePRI(input)
+// ParseInto parses a syslog message from the input into m, reusing the backing storage of m for its string fields.
+// This avoids allocating a new message for every call, but it means that the strings of a previous message parsed
+// into m are overwritten. Use strings.Clone to keep values beyond the next call. Messages can be kept in a sync.Pool
+// for reuse. If the input is not a valid syslog message, an error is returned and m is reset.
+func (p Parser) ParseInto(input io.ByteScanner, m *Message) error {
+	*m = Message{buf: m.buf[:0]}
+	err := p.parseInto(input, m)
 	if err != nil {
-		return m, err
+		*m = Message{buf: m.buf[:0]}
 	}
+	return err
+}
 
-	timestamp, err := parseTimestamp(input)
+func (p Parser) parseInto(input io.ByteScanner, m *Message) error {
+	if err := p.filter.Err(); err != nil {
+		return err
+	}
+	pri, err := common.ParsePRI(input)
 	if err != nil {
-		return m, err
+		return err
 	}
+	if err := p.filter.CheckPRI(pri); err != nil {
+		return err
+	}
+	m.PRI = pri
 
-	hostname, err := parseHostname(input)
+	m.Timestamp, m.buf, err = readTimestamp(m.buf, input)
 	if err != nil {
-		return m, err
+		return err
 	}
 
-	tag, content := parseMessage(input)
+	start := len(m.buf)
+	m.buf, err = common.AppendField(m.buf, input, 0, ErrInvalidHostname)
+	if err != nil {
+		return err
+	}
+	m.Hostname = bytesToString(m.buf[start:])
+	if err := p.filter.CheckHostname(m.Hostname); err != nil {
+		return err
+	}
 
-	return Message{
-		PRI:       PRI{pri},
-		Timestamp: timestamp,
-		Hostname:  hostname,
-		Tag:       tag,
-		Content:   content,
-	}, nil
-}
+	start = len(m.buf)
+	var tagLen int
+	m.buf, tagLen = appendMessage(m.buf, input)
+	m.Tag = bytesToString(m.buf[start : start+tagLen])
+	m.Content = bytesToString(m.buf[start+tagLen:])
+	if err := p.filter.CheckAppName(m.Tag); err != nil {
+		return err
+	}
 
-// parsePRI parses the PRI part of a syslog message.
-func parsePRI(input io.ByteScanner) (byte, error) {
-	b, err := input.ReadByte()
-	if err != nil || b != '<' {
-		return 0, ErrInvalidPRI
+	if p.detectJSON {
+		m.JSON = detectJSON(m.buf[start:], tagLen)
 	}
 
-	PRI := byte(0)
-	for i := 0; i < 4; i++ {
-		b, err = input.ReadByte()
-		if err != nil {
-			return 0, ErrInvalidPRI
-		}
-		if b == '>' {
-			if PRI > 191 {
-				return 0, ErrInvalidPRI
+	return nil
+}
+
+// ParseBatch parses each frame into the message at the same index of msgs, growing msgs if it is too short, and
+// returns the messages for the frames. The backing storage of the given messages is reused as with ParseInto, so the
+// returned messages are only valid until the next call with the same slice. If any frame fails to parse, the returned
+// errors contain the error of every frame at its index and the message at that index is reset. Otherwise, the returned
+// errors are nil.
+func (p Parser) ParseBatch(frames [][]byte, msgs []Message) ([]Message, []error) {
+	if cap(msgs) < len(frames) {
+		msgs = append(msgs[:cap(msgs)], make([]Message, len(frames)-cap(msgs))...)
+	}
+	msgs = msgs[:len(frames)]
+
+	var (
+		errs   []error
+		reader bytes.Reader
+	)
+	for i, frame := range frames {
+		reader.Reset(frame)
+		if err := p.ParseInto(&reader, &msgs[i]); err != nil {
+			if errs == nil {
+				errs = make([]error, len(frames))
 			}
-			return PRI, nil
+			errs[i] = err
 		}
-		if b < '0' || b > '9' {
-			return 0, ErrInvalidPRI
-		}
-		PRI = PRI*10 + (b - '0')
 	}
-
-	return 0, ErrInvalidPRI
+	return msgs, errs
 }
 
-func parseTimestamp(input io.ByteScanner) (time.Time, error) {
+// readTimestamp parses the timestamp using the spare capacity of buf as scratch space. The returned buffer has the
+// same length as buf, but might have grown.
+func readTimestamp(buf []byte, input io.ByteScanner) (time.Time, []byte, error) {
 	b, err := input.ReadByte()
 	if err != nil {
-		return time.Time{}, ErrInvalidTimestamp
+		return time.Time{}, buf, ErrInvalidTimestamp
 	}
 	if b == ' ' {
-		return time.Time{}, nil
+		return time.Time{}, buf, nil
 	}
 
-	builder := strings.Builder{}
-	builder.WriteByte(b)
+	start := len(buf)
+	buf = append(buf, b)
 	for i := 0; i < 14; i++ {
 		b, err := input.ReadByte()
 		if err != nil {
-			return time.Time{}, ErrInvalidTimestamp
+			return time.Time{}, buf[:start], ErrInvalidTimestamp
 		}
-		builder.WriteByte(b)
+		buf = append(buf, b)
 	}
 
 	space, err := input.ReadByte()
 	if err != nil || space != ' ' {
-		return time.Time{}, ErrInvalidTimestamp
+		return time.Time{}, buf[:start], ErrInvalidTimestamp
 	}
 
-	timestamp, err := time.Parse(time.Stamp, builder.String())
+	timestamp, err := time.Parse(time.Stamp, bytesToString(buf[start:]))
 	if err != nil {
-		return time.Time{}, ErrInvalidTimestamp
+		return time.Time{}, buf[:start], ErrInvalidTimestamp
 	}
-	return timestamp, nil
+	return timestamp, buf[:start], nil
 }
 
-func parseHostname(input io.ByteScanner) (string, error) {
-	builder := strings.Builder{}
+// appendMessage appends the remainder of the input to dst and returns the length of the tag at its start. The tag ends
+// at the first '[', ']' or ':'. If there is no such character, the message has no tag and the length is zero.
+func appendMessage(dst []byte, input io.ByteScanner) ([]byte, int) {
+	start := len(dst)
+	tagLen := -1
 	for {
 		b, err := input.ReadByte()
 		if err != nil {
-			return "", ErrInvalidHostname
-		}
-		if b == ' ' {
 			break
 		}
-		builder.WriteByte(b)
+		if tagLen < 0 && (b == '[' || b == ']' || b == ':') {
+			tagLen = len(dst) - start
+		}
+		dst = append(dst, b)
 	}
-	return builder.String(), nil
+	if tagLen < 0 {
+		tagLen = 0
+	}
+	return dst, tagLen
 }
 
-func parseMessage(input io.ByteScanner) (tag string, content string) {
-	builder := strings.Builder{}
-	tagFound := false
-	for {
-		b, err := input.ReadByte()
-		if err != nil {
-			break
-		}
-		if (b == '[' || b == ']' || b == ':') && !tagFound {
-			tagFound = true
-			tag = builder.String()
-			builder.Reset()
+// detectJSON looks for a JSON object in the message, which consists of the tag and content. The object either makes up
+// the whole message, in which case the tag was taken from the JSON itself, or it follows the ": " that ends the tag.
+func detectJSON(msg []byte, tagLen int) *JSONPayload {
+	if payload, ok := common.DetectJSON(bytesToString(msg)); ok {
+		return payload
+	}
+	if i := bytes.Index(msg[tagLen:], []byte(": ")); i >= 0 {
+		if payload, ok := common.DetectJSON(bytesToString(msg[tagLen+i+2:])); ok {
+			return payload
 		}
-		builder.WriteByte(b)
 	}
-	content = builder.String()
-	return
+	return nil
+}
+
+// bytesToString returns a string that shares its memory with b. The bytes must not be modified while the string is in
+// use.
+func bytesToString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b)) //nolint:gosec // The buffers are only written beyond their length.
 }
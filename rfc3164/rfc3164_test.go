@@ -3,10 +3,13 @@ package rfc3164
 
 import (
 	"bytes"
+	"encoding/json"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/common"
 )
 
 func TestParse(t *testing.T) {
@@ -22,7 +25,7 @@ func TestParse(t *testing.T) {
 			name: "valid message - example 1",
 			msg:  []byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8"),
 			expectedMessage: Message{
-				PRI:       PRI{34},
+				PRI:       common.MustPRI(34),
 				Timestamp: time.Date(0, time.October, 11, 22, 14, 15, 0, time.UTC),
 				Hostname:  "mymachine",
 				Tag:       "su",
@@ -33,7 +36,7 @@ func TestParse(t *testing.T) {
 			name: "valid message - example 2 (after relay)",
 			msg:  []byte("<13>Feb  5 17:32:18 10.0.0.99 Use the BFG!"),
 			expectedMessage: Message{
-				PRI:       PRI{13},
+				PRI:       common.MustPRI(13),
 				Timestamp: time.Date(0, time.February, 5, 17, 32, 18, 0, time.UTC),
 				Hostname:  "10.0.0.99",
 				Tag:       "",
@@ -44,7 +47,7 @@ func TestParse(t *testing.T) {
 			name: "valid message - example 3",
 			msg:  []byte("<165>Aug 24 05:34:00 CST 1987 mymachine myproc[10]: %% It's time to make the do-nuts.  %%  Ingredients: Mix=OK, Jelly=OK # Devices: Mixer=OK, Jelly_Injector=OK, Frier=OK # Transport: Conveyer1=OK, Conveyer2=OK # %%"),
 			expectedMessage: Message{
-				PRI:       PRI{165},
+				PRI:       common.MustPRI(165),
 				Timestamp: time.Date(0, time.August, 24, 5, 34, 0, 0, time.UTC),
 				Hostname:  "CST",
 				Tag:       "1987 mymachine myproc",
@@ -61,75 +64,6 @@ func TestParse(t *testing.T) {
 	}
 }
 
-func TestParsePRI(t *testing.T) {
-	t.Parallel()
-
-	testcases := []struct {
-		name          string
-		msg           []byte
-		expectedPRI   byte
-		expectedError error
-	}{
-		{
-			name:        "valid PRI - single digit",
-			msg:         []byte("<3>"),
-			expectedPRI: 3,
-		},
-		{
-			name:        "valid PRI - double digit",
-			msg:         []byte("<34>"),
-			expectedPRI: 34,
-		},
-		{
-			name:        "valid PRI - triple digit",
-			msg:         []byte("<165>"),
-			expectedPRI: 165,
-		},
-		{
-			name:          "invalid PRI - missing closing bracket",
-			msg:           []byte("<165"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - invalid character",
-			msg:           []byte("<1a5>"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - value too high",
-			msg:           []byte("<192>"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - value too long",
-			msg:           []byte("<0192>"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - missing opening bracket",
-			msg:           []byte("165>"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - empty",
-			msg:           []byte(""),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-	}
-
-	for _, tc := range testcases {
-		pri, err := parsePRI(bytes.NewReader(tc.msg))
-		assert.Equal(t, tc.expectedPRI, pri, tc.name)
-		assert.Equal(t, tc.expectedError, err, tc.name)
-	}
-}
-
 func TestParseTimestamp(t *testing.T) {
 	t.Parallel()
 
@@ -178,7 +112,7 @@ func TestParseTimestamp(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		timestamp, err := parseTimestamp(bytes.NewReader(tc.msg))
+		timestamp, _, err := readTimestamp(nil, bytes.NewReader(tc.msg))
 		assert.Equal(t, tc.expectedTime, timestamp, tc.name)
 		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
@@ -214,8 +148,8 @@ func TestParseHostname(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		hostname, err := parseHostname(bytes.NewReader(tc.msg))
-		assert.Equal(t, tc.expectedHost, hostname, tc.name)
+		hostname, err := common.AppendField(nil, bytes.NewReader(tc.msg), 0, ErrInvalidHostname)
+		assert.Equal(t, tc.expectedHost, string(hostname), tc.name)
 		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
 }
@@ -262,12 +196,215 @@ func TestParseMessage(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		tag, content := parseMessage(bytes.NewReader(tc.msg))
-		assert.Equal(t, tc.expectedTag, tag, tc.name)
-		assert.Equal(t, tc.expectedContent, content, tc.name)
+		msg, tagLen := appendMessage(nil, bytes.NewReader(tc.msg))
+		assert.Equal(t, tc.expectedTag, string(msg[:tagLen]), tc.name)
+		assert.Equal(t, tc.expectedContent, string(msg[tagLen:]), tc.name)
+	}
+}
+
+func TestParseFiltered(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8")
+
+	testcases := []struct {
+		name          string
+		options       []parseOption
+		expectedError error
+	}{
+		{
+			name:    "no filters",
+			options: nil,
+		},
+		{
+			name:    "min severity - kept",
+			options: []parseOption{WithMinSeverity(2)},
+		},
+		{
+			name:          "min severity - filtered",
+			options:       []parseOption{WithMinSeverity(1)},
+			expectedError: ErrFiltered,
+		},
+		{
+			name:    "facilities - kept",
+			options: []parseOption{WithFacilities(4)},
+		},
+		{
+			name:          "facilities - filtered",
+			options:       []parseOption{WithFacilities(0, 1)},
+			expectedError: ErrFiltered,
+		},
+		{
+			name:    "hostname - kept",
+			options: []parseOption{WithHostnameMatch(regexp.MustCompile(`^my`))},
+		},
+		{
+			name:          "hostname - filtered",
+			options:       []parseOption{WithHostnameMatch(regexp.MustCompile(`^other`))},
+			expectedError: ErrFiltered,
+		},
+		{
+			name:    "app-names - kept",
+			options: []parseOption{WithAppNames("su")},
+		},
+		{
+			name:          "app-names - filtered",
+			options:       []parseOption{WithAppNames("sshd")},
+			expectedError: ErrFiltered,
+		},
+	}
+
+	for _, tc := range testcases {
+		r := NewParser(tc.options...)
+		_, err := r.Parse(bytes.NewReader(msg))
+		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
 }
 
+func TestWithFacilitiesOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8")
+	_, err := NewParser(WithFacilities(4, 24)).Parse(bytes.NewReader(msg))
+	assert.Equal(t, ErrInvalidFacility, err)
+	_, err = NewParser(WithFacilities(0, 23)).Parse(bytes.NewReader(msg))
+	assert.Equal(t, ErrFiltered, err)
+}
+
+func TestParseInto(t *testing.T) {
+	t.Parallel()
+
+	r := NewParser()
+	frames := [][]byte{
+		[]byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8"),
+		[]byte("<13>Feb  5 17:32:18 10.0.0.99 Use the BFG!"),
+		[]byte("<165>Aug 24 05:34:00 host myproc[10]: %% It's time to make the do-nuts."),
+	}
+
+	var m Message
+	for _, frame := range frames {
+		expected, err := r.Parse(bytes.NewReader(frame))
+		assert.Nil(t, err)
+		err = r.ParseInto(bytes.NewReader(frame), &m)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, Message{
+			PRI:       m.PRI,
+			Timestamp: m.Timestamp,
+			Hostname:  m.Hostname,
+			Tag:       m.Tag,
+			Content:   m.Content,
+		})
+	}
+
+	err := r.ParseInto(bytes.NewReader([]byte("<34>Oct 11 22:14:15 host")), &m)
+	assert.Equal(t, ErrInvalidHostname, err)
+	assert.Equal(t, "", m.Content)
+}
+
+func TestParseBatch(t *testing.T) {
+	t.Parallel()
+
+	r := NewParser()
+	frames := [][]byte{
+		[]byte("<34>Oct 11 22:14:15 mymachine su: first"),
+		[]byte("<34>invalid"),
+		[]byte("<34>Oct 11 22:14:15 mymachine su: third"),
+	}
+
+	msgs, errs := r.ParseBatch(frames, nil)
+	assert.Len(t, msgs, 3)
+	assert.Equal(t, []error{nil, ErrInvalidTimestamp, nil}, errs)
+	assert.Equal(t, ": first", msgs[0].Content)
+	assert.Equal(t, PRI{}, msgs[1].PRI)
+	assert.Equal(t, ": third", msgs[2].Content)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8")
+	m, err := NewParser().Parse(bytes.NewReader(msg))
+	assert.Nil(t, err)
+
+	data, err := json.Marshal(m)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"pri": 34,
+		"facility": "auth",
+		"severity": "crit",
+		"timestamp": "0000-10-11T22:14:15Z",
+		"hostname": "mymachine",
+		"tag": "su",
+		"content": ": 'su root' failed for lonvick on /dev/pts/8"
+	}`, string(data))
+
+	var decoded Message
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, m, decoded)
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	var m Message
+	assert.Nil(t, json.Unmarshal([]byte(`{"facility": "local7", "severity": "debug", "content": "hello"}`), &m))
+	assert.Equal(t, Message{PRI: common.MustPRI(191), Content: "hello"}, m)
+
+	assert.Equal(t, ErrInvalidPRI, json.Unmarshal([]byte(`{"severity": "debug"}`), &m))
+	assert.Equal(t, ErrInvalidPRI, json.Unmarshal([]byte(`{"pri": 192}`), &m))
+}
+
+func TestParseDetectJSON(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name        string
+		msg         []byte
+		expectedRaw string
+	}{
+		{
+			name:        "cee cookie after tag",
+			msg:         []byte(`<13>Oct 11 22:14:15 host app[42]: @cee: {"user": "bob"}`),
+			expectedRaw: `{"user": "bob"}`,
+		},
+		{
+			name:        "object after tag",
+			msg:         []byte(`<13>Oct 11 22:14:15 host app: {"user": "bob"}`),
+			expectedRaw: `{"user": "bob"}`,
+		},
+		{
+			name:        "cee cookie as message",
+			msg:         []byte(`<13>Oct 11 22:14:15 host @cee: {"user": "bob"}`),
+			expectedRaw: `{"user": "bob"}`,
+		},
+		{
+			name:        "object as message",
+			msg:         []byte(`<13>Oct 11 22:14:15 host {"user": "bob"}`),
+			expectedRaw: `{"user": "bob"}`,
+		},
+		{
+			name: "no object",
+			msg:  []byte(`<13>Oct 11 22:14:15 host app: user bob logged in`),
+		},
+	}
+
+	for _, tc := range testcases {
+		m, err := NewParser(WithDetectJSON()).Parse(bytes.NewReader(tc.msg))
+		assert.Nil(t, err, tc.name)
+		if tc.expectedRaw == "" {
+			assert.Nil(t, m.JSON, tc.name)
+			continue
+		}
+		if assert.NotNil(t, m.JSON, tc.name) {
+			assert.Equal(t, tc.expectedRaw, m.JSON.Raw(), tc.name)
+		}
+	}
+
+	m, err := NewParser().Parse(bytes.NewReader(testcases[0].msg))
+	assert.Nil(t, err)
+	assert.Nil(t, m.JSON)
+}
+
 func BenchmarkParse(b *testing.B) {
 	r := Parser{}
 	msg := []byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8")
@@ -278,3 +415,16 @@ func BenchmarkParse(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkParseInto(b *testing.B) {
+	r := NewParser()
+	msg := []byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8")
+	var m Message
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := r.ParseInto(bytes.NewReader(msg), &m)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package rfc5424
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/ysmilda/syslog/common"
+)
 
 var (
 	ErrInvalidNilValue       = errors.New("invalid nil value")
-	ErrInvalidPRI            = errors.New("invalid PRI")
+	ErrInvalidPRI            = common.ErrInvalidPRI
+	ErrFiltered              = common.ErrFiltered
+	ErrInvalidFacility       = common.ErrInvalidFacility
 	ErrInvalidVersion        = errors.New("invalid version")
 	ErrInvalidTimestamp      = errors.New("invalid timestamp")
 	ErrInvalidHostname       = errors.New("invalid hostname")
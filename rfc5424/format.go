@@ -0,0 +1,56 @@
+package rfc5424
+
+import "strconv"
+
+// timestampLayout is RFC3339 with at most the six fractional digits that RFC5424 allows.
+const timestampLayout = "2006-01-02T15:04:05.999999Z07:00"
+
+// String formats the message as an RFC5424 message, see Append.
+func (m Message) String() string {
+	return string(m.Append(nil))
+}
+
+// Append formats the message as an RFC5424 message and appends it to dst. Empty header fields are written as the
+// NILVALUE and a zero version as 1. The structured data is formatted from StructuredDataElements when set, and
+// taken from StructuredData otherwise, so a parsed message is written back unchanged.
+func (m Message) Append(dst []byte) []byte {
+	dst = append(dst, '<')
+	dst = strconv.AppendUint(dst, uint64(m.PRI.Value()), 10)
+	dst = append(dst, '>')
+	if m.Version == 0 {
+		dst = append(dst, '1')
+	} else {
+		dst = strconv.AppendUint(dst, uint64(m.Version), 10)
+	}
+
+	dst = append(dst, ' ')
+	if m.Timestamp.IsZero() {
+		dst = append(dst, '-')
+	} else {
+		dst = m.Timestamp.AppendFormat(dst, timestampLayout)
+	}
+	dst = appendHeaderField(dst, m.Hostname)
+	dst = appendHeaderField(dst, m.AppName)
+	dst = appendHeaderField(dst, m.ProcID)
+	dst = appendHeaderField(dst, m.MsgID)
+
+	structuredData := m.StructuredData
+	if m.StructuredDataElements != nil {
+		structuredData = FormatStructuredData(*m.StructuredDataElements)
+	}
+	dst = appendHeaderField(dst, structuredData)
+
+	if m.Message != "" {
+		dst = append(dst, ' ')
+		dst = append(dst, m.Message...)
+	}
+	return dst
+}
+
+func appendHeaderField(dst []byte, value string) []byte {
+	dst = append(dst, ' ')
+	if value == "" {
+		return append(dst, '-')
+	}
+	return append(dst, value...)
+}
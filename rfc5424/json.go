@@ -0,0 +1,114 @@
+package rfc5424
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+)
+
+// jsonMessage is the JSON representation of a Message, see MarshalJSON for the schema.
+type jsonMessage struct {
+	PRI            *PRI                         `json:"pri,omitempty"`
+	Facility       string                       `json:"facility,omitempty"`
+	Severity       string                       `json:"severity,omitempty"`
+	Version        byte                         `json:"version"`
+	Timestamp      *time.Time                   `json:"timestamp,omitempty"`
+	Hostname       string                       `json:"hostname,omitempty"`
+	AppName        string                       `json:"app_name,omitempty"`
+	ProcID         string                       `json:"procid,omitempty"`
+	MsgID          string                       `json:"msgid,omitempty"`
+	StructuredData map[string]map[string]string `json:"structured_data,omitempty"`
+	Message        string                       `json:"message,omitempty"`
+}
+
+// MarshalJSON encodes the message as a JSON object with the following fields. Fields with a NILVALUE are omitted.
+//
+//	pri              number  The raw PRI value.
+//	facility         string  The facility keyword, e.g. "local4".
+//	severity         string  The severity keyword, e.g. "notice".
+//	version          number  The VERSION.
+//	timestamp        string  The TIMESTAMP in RFC3339 format with nanoseconds.
+//	hostname         string  The HOSTNAME.
+//	app_name         string  The APP-NAME.
+//	procid           string  The PROCID.
+//	msgid            string  The MSGID.
+//	structured_data  object  The SD-ELEMENTs by SD-ID, each an object with the parameters by name.
+//	message          string  The MSG.
+func (m Message) MarshalJSON() ([]byte, error) {
+	pri := m.PRI
+	j := jsonMessage{
+		PRI:      &pri,
+		Facility: pri.FacilityName(),
+		Severity: pri.SeverityName(),
+		Version:  m.Version,
+		Hostname: m.Hostname,
+		AppName:  m.AppName,
+		ProcID:   m.ProcID,
+		MsgID:    m.MsgID,
+		Message:  m.Message,
+	}
+	if !m.Timestamp.IsZero() {
+		j.Timestamp = &m.Timestamp
+	}
+
+	elements, err := m.Elements()
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) > 0 {
+		j.StructuredData = make(map[string]map[string]string, len(elements))
+		for _, element := range elements {
+			j.StructuredData[element.ID] = element.Parameters
+		}
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a message in the format written by MarshalJSON. If pri is missing, it is derived from the
+// facility and severity keywords. The structured data is restored both as StructuredDataElements, sorted by SD-ID,
+// and as the formatted StructuredData string.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var j jsonMessage
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	var pri PRI
+	if j.PRI != nil {
+		pri = *j.PRI
+	} else {
+		var err error
+		pri, err = common.NewPRIFromNames(j.Facility, j.Severity)
+		if err != nil {
+			return err
+		}
+	}
+
+	*m = Message{
+		PRI:      pri,
+		Version:  j.Version,
+		Hostname: j.Hostname,
+		AppName:  j.AppName,
+		ProcID:   j.ProcID,
+		MsgID:    j.MsgID,
+		Message:  j.Message,
+	}
+	if j.Timestamp != nil {
+		m.Timestamp = *j.Timestamp
+	}
+	if len(j.StructuredData) > 0 {
+		elements := make([]StructuredDataElement, 0, len(j.StructuredData))
+		for id, params := range j.StructuredData {
+			if params == nil {
+				params = map[string]string{}
+			}
+			elements = append(elements, StructuredDataElement{ID: id, Parameters: params})
+		}
+		sort.Slice(elements, func(a, b int) bool { return elements[a].ID < elements[b].ID })
+		m.StructuredData = FormatStructuredData(elements)
+		m.StructuredDataElements = &elements
+	}
+	return nil
+}
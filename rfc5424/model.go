@@ -1,6 +1,12 @@
 package rfc5424
 
-import "time"
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+)
 
 // Message represents a syslog message as defined in RFC 5424.
 type Message struct {
@@ -13,34 +19,113 @@ type Message struct {
 	MsgID                  string
 	StructuredData         string
 	StructuredDataElements *[]StructuredDataElement
-	Message                string
-}
+	// JSON is set when the MSG contains a JSON object and the message was parsed using WithDetectJSON.
+	JSON    *JSONPayload
+	Message string
 
-// PRI represents the Priority value of a syslog message.
-// The PRI is a single byte that encodes the facility and severity of the message.
-type PRI struct {
-	value byte
+	// buf holds the bytes of the string fields when the message was parsed using ParseInto.
+	buf []byte
 }
 
-func NewPRI(value byte) (PRI, error) {
-	if value > 191 {
-		return PRI{}, ErrInvalidPRI
-	}
-	return PRI{value: value}, nil
-}
+// Field identifies one or more fields of a Message. Fields can be combined using a bitwise or.
+type Field uint16
 
-// Facility returns the facility value of the PRI.
-func (p PRI) Facility() byte {
-	return p.value & 0xF8 >> 3
-}
+const (
+	FieldPRI Field = 1 << iota
+	FieldVersion
+	FieldTimestamp
+	FieldHostname
+	FieldAppName
+	FieldProcID
+	FieldMsgID
+	// FieldStructuredData covers both the StructuredData and StructuredDataElements fields.
+	FieldStructuredData
+	FieldMessage
+
+	FieldAll = FieldPRI | FieldVersion | FieldTimestamp | FieldHostname | FieldAppName | FieldProcID | FieldMsgID |
+		FieldStructuredData | FieldMessage
+)
 
-// Severity returns the severity value of the PRI.
-func (p PRI) Severity() byte {
-	return p.value & 0x07
+// PRI represents the Priority value of a syslog message. It is shared with the other formats, see common.PRI.
+type PRI = common.PRI
+
+// NewPRI creates a new PRI from its raw value. Values above 191 are invalid.
+func NewPRI(value byte) (PRI, error) {
+	return common.NewPRI(value)
 }
 
+// JSONPayload is a JSON object embedded in the MSG of a syslog message. It is shared with the other formats, see
+// common.JSONPayload.
+type JSONPayload = common.JSONPayload
+
 // StructuredDataElement represents a structured data element in a syslog message.
 type StructuredDataElement struct {
 	ID         string
 	Parameters map[string]string
 }
+
+// Elements returns the structured data elements of the message. If the message was parsed without
+// WithParseStructuredDataElements, the elements are parsed from the StructuredData string.
+func (m Message) Elements() ([]StructuredDataElement, error) {
+	if m.StructuredDataElements != nil {
+		return *m.StructuredDataElements, nil
+	}
+	if m.StructuredData == "" {
+		return nil, nil
+	}
+	return appendStructuredDataElements(nil, m.StructuredData)
+}
+
+// Parameter returns the value of a structured data parameter, or an empty string if it is not present or the
+// structured data is invalid.
+func (m Message) Parameter(id, name string) string {
+	elements, _ := m.Elements()
+	for _, element := range elements {
+		if element.ID == id {
+			return element.Parameters[name]
+		}
+	}
+	return ""
+}
+
+// String formats the element as an SD-ELEMENT. The parameters are sorted by name and their values are escaped.
+func (e StructuredDataElement) String() string {
+	builder := strings.Builder{}
+	e.appendTo(&builder)
+	return builder.String()
+}
+
+func (e StructuredDataElement) appendTo(builder *strings.Builder) {
+	names := make([]string, 0, len(e.Parameters))
+	for name := range e.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	builder.WriteByte('[')
+	builder.WriteString(e.ID)
+	for _, name := range names {
+		builder.WriteByte(' ')
+		builder.WriteString(name)
+		builder.WriteString(`="`)
+		value := e.Parameters[name]
+		for i := 0; i < len(value); i++ {
+			if value[i] == '"' || value[i] == '\\' || value[i] == ']' {
+				builder.WriteByte('\\')
+			}
+			builder.WriteByte(value[i])
+		}
+		builder.WriteByte('"')
+	}
+	builder.WriteByte(']')
+}
+
+// FormatStructuredData formats the elements as the STRUCTURED-DATA part of a syslog message. Without elements an
+// empty string is returned, which represents the NILVALUE.
+func FormatStructuredData(elements []StructuredDataElement) string {
+	builder := strings.Builder{}
+	for _, element := range elements {
+		element.appendTo(&builder)
+	}
+	return builder.String()
+}
@@ -1,5 +1,7 @@
 package rfc5424
 
+import "regexp"
+
 type parseOption func(*Parser)
 
 // WithParseStructuredDataElements enables parsing of structured data elements into its seperate parts.
@@ -8,3 +10,56 @@ func WithParseStructuredDataElements() parseOption {
 		r.parseStructuredDataElements = true
 	}
 }
+
+// WithMinSeverity discards messages that are less important than the given severity. As lower values are more
+// important, messages with a severity value above the given value are discarded. The check is done directly after
+// parsing the PRI.
+func WithMinSeverity(severity byte) parseOption {
+	return func(r *Parser) {
+		r.filter.SetMinSeverity(severity)
+	}
+}
+
+// WithFacilities discards messages that do not have one of the given facilities. The check is done directly after
+// parsing the PRI. If a facility is above 23, parsing returns ErrInvalidFacility.
+func WithFacilities(facilities ...byte) parseOption {
+	return func(r *Parser) {
+		r.filter.AddFacilities(facilities...)
+	}
+}
+
+// WithHostnameMatch discards messages of which the hostname does not match the given regular expression. The check
+// is done directly after parsing the HOSTNAME.
+func WithHostnameMatch(re *regexp.Regexp) parseOption {
+	return func(r *Parser) {
+		r.filter.SetHostnameMatch(re)
+	}
+}
+
+// WithAppNames discards messages of which the APP-NAME is not one of the given names. The check is done directly
+// after parsing the APP-NAME.
+func WithAppNames(names ...string) parseOption {
+	return func(r *Parser) {
+		r.filter.AddAppNames(names...)
+	}
+}
+
+// WithFields limits the fields of the Message that are materialized to the given fields, e.g.
+// WithFields(FieldPRI | FieldHostname | FieldAppName). The other fields are still validated so that the framing of the
+// message is checked, but they are left empty and no allocations are made for them. As the MSG is the remainder of
+// the input it is not read at all when it is not selected. Fields needed by a filter option are always parsed.
+// Passing zero selects all fields.
+func WithFields(fields Field) parseOption {
+	return func(r *Parser) {
+		r.fields = fields
+	}
+}
+
+// WithDetectJSON enables the detection of a JSON object in the MSG, either directly or preceded by the "@cee:" cookie.
+// A detected object is available through the JSON field of the Message. It is only decoded when its fields are
+// requested.
+func WithDetectJSON() parseOption {
+	return func(r *Parser) {
+		r.detectJSON = true
+	}
+}
@@ -1,13 +1,20 @@
 package rfc5424
 
 import (
+	"bytes"
 	"io"
 	"strings"
 	"time"
+	"unsafe"
+
+	"github.com/ysmilda/syslog/common"
 )
 
 type Parser struct {
 	parseStructuredDataElements bool
+	filter                      common.Filter
+	fields                      Field
+	detectJSON                  bool
 }
 
 // NewParser creates a new Parser with the provided options.
@@ -21,114 +28,168 @@ func NewParser(options ...parseOption) Parser {
 
 // Parse tries to parse a syslog message from the input. If the input is not a valid syslog message, an error is returned.
 func (r Parser) Parse(input io.ByteScanner) (Message, error) {
+	var m Message
+	err := r.ParseInto(input, &m)
+	// The buffer is not handed out with the message, so the strings in it are never overwritten.
+	m.buf = nil
+	return m, err
+}
+
+// ParseInto parses a syslog message from the input into m, reusing the backing storage of m for its string fields and
+// structured data elements. This avoids allocating a new message for every call, but it means that the strings of a
+// previous message parsed into m are overwritten. Use strings.Clone to keep values beyond the next call. Messages can
+// be kept in a sync.Pool for reuse. If the input is not a valid syslog message, an error is returned and m is reset.
+func (r Parser) ParseInto(input io.ByteScanner, m *Message) error {
 	// Taken from https://datatracker.ietf.org/doc/html/rfc5424#section-6
 	// The syslog message has the following ABNF [RFC5234] definition:
 	// SYSLOG-MSG      = HEADER SP STRUCTURED-DATA [SP MSG]
 	// HEADER          = PRI VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID
 
-	var (
-		m        Message
-		elements *[]StructuredDataElement
-	)
+	buf, elements := m.buf[:0], m.StructuredDataElements
+	*m = Message{buf: buf}
 
-	pri, err := parsePRI(input)
+	err := r.parseInto(input, m, elements)
 	if err != nil {
-		return m, err
+		*m = Message{buf: m.buf[:0]}
 	}
+	return err
+}
 
-	version, err := parseVersion(input)
+func (r Parser) parseInto(input io.ByteScanner, m *Message, elements *[]StructuredDataElement) error {
+	if err := r.filter.Err(); err != nil {
+		return err
+	}
+	pri, err := common.ParsePRI(input)
 	if err != nil {
-		return m, err
+		return err
+	}
+	if err := r.filter.CheckPRI(pri); err != nil {
+		return err
+	}
+	if r.selects(FieldPRI) {
+		m.PRI = pri
 	}
 
-	timestamp, err := parseTimestamp(input)
+	version, err := parseVersion(input)
 	if err != nil {
-		return m, err
+		return err
+	}
+	if r.selects(FieldVersion) {
+		m.Version = version
 	}
 
-	hostname, err := parseHostname(input)
+	if r.selects(FieldTimestamp) {
+		m.Timestamp, m.buf, err = readTimestamp(m.buf, input)
+	} else {
+		err = skipTimestamp(input)
+	}
 	if err != nil {
-		return m, err
+		return err
 	}
 
-	appName, err := parseAppName(input)
+	hostname, err := m.appendString(input, r.selects(FieldHostname) || r.filter.HasHostnameMatch(), 255,
+		ErrInvalidHostname)
 	if err != nil {
-		return m, err
+		return err
+	}
+	if err := r.filter.CheckHostname(hostname); err != nil {
+		return err
+	}
+	if r.selects(FieldHostname) {
+		m.Hostname = hostname
 	}
 
-	procID, err := parseProcID(input)
+	appName, err := m.appendString(input, r.selects(FieldAppName) || r.filter.HasAppNames(), 48, ErrInvalidAppName)
 	if err != nil {
-		return m, err
+		return err
+	}
+	if err := r.filter.CheckAppName(appName); err != nil {
+		return err
+	}
+	if r.selects(FieldAppName) {
+		m.AppName = appName
 	}
 
-	msgID, err := parseMsgID(input)
+	m.ProcID, err = m.appendString(input, r.selects(FieldProcID), 128, ErrInvalidProcID)
 	if err != nil {
-		return m, err
+		return err
 	}
 
-	structuredData, err := parseStructuredData(input)
+	m.MsgID, err = m.appendString(input, r.selects(FieldMsgID), 32, ErrInvalidMsgID)
 	if err != nil {
-		return m, err
+		return err
 	}
 
-	if r.parseStructuredDataElements {
-		elements, err = parseStructuredDataElements(structuredData)
+	if r.selects(FieldStructuredData) {
+		start := len(m.buf)
+		m.buf, err = appendStructuredData(m.buf, input)
 		if err != nil {
-			return m, err
+			return err
+		}
+		m.StructuredData = bytesToString(m.buf[start:])
+		if r.parseStructuredDataElements && m.StructuredData != "" {
+			if elements == nil {
+				elements = new([]StructuredDataElement)
+			}
+			*elements, err = appendStructuredDataElements((*elements)[:0], m.StructuredData)
+			if err != nil {
+				return err
+			}
+			m.StructuredDataElements = elements
 		}
+	} else if err := skipStructuredData(input); err != nil {
+		return err
 	}
 
-	builder := strings.Builder{}
-	for {
-		b, err := input.ReadByte()
-		if err != nil {
-			break
+	// The message is the remainder of the input, so it is simply not read when it is not selected.
+	if r.selects(FieldMessage) {
+		start := len(m.buf)
+		for {
+			b, err := input.ReadByte()
+			if err != nil {
+				break
+			}
+			m.buf = append(m.buf, b)
+		}
+		m.Message = bytesToString(m.buf[start:])
+		if r.detectJSON {
+			m.JSON, _ = common.DetectJSON(m.Message)
 		}
-		builder.WriteByte(b)
-	}
-
-	return Message{
-		PRI:                    PRI{pri},
-		Version:                version,
-		Timestamp:              timestamp,
-		Hostname:               hostname,
-		AppName:                appName,
-		ProcID:                 procID,
-		MsgID:                  msgID,
-		StructuredData:         structuredData,
-		StructuredDataElements: elements,
-		Message:                builder.String(),
-	}, nil
+	}
+
+	return nil
 }
 
-// parsePRI parses the PRI part of a syslog message according to the following rules.
-// PRI             = "<" PRIVAL ">"
-// PRIVAL          = 1*3DIGIT ; range 0 .. 191
-func parsePRI(input io.ByteScanner) (byte, error) {
-	b, err := input.ReadByte()
-	if err != nil || b != '<' {
-		return 0, ErrInvalidPRI
+// ParseBatch parses each frame into the message at the same index of msgs, growing msgs if it is too short, and
+// returns the messages for the frames. The backing storage of the given messages is reused as with ParseInto, so the
+// returned messages are only valid until the next call with the same slice. If any frame fails to parse, the returned
+// errors contain the error of every frame at its index and the message at that index is reset. Otherwise, the returned
+// errors are nil.
+func (r Parser) ParseBatch(frames [][]byte, msgs []Message) ([]Message, []error) {
+	if cap(msgs) < len(frames) {
+		msgs = append(msgs[:cap(msgs)], make([]Message, len(frames)-cap(msgs))...)
 	}
+	msgs = msgs[:len(frames)]
 
-	PRI := byte(0)
-	for i := 0; i < 4; i++ {
-		b, err = input.ReadByte()
-		if err != nil {
-			return 0, ErrInvalidPRI
-		}
-		if b == '>' {
-			if PRI > 191 {
-				return 0, ErrInvalidPRI
+	var (
+		errs   []error
+		reader bytes.Reader
+	)
+	for i, frame := range frames {
+		reader.Reset(frame)
+		if err := r.ParseInto(&reader, &msgs[i]); err != nil {
+			if errs == nil {
+				errs = make([]error, len(frames))
 			}
-			return PRI, nil
-		}
-		if b < '0' || b > '9' {
-			return 0, ErrInvalidPRI
+			errs[i] = err
 		}
-		PRI = PRI*10 + (b - '0')
 	}
+	return msgs, errs
+}
 
-	return 0, ErrInvalidPRI
+// selects returns whether the field should be materialized in the parsed message.
+func (r Parser) selects(field Field) bool {
+	return r.fields == 0 || r.fields&field != 0
 }
 
 // parseVersion parses the VERSION part of a syslog message according to the following rules.
@@ -150,7 +211,7 @@ func parseVersion(input io.ByteScanner) (byte, error) {
 	return b, nil
 }
 
-// parseTimestamp parses the TIMESTAMP part of a syslog message according to the following rules.
+// readTimestamp parses the TIMESTAMP part of a syslog message according to the following rules.
 // The TIMESTAMP field is a formalized timestamp derived from [RFC3339]
 // TIMESTAMP       = NILVALUE / FULL-DATE "T" FULL-TIME
 // FULL-DATE       = DATE-FULLYEAR "-" DATE-MONTH "-" DATE-MDAY
@@ -165,161 +226,282 @@ func parseVersion(input io.ByteScanner) (byte, error) {
 // TIME-SECFRAC    = "." 1*6DIGIT
 // TIME-OFFSET     = "Z" / TIME-NUMOFFSET
 // TIME-NUMOFFSET  = ("+" / "-") TIME-HOUR ":" TIME-MINUTE
-func parseTimestamp(input io.ByteScanner) (time.Time, error) {
+// The spare capacity of buf is used as scratch space. The returned buffer has the same length as buf, but might have
+// grown.
+func readTimestamp(buf []byte, input io.ByteScanner) (time.Time, []byte, error) {
 	isNil, err := checkNilValue(input)
 	if err != nil {
-		return time.Time{}, ErrInvalidTimestamp
+		return time.Time{}, buf, ErrInvalidTimestamp
 	}
-
 	if isNil {
-		return time.Time{}, nil
+		return time.Time{}, buf, nil
 	}
 
-	builder := strings.Builder{}
-	for {
-		b, err := input.ReadByte()
-		if err != nil {
-			return time.Time{}, ErrInvalidTimestamp
-		}
-		if b == ' ' {
-			break
-		}
-		builder.WriteByte(b)
+	start := len(buf)
+	buf, err = common.AppendField(buf, input, 0, ErrInvalidTimestamp)
+	if err != nil {
+		return time.Time{}, buf[:start], err
 	}
-	return time.Parse(time.RFC3339, builder.String())
-}
-
-// parseHostname parses the HOSTNAME part of a syslog message according to the following rules.
-// HOSTNAME        = NILVALUE / 1*255PRINTUSASCII
-func parseHostname(input io.ByteScanner) (string, error) {
-	return parseString(input, 255, ErrInvalidHostname)
+	timestamp, err := time.Parse(time.RFC3339, bytesToString(buf[start:]))
+	if err != nil {
+		return time.Time{}, buf[:start], ErrInvalidTimestamp
+	}
+	return timestamp, buf[:start], nil
 }
 
-// parseAppName parses the APP-NAME part of a syslog message according to the following rules.
-// APP-NAME        = NILVALUE / 1*48PRINTUSASCII
-func parseAppName(input io.ByteScanner) (string, error) {
-	return parseString(input, 48, ErrInvalidAppName)
+// skipTimestamp validates the framing of the TIMESTAMP part of a syslog message without parsing its value.
+func skipTimestamp(input io.ByteScanner) error {
+	if _, err := common.SkipField(input, 0, ErrInvalidTimestamp); err != nil {
+		return err
+	}
+	return nil
 }
 
-// parseProcID parses the PROCID part of a syslog message according to the following rules.
-// PROCID          = NILVALUE / 1*128PRINTUSASCII
-func parseProcID(input io.ByteScanner) (string, error) {
-	return parseString(input, 128, ErrInvalidProcID)
+// appendStructuredData parses the STRUCTURED-DATA part of a syslog message and appends it to dst, according to the
+// following rules.
+// STRUCTURED-DATA = NILVALUE / 1*SD-ELEMENT
+// SD-ELEMENT      = "[" SD-ID *(SP SD-PARAM) "]"
+func appendStructuredData(dst []byte, input io.ByteScanner) ([]byte, error) {
+	return scanStructuredData(dst, input, true)
 }
 
-// parseMsgID parses the MSGID part of a syslog message according to the following rules.
-// MSGID           = NILVALUE / 1*32PRINTUSASCII
-func parseMsgID(input io.ByteScanner) (string, error) {
-	return parseString(input, 32, ErrInvalidMsgID)
+// skipStructuredData validates the framing of the STRUCTURED-DATA part of a syslog message without storing it.
+func skipStructuredData(input io.ByteScanner) error {
+	_, err := scanStructuredData(nil, input, false)
+	return err
 }
 
-// parseStructuredData parses the STRUCTURED-DATA part of a syslog message into a string according to the following rules.
-// STRUCTURED-DATA = NILVALUE / 1*SD-ELEMENT
-// SD-ELEMENT      = "[" SD-ID *(SP SD-PARAM) "]"
-func parseStructuredData(input io.ByteScanner) (string, error) {
+// scanStructuredData reads the STRUCTURED-DATA part of a syslog message, appending it to dst if store is set. Escaped
+// characters in the parameter values are taken into account, so a ']' in a value does not end the element. The
+// structured data ends at a ']' followed by a space or the end of the input, so text after an element is kept up to
+// the next such ']'.
+func scanStructuredData(dst []byte, input io.ByteScanner, store bool) ([]byte, error) {
 	isNil, err := checkNilValue(input)
 	if err != nil {
-		return "", ErrInvalidStructuredData
+		return dst, ErrInvalidStructuredData
 	}
 	if isNil {
-		return "", nil
+		return dst, nil
 	}
-	builder := strings.Builder{}
+
+	var inElement, inValue, escaped, started bool
 	for {
 		b, err := input.ReadByte()
 		if err != nil {
-			return "", ErrInvalidStructuredData
-		}
-		if b == ']' {
-			space, err := input.ReadByte()
-			if err != nil || space == ' ' {
-				builder.WriteByte(b)
-				break
+			if inElement {
+				return dst, ErrInvalidStructuredData
 			}
-			err = input.UnreadByte()
-			if err != nil {
-				return "", ErrInvalidStructuredData
+			// The structured data was the last part of the message.
+			return dst, nil
+		}
+		switch {
+		case !inElement:
+			if b == ' ' && started {
+				return dst, nil
 			}
+			inElement = true
+		case escaped:
+			escaped = false
+		case inValue && b == '\\':
+			escaped = true
+		case b == '"':
+			inValue = !inValue
+		case !inValue && b == ']':
+			inElement = false
+		}
+		started = true
+		if store {
+			dst = append(dst, b)
 		}
-		builder.WriteByte(b)
 	}
-	return builder.String(), nil
 }
 
-// parseStructuredDataElements parses the STRUCTURED-DATA part of a syslog message according to the following rules.
+// appendStructuredDataElements parses the structured data elements in the input and appends them to dst. The
+// parameter maps of elements in the spare capacity of dst are cleared and reused. The elements follow these rules.
 // SD-ELEMENT      = "[" SD-ID *(SP SD-PARAM) "]"
 // SD-PARAM        = PARAM-NAME "=" %d34 PARAM-VALUE %d34
 // SD-ID           = SD-NAME
 // PARAM-NAME      = SD-NAME
 // PARAM-VALUE     = UTF-8-STRING ; characters '"', '\' and ']' MUST be escaped.
 // SD-NAME         = 1*32PRINTUSASCII except '=', SP, ']', %d34 (")
-func parseStructuredDataElements(input string) (*[]StructuredDataElement, error) {
-	// If the input is empty, return nil
-	// The structured data is optional, and a nil value ('-') is parsed as an empty string.
-	if input == "" {
-		return nil, nil //nolint:nilnil
-	}
+func appendStructuredDataElements(dst []StructuredDataElement, input string) ([]StructuredDataElement, error) {
 	input = strings.TrimSpace(input)
+	for len(input) > 0 {
+		if input[0] != '[' {
+			return dst, ErrInvalidStructuredData
+		}
+		input = input[1:]
 
-	elements := []StructuredDataElement{}
-	// Split the input by the indicator of a new element: '['
-	for _, element := range strings.Split(input, "[") {
-		if element == "" {
-			continue
+		end := strings.IndexAny(input, " ]")
+		if end < 0 {
+			return dst, ErrInvalidStructuredData
+		}
+		id := input[:end]
+		if !validSDName(id) {
+			return dst, ErrInvalidStructuredData
 		}
-		// Split the element on spaces, ignore the last character which is ']'
-		parts := strings.Split(element[:len(element)-1], " ")
-		id := parts[0]
-		// Check if the ID is valid
-		if len(id) < 1 || len(id) > 32 || strings.ContainsAny(id, "= ]\"") {
-			return nil, ErrInvalidStructuredData
+		input = input[end:]
+
+		var params map[string]string
+		if spare := dst[len(dst):cap(dst)]; len(spare) > 0 && spare[0].Parameters != nil {
+			params = spare[0].Parameters
+			clear(params)
+		} else {
+			params = map[string]string{}
 		}
-		params := map[string]string{}
-		for _, param := range parts[1:] {
-			parts := strings.Split(param, "=")
-			if len(parts) != 2 {
-				return nil, ErrInvalidStructuredData
+
+		for input[0] == ' ' {
+			var (
+				name, value string
+				err         error
+			)
+			name, value, input, err = parseSDParam(input[1:])
+			if err != nil {
+				return dst, err
 			}
-			// Remove the quotes from the value and unescape the characters
-			value := parts[1][1 : len(parts[1])-1]
-			value = strings.ReplaceAll(value, "\\\"", "\"")
-			value = strings.ReplaceAll(value, "\\\\", "\\")
-			value = strings.ReplaceAll(value, "\\]", "]")
-			params[parts[0]] = value
+			params[name] = value
 		}
-		elements = append(elements, StructuredDataElement{
+		// The loop above only ends on a ']', as parseSDParam checks the character following each parameter.
+		input = input[1:]
+
+		dst = append(dst, StructuredDataElement{
 			ID:         id,
 			Parameters: params,
 		})
 	}
-	return &elements, nil
+	return dst, nil
+}
+
+// parseSDParam parses a single SD-PARAM from the start of the input and returns its name, unescaped value and the
+// remainder of the input, which starts with either a space or a ']'.
+func parseSDParam(input string) (name, value, rest string, err error) {
+	eq := strings.IndexByte(input, '=')
+	if eq < 0 {
+		return "", "", "", ErrInvalidStructuredData
+	}
+	name = input[:eq]
+	if !validSDName(name) {
+		return "", "", "", ErrInvalidStructuredData
+	}
+	input = input[eq+1:]
+	if len(input) == 0 || input[0] != '"' {
+		return "", "", "", ErrInvalidStructuredData
+	}
+	input = input[1:]
+
+	escapes := false
+	end := -1
+	for i := 0; i < len(input); i++ {
+		if input[i] == '\\' {
+			escapes = true
+			i++
+			continue
+		}
+		if input[i] == '"' {
+			end = i
+			break
+		}
+	}
+	if end < 0 || end+1 >= len(input) || (input[end+1] != ' ' && input[end+1] != ']') {
+		return "", "", "", ErrInvalidStructuredData
+	}
+	value = input[:end]
+	if escapes {
+		value = unescapeParamValue(value)
+	}
+	return name, value, input[end+1:], nil
 }
 
-// parseString parses a string from the input with a maximum length according to the following rules.
+// unescapeParamValue removes the escaping of '"', '\' and ']' from a PARAM-VALUE. A backslash that does not precede
+// one of these characters is kept as is.
+func unescapeParamValue(value string) string {
+	builder := strings.Builder{}
+	builder.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && strings.IndexByte("\"\\]", value[i+1]) >= 0 {
+			i++
+		}
+		builder.WriteByte(value[i])
+	}
+	return builder.String()
+}
+
+// validSDName checks whether the name is a valid SD-NAME.
+// SD-NAME         = 1*32PRINTUSASCII except '=', SP, ']', %d34 (")
+func validSDName(name string) bool {
+	if len(name) < 1 || len(name) > 32 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		if b < 33 || b > 126 || b == '=' || b == ']' || b == '"' {
+			return false
+		}
+	}
+	return true
+}
+
+// appendString parses a string from the input with a maximum length and appends it to dst. The HOSTNAME, APP-NAME,
+// PROCID and MSGID parts of a syslog message are parsed according to the following rules.
 // STRING = NILVALUE / 1*[max]PRINTUSASCII SP
-func parseString(input io.ByteScanner, max int, e error) (string, error) {
+func appendString(dst []byte, input io.ByteScanner, max int, e error) ([]byte, error) {
 	isNil, err := checkNilValue(input)
 	if err != nil {
-		return "", e
+		return dst, e
 	}
 	if isNil {
-		return "", nil
+		return dst, nil
 	}
-	builder := strings.Builder{}
-	for {
-		b, err := input.ReadByte()
-		if err != nil {
-			return "", e
-		}
-		if b == ' ' {
-			break
-		}
-		builder.WriteByte(b)
+	start := len(dst)
+	dst, err = common.AppendField(dst, input, max, e)
+	if err != nil {
+		return dst[:start], err
 	}
-	if builder.Len() < 1 || builder.Len() > max {
-		return "", e
+	if len(dst) == start {
+		return dst, e
 	}
-	return builder.String(), nil
+	return dst, nil
+}
+
+// skipString validates a string from the input with a maximum length without storing it. It follows the same rules as
+// appendString.
+func skipString(input io.ByteScanner, max int, e error) error {
+	isNil, err := checkNilValue(input)
+	if err != nil {
+		return e
+	}
+	if isNil {
+		return nil
+	}
+	n, err := common.SkipField(input, max, e)
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return e
+	}
+	return nil
+}
+
+// appendString parses a string from the input into the buffer of the message if it is needed, otherwise it only
+// validates it.
+func (m *Message) appendString(input io.ByteScanner, needed bool, max int, e error) (string, error) {
+	if !needed {
+		return "", skipString(input, max, e)
+	}
+	start := len(m.buf)
+	var err error
+	m.buf, err = appendString(m.buf, input, max, e)
+	if err != nil {
+		return "", err
+	}
+	return bytesToString(m.buf[start:]), nil
+}
+
+// bytesToString returns a string that shares its memory with b. The bytes must not be modified while the string is in
+// use.
+func bytesToString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b)) //nolint:gosec // The buffers are only written beyond their length.
 }
 
 // checkNilValue checks if the input is a nil value ('-') according to the following rules.
@@ -3,11 +3,14 @@ package rfc5424
 
 import (
 	"bytes"
+	"encoding/json"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/common"
 )
 
 func TestParse(t *testing.T) {
@@ -23,7 +26,7 @@ func TestParse(t *testing.T) {
 			name: "valid message - example 1",
 			msg:  []byte("<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed for lonvick on /dev/pts/8'"),
 			expectedMessage: Message{
-				PRI:       PRI{value: 34},
+				PRI:       common.MustPRI(34),
 				Version:   1,
 				Timestamp: time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
 				Hostname:  "mymachine.example.com",
@@ -36,7 +39,7 @@ func TestParse(t *testing.T) {
 			name: "valid message - example 2",
 			msg:  []byte("<165>1 2003-08-24T05:14:15.000003-07:00 192.0.2.1 myproc 8710 - - %% It's time to make the do-nuts."),
 			expectedMessage: Message{
-				PRI:       PRI{value: 165},
+				PRI:       common.MustPRI(165),
 				Version:   1,
 				Timestamp: time.Date(2003, 8, 24, 5, 14, 15, 3000, time.FixedZone("", -7*60*60)),
 				Hostname:  "192.0.2.1",
@@ -49,7 +52,7 @@ func TestParse(t *testing.T) {
 			name: "valid message - example 3",
 			msg:  []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"] An application event log entry..."),
 			expectedMessage: Message{
-				PRI:            PRI{value: 165},
+				PRI:            common.MustPRI(165),
 				Version:        1,
 				Timestamp:      time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
 				Hostname:       "mymachine.example.com",
@@ -63,7 +66,7 @@ func TestParse(t *testing.T) {
 			name: "valid message - example 4",
 			msg:  []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"][examplePriority@32473 class=\"high\"]"),
 			expectedMessage: Message{
-				PRI:            PRI{value: 165},
+				PRI:            common.MustPRI(165),
 				Version:        1,
 				Timestamp:      time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
 				Hostname:       "mymachine.example.com",
@@ -82,75 +85,6 @@ func TestParse(t *testing.T) {
 	}
 }
 
-func TestParsePRI(t *testing.T) {
-	t.Parallel()
-
-	testcases := []struct {
-		name          string
-		msg           []byte
-		expectedPRI   byte
-		expectedError error
-	}{
-		{
-			name:        "valid PRI - single digit",
-			msg:         []byte("<3>"),
-			expectedPRI: 3,
-		},
-		{
-			name:        "valid PRI - double digit",
-			msg:         []byte("<34>"),
-			expectedPRI: 34,
-		},
-		{
-			name:        "valid PRI - triple digit",
-			msg:         []byte("<165>"),
-			expectedPRI: 165,
-		},
-		{
-			name:          "invalid PRI - missing closing bracket",
-			msg:           []byte("<165"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - invalid character",
-			msg:           []byte("<1a5>"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - value too high",
-			msg:           []byte("<192>"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - value too long",
-			msg:           []byte("<0192>"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - missing opening bracket",
-			msg:           []byte("165>"),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-		{
-			name:          "invalid PRI - empty",
-			msg:           []byte(""),
-			expectedPRI:   0,
-			expectedError: ErrInvalidPRI,
-		},
-	}
-
-	for _, tc := range testcases {
-		pri, err := parsePRI(bytes.NewReader(tc.msg))
-		assert.Equal(t, tc.expectedPRI, pri, tc.name)
-		assert.Equal(t, tc.expectedError, err, tc.name)
-	}
-}
-
 func TestParseVersion(t *testing.T) {
 	t.Parallel()
 
@@ -253,7 +187,7 @@ func TestParseTimestamp(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		timestamp, err := parseTimestamp(bytes.NewReader(tc.msg))
+		timestamp, _, err := readTimestamp(nil, bytes.NewReader(tc.msg))
 		assert.Equal(t, tc.expectedTime, timestamp, tc.name)
 		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
@@ -293,8 +227,8 @@ func TestParseHostname(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		hostname, err := parseHostname(bytes.NewReader(tc.msg))
-		assert.Equal(t, tc.expectedHost, hostname, tc.name)
+		hostname, err := appendString(nil, bytes.NewReader(tc.msg), 255, ErrInvalidHostname)
+		assert.Equal(t, tc.expectedHost, string(hostname), tc.name)
 		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
 }
@@ -333,8 +267,8 @@ func TestParseAppName(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		appName, err := parseAppName(bytes.NewReader(tc.msg))
-		assert.Equal(t, tc.expectedApp, appName, tc.name)
+		appName, err := appendString(nil, bytes.NewReader(tc.msg), 48, ErrInvalidAppName)
+		assert.Equal(t, tc.expectedApp, string(appName), tc.name)
 		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
 }
@@ -373,8 +307,8 @@ func TestParseProcID(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		procID, err := parseProcID(bytes.NewReader(tc.msg))
-		assert.Equal(t, tc.expectedProc, procID, tc.name)
+		procID, err := appendString(nil, bytes.NewReader(tc.msg), 128, ErrInvalidProcID)
+		assert.Equal(t, tc.expectedProc, string(procID), tc.name)
 		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
 }
@@ -413,8 +347,8 @@ func TestParseMsgID(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		msgID, err := parseMsgID(bytes.NewReader(tc.msg))
-		assert.Equal(t, tc.expectedMsg, msgID, tc.name)
+		msgID, err := appendString(nil, bytes.NewReader(tc.msg), 32, ErrInvalidMsgID)
+		assert.Equal(t, tc.expectedMsg, string(msgID), tc.name)
 		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
 }
@@ -448,6 +382,22 @@ func TestParseStructuredData(t *testing.T) {
 			msg:        []byte("[exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"]"),
 			expectedSD: "[exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"]",
 		},
+		{
+			name:       "valid structured-data - escaped characters",
+			msg:        []byte("[exampleSDID@32473 msg=\"a \\\"quoted\\\" \\] value\"] message"),
+			expectedSD: "[exampleSDID@32473 msg=\"a \\\"quoted\\\" \\] value\"]",
+		},
+		{
+			name:       "valid structured-data - text after element",
+			msg:        []byte("[exampleSDID@32473 iut=\"3\"]x] message"),
+			expectedSD: "[exampleSDID@32473 iut=\"3\"]x]",
+		},
+		{
+			name:          "invalid structured-data - text after element without closing bracket",
+			msg:           []byte("[exampleSDID@32473 iut=\"3\"]x "),
+			expectedSD:    "",
+			expectedError: ErrInvalidStructuredData,
+		},
 		{
 			name:          "invalid structured-data - empty",
 			msg:           []byte(""),
@@ -463,9 +413,11 @@ func TestParseStructuredData(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		sd, err := parseStructuredData(bytes.NewReader(tc.msg))
-		assert.Equal(t, tc.expectedSD, sd, tc.name)
+		sd, err := appendStructuredData(nil, bytes.NewReader(tc.msg))
 		assert.Equal(t, tc.expectedError, err, tc.name)
+		if err == nil {
+			assert.Equal(t, tc.expectedSD, string(sd), tc.name)
+		}
 	}
 }
 
@@ -475,7 +427,7 @@ func TestParseStructuredDataElements(t *testing.T) {
 	testcases := []struct {
 		name          string
 		msg           []byte
-		expectedSD    *[]StructuredDataElement
+		expectedSD    []StructuredDataElement
 		expectedError error
 	}{
 		{
@@ -486,7 +438,7 @@ func TestParseStructuredDataElements(t *testing.T) {
 		{
 			name: "valid structured-data-elements - example 1",
 			msg:  []byte("[exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"] "),
-			expectedSD: &[]StructuredDataElement{
+			expectedSD: []StructuredDataElement{
 				{
 					ID: "exampleSDID@32473",
 					Parameters: map[string]string{
@@ -500,7 +452,7 @@ func TestParseStructuredDataElements(t *testing.T) {
 		{
 			name: "valid structured-data-elements - example 2",
 			msg:  []byte("[exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"][examplePriority@32473 class=\"high\"] "),
-			expectedSD: &[]StructuredDataElement{
+			expectedSD: []StructuredDataElement{
 				{
 					ID: "exampleSDID@32473",
 					Parameters: map[string]string{
@@ -517,6 +469,35 @@ func TestParseStructuredDataElements(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid structured-data-elements - escaped characters and spaces",
+			msg:  []byte("[exampleSDID@32473 msg=\"a \\\"quoted\\\" \\] value\" path=\"C:\\\\dir\\n\"]"),
+			expectedSD: []StructuredDataElement{
+				{
+					ID: "exampleSDID@32473",
+					Parameters: map[string]string{
+						"msg":  "a \"quoted\" ] value",
+						"path": "C:\\dir\\n",
+					},
+				},
+			},
+		},
+		{
+			name: "valid structured-data-elements - no parameters",
+			msg:  []byte("[exampleSDID@32473]"),
+			expectedSD: []StructuredDataElement{
+				{
+					ID:         "exampleSDID@32473",
+					Parameters: map[string]string{},
+				},
+			},
+		},
+		{
+			name:          "invalid structured-data-elements - unquoted value",
+			msg:           []byte("[exampleSDID@32473 iut=3]"),
+			expectedSD:    nil,
+			expectedError: ErrInvalidStructuredData,
+		},
 		{
 			name:          "invalid structured-data-elements - missing ID",
 			msg:           []byte("[ iut=\"3\" eventSource=\"Application\" eventID=\"1011\"] "),
@@ -532,7 +513,7 @@ func TestParseStructuredDataElements(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		sd, err := parseStructuredDataElements(string(tc.msg))
+		sd, err := appendStructuredDataElements(nil, string(tc.msg))
 		assert.Equal(t, tc.expectedSD, sd, tc.name)
 		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
@@ -584,8 +565,8 @@ func TestParseString(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		str, err := parseString(bytes.NewReader(tc.msg), tc.maxLength, ErrInvalidMessage)
-		assert.Equal(t, tc.expectedStr, str, tc.name)
+		str, err := appendString(nil, bytes.NewReader(tc.msg), tc.maxLength, ErrInvalidMessage)
+		assert.Equal(t, tc.expectedStr, string(str), tc.name)
 		assert.Equal(t, tc.expectedError, err, tc.name)
 	}
 }
@@ -630,6 +611,389 @@ func TestCheckNilValue(t *testing.T) {
 	}
 }
 
+func TestParseFiltered(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\"] An application event log entry...")
+
+	testcases := []struct {
+		name          string
+		options       []parseOption
+		expectedError error
+	}{
+		{
+			name:    "no filters",
+			options: nil,
+		},
+		{
+			name:    "min severity - kept",
+			options: []parseOption{WithMinSeverity(5)},
+		},
+		{
+			name:          "min severity - filtered",
+			options:       []parseOption{WithMinSeverity(4)},
+			expectedError: ErrFiltered,
+		},
+		{
+			name:    "facilities - kept",
+			options: []parseOption{WithFacilities(1, 20)},
+		},
+		{
+			name:          "facilities - filtered",
+			options:       []parseOption{WithFacilities(1, 2)},
+			expectedError: ErrFiltered,
+		},
+		{
+			name:    "hostname - kept",
+			options: []parseOption{WithHostnameMatch(regexp.MustCompile(`\.example\.com$`))},
+		},
+		{
+			name:          "hostname - filtered",
+			options:       []parseOption{WithHostnameMatch(regexp.MustCompile(`^other`))},
+			expectedError: ErrFiltered,
+		},
+		{
+			name:    "app-names - kept",
+			options: []parseOption{WithAppNames("su", "evntslog")},
+		},
+		{
+			name:          "app-names - filtered",
+			options:       []parseOption{WithAppNames("su")},
+			expectedError: ErrFiltered,
+		},
+		{
+			name:          "combined - filtered on last",
+			options:       []parseOption{WithMinSeverity(7), WithFacilities(20), WithAppNames("sshd")},
+			expectedError: ErrFiltered,
+		},
+	}
+
+	for _, tc := range testcases {
+		r := NewParser(tc.options...)
+		input := bytes.NewReader(msg)
+		_, err := r.Parse(input)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+		if err != nil {
+			// Filtered messages are returned before the structured data and message are read.
+			assert.NotZero(t, input.Len(), tc.name)
+		}
+	}
+}
+
+func TestWithFacilitiesOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 - An application event log entry...")
+	_, err := NewParser(WithFacilities(4, 24)).Parse(bytes.NewReader(msg))
+	assert.Equal(t, ErrInvalidFacility, err)
+	_, err = NewParser(WithFacilities(0, 23)).Parse(bytes.NewReader(msg))
+	assert.Equal(t, ErrFiltered, err)
+}
+
+func TestParseFields(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\"] An application event log entry...")
+
+	testcases := []struct {
+		name            string
+		msg             []byte
+		options         []parseOption
+		expectedMessage Message
+		expectedError   error
+	}{
+		{
+			name:    "routing fields",
+			msg:     msg,
+			options: []parseOption{WithFields(FieldPRI | FieldHostname | FieldAppName)},
+			expectedMessage: Message{
+				PRI:      common.MustPRI(165),
+				Hostname: "mymachine.example.com",
+				AppName:  "evntslog",
+			},
+		},
+		{
+			name:    "structured data elements and message",
+			msg:     msg,
+			options: []parseOption{WithFields(FieldStructuredData | FieldMessage), WithParseStructuredDataElements()},
+			expectedMessage: Message{
+				StructuredData: "[exampleSDID@32473 iut=\"3\"]",
+				StructuredDataElements: &[]StructuredDataElement{
+					{ID: "exampleSDID@32473", Parameters: map[string]string{"iut": "3"}},
+				},
+				Message: "An application event log entry...",
+			},
+		},
+		{
+			name:    "filtered field is not materialized",
+			msg:     msg,
+			options: []parseOption{WithFields(FieldMsgID), WithAppNames("evntslog")},
+			expectedMessage: Message{
+				MsgID: "ID47",
+			},
+		},
+		{
+			name:    "all fields",
+			msg:     msg,
+			options: []parseOption{WithFields(FieldAll)},
+			expectedMessage: Message{
+				PRI:            common.MustPRI(165),
+				Version:        1,
+				Timestamp:      time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+				Hostname:       "mymachine.example.com",
+				AppName:        "evntslog",
+				MsgID:          "ID47",
+				StructuredData: "[exampleSDID@32473 iut=\"3\"]",
+				Message:        "An application event log entry...",
+			},
+		},
+		{
+			name:          "skipped field is still validated",
+			msg:           []byte("<165>1 2003-10-11T22:14:15.003Z " + strings.Repeat("a", 256) + " evntslog - ID47 - msg"),
+			options:       []parseOption{WithFields(FieldPRI)},
+			expectedError: ErrInvalidHostname,
+		},
+		{
+			name:          "skipped structured data is still validated",
+			msg:           []byte("<165>1 2003-10-11T22:14:15.003Z host app - ID47 [exampleSDID@32473 iut=\"3\""),
+			options:       []parseOption{WithFields(FieldPRI)},
+			expectedError: ErrInvalidStructuredData,
+		},
+	}
+
+	for _, tc := range testcases {
+		r := NewParser(tc.options...)
+		msg, err := r.Parse(bytes.NewReader(tc.msg))
+		assert.Equal(t, tc.expectedMessage, msg, tc.name)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+	}
+}
+
+func TestParseInto(t *testing.T) {
+	t.Parallel()
+
+	r := NewParser(WithParseStructuredDataElements())
+	frames := [][]byte{
+		[]byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\"][examplePriority@32473 class=\"high\"] An application event log entry..."),
+		[]byte("<34>1 2003-10-11T22:14:15.003Z host su - ID48 [exampleSDID@32473 iut=\"4\"] 'su root' failed"),
+		[]byte("<34>1 2003-10-11T22:14:15.003Z host su - ID48 - 'su root' failed"),
+	}
+
+	var m Message
+	for _, frame := range frames {
+		expected, err := r.Parse(bytes.NewReader(frame))
+		assert.Nil(t, err)
+		err = r.ParseInto(bytes.NewReader(frame), &m)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, Message{
+			PRI:                    m.PRI,
+			Version:                m.Version,
+			Timestamp:              m.Timestamp,
+			Hostname:               m.Hostname,
+			AppName:                m.AppName,
+			ProcID:                 m.ProcID,
+			MsgID:                  m.MsgID,
+			StructuredData:         m.StructuredData,
+			StructuredDataElements: m.StructuredDataElements,
+			Message:                m.Message,
+		})
+	}
+
+	err := r.ParseInto(bytes.NewReader([]byte("<34>1 invalid")), &m)
+	assert.Equal(t, ErrInvalidTimestamp, err)
+	assert.Equal(t, "", m.Hostname)
+}
+
+func TestParseBatch(t *testing.T) {
+	t.Parallel()
+
+	r := NewParser()
+	frames := [][]byte{
+		[]byte("<34>1 2003-10-11T22:14:15.003Z host su - ID47 - first"),
+		[]byte("<34>1 invalid"),
+		[]byte("<34>1 2003-10-11T22:14:15.003Z host su - ID47 - third"),
+	}
+
+	msgs, errs := r.ParseBatch(frames, nil)
+	assert.Len(t, msgs, 3)
+	assert.Equal(t, []error{nil, ErrInvalidTimestamp, nil}, errs)
+	assert.Equal(t, "first", msgs[0].Message)
+	assert.Equal(t, PRI{}, msgs[1].PRI)
+	assert.Equal(t, "third", msgs[2].Message)
+
+	msgs, errs = r.ParseBatch(frames[:1], msgs)
+	assert.Len(t, msgs, 1)
+	assert.Nil(t, errs)
+	assert.Equal(t, "first", msgs[0].Message)
+}
+
+func TestMessageElements(t *testing.T) {
+	t.Parallel()
+
+	expected := []StructuredDataElement{
+		{ID: "exampleSDID@32473", Parameters: map[string]string{"iut": "3"}},
+		{ID: "examplePriority@32473", Parameters: map[string]string{"class": "high"}},
+	}
+
+	m := Message{StructuredData: "[exampleSDID@32473 iut=\"3\"][examplePriority@32473 class=\"high\"]"}
+	elements, err := m.Elements()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, elements)
+
+	m = Message{StructuredDataElements: &expected}
+	elements, err = m.Elements()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, elements)
+
+	elements, err = Message{}.Elements()
+	assert.Nil(t, err)
+	assert.Nil(t, elements)
+
+	_, err = Message{StructuredData: "[invalid"}.Elements()
+	assert.Equal(t, ErrInvalidStructuredData, err)
+}
+
+func TestMessageParameter(t *testing.T) {
+	t.Parallel()
+
+	m := Message{StructuredData: "[exampleSDID@32473 iut=\"3\"][examplePriority@32473 class=\"high\"]"}
+	assert.Equal(t, "high", m.Parameter("examplePriority@32473", "class"))
+	assert.Equal(t, "", m.Parameter("examplePriority@32473", "iut"))
+	assert.Equal(t, "", m.Parameter("origin", "ip"))
+
+	elements := []StructuredDataElement{{ID: "origin", Parameters: map[string]string{"ip": "192.0.2.1"}}}
+	assert.Equal(t, "192.0.2.1", Message{StructuredDataElements: &elements}.Parameter("origin", "ip"))
+	assert.Equal(t, "", Message{StructuredData: "[origin ip=\"192.0.2.1\""}.Parameter("origin", "ip"))
+}
+
+func TestFormatStructuredData(t *testing.T) {
+	t.Parallel()
+
+	elements := []StructuredDataElement{
+		{ID: "exampleSDID@32473", Parameters: map[string]string{"iut": "3", "eventSource": "Appl\"ication]"}},
+		{ID: "examplePriority@32473", Parameters: map[string]string{}},
+	}
+	sd := FormatStructuredData(elements)
+	assert.Equal(t, "[exampleSDID@32473 eventSource=\"Appl\\\"ication\\]\" iut=\"3\"][examplePriority@32473]", sd)
+
+	parsed, err := appendStructuredDataElements(nil, sd)
+	assert.Nil(t, err)
+	assert.Equal(t, elements, parsed)
+
+	assert.Equal(t, "", FormatStructuredData(nil))
+}
+
+func TestMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\"][examplePriority@32473 class=\"high\"] An application event log entry...")
+	m, err := NewParser().Parse(bytes.NewReader(msg))
+	assert.Nil(t, err)
+
+	data, err := json.Marshal(m)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"pri": 165,
+		"facility": "local4",
+		"severity": "notice",
+		"version": 1,
+		"timestamp": "2003-10-11T22:14:15.003Z",
+		"hostname": "mymachine.example.com",
+		"app_name": "evntslog",
+		"msgid": "ID47",
+		"structured_data": {
+			"exampleSDID@32473": {"iut": "3", "eventSource": "Application"},
+			"examplePriority@32473": {"class": "high"}
+		},
+		"message": "An application event log entry..."
+	}`, string(data))
+
+	var decoded Message
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, m.PRI, decoded.PRI)
+	assert.Equal(t, m.Hostname, decoded.Hostname)
+	assert.Equal(t, m.MsgID, decoded.MsgID)
+	assert.Equal(t, m.Message, decoded.Message)
+	assert.True(t, m.Timestamp.Equal(decoded.Timestamp))
+	assert.Equal(t, "[examplePriority@32473 class=\"high\"][exampleSDID@32473 eventSource=\"Application\" iut=\"3\"]", decoded.StructuredData)
+	assert.Len(t, *decoded.StructuredDataElements, 2)
+
+	data, err = json.Marshal(Message{PRI: common.MustPRI(13), Version: 1})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"pri": 13, "facility": "user", "severity": "notice", "version": 1}`, string(data))
+
+	_, err = json.Marshal(Message{StructuredData: "[invalid"})
+	assert.ErrorIs(t, err, ErrInvalidStructuredData)
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		data            string
+		expectedMessage Message
+		expectedError   error
+	}{
+		{
+			name:            "pri from names",
+			data:            `{"facility": "auth", "severity": "crit", "version": 1, "message": "hello"}`,
+			expectedMessage: Message{PRI: common.MustPRI(34), Version: 1, Message: "hello"},
+		},
+		{
+			name:            "pri takes precedence",
+			data:            `{"pri": 165, "facility": "auth", "severity": "crit"}`,
+			expectedMessage: Message{PRI: common.MustPRI(165)},
+		},
+		{
+			name:          "invalid pri",
+			data:          `{"pri": 200}`,
+			expectedError: ErrInvalidPRI,
+		},
+		{
+			name:          "unknown facility",
+			data:          `{"facility": "mainframe", "severity": "crit"}`,
+			expectedError: ErrInvalidPRI,
+		},
+	}
+
+	for _, tc := range testcases {
+		var m Message
+		err := json.Unmarshal([]byte(tc.data), &m)
+		assert.Equal(t, tc.expectedMessage, m, tc.name)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+	}
+}
+
+func TestParseDetectJSON(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte(`<165>1 2003-10-11T22:14:15.003Z host app - ID47 [exampleSDID@32473 iut="3"] @cee: {"user": "bob", "id": 42}`)
+
+	m, err := NewParser(WithDetectJSON(), WithParseStructuredDataElements()).Parse(bytes.NewReader(msg))
+	assert.Nil(t, err)
+	assert.Len(t, *m.StructuredDataElements, 1)
+	if assert.NotNil(t, m.JSON) {
+		fields, err := m.JSON.Fields()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]any{"user": "bob", "id": float64(42)}, fields)
+	}
+
+	m, err = NewParser(WithDetectJSON()).Parse(bytes.NewReader([]byte(`<165>1 - host app - - - {"user": "bob"}`)))
+	assert.Nil(t, err)
+	if assert.NotNil(t, m.JSON) {
+		assert.Equal(t, `{"user": "bob"}`, m.JSON.Raw())
+	}
+
+	m, err = NewParser(WithDetectJSON()).Parse(bytes.NewReader([]byte(`<165>1 - host app - - - user bob logged in`)))
+	assert.Nil(t, err)
+	assert.Nil(t, m.JSON)
+
+	m, err = NewParser().Parse(bytes.NewReader(msg))
+	assert.Nil(t, err)
+	assert.Nil(t, m.JSON)
+}
+
 func BenchmarkParse(b *testing.B) {
 	r := Parser{}
 	msg := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"] An application event log entry...")
@@ -640,3 +1004,123 @@ func BenchmarkParse(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkParseFields(b *testing.B) {
+	msg := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"] An application event log entry...")
+
+	benchmarks := []struct {
+		name    string
+		options []parseOption
+	}{
+		{name: "all", options: nil},
+		{name: "all with elements", options: []parseOption{WithParseStructuredDataElements()}},
+		{name: "routing", options: []parseOption{WithFields(FieldPRI | FieldHostname | FieldAppName)}},
+		{name: "pri only", options: []parseOption{WithFields(FieldPRI)}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			r := NewParser(bm.options...)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, err := r.Parse(bytes.NewReader(msg))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseInto(b *testing.B) {
+	r := NewParser()
+	msg := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"] An application event log entry...")
+	var m Message
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := r.ParseInto(bytes.NewReader(msg), &m)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseBatch(b *testing.B) {
+	r := NewParser()
+	msg := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"] An application event log entry...")
+	frames := make([][]byte, 64)
+	for i := range frames {
+		frames[i] = msg
+	}
+	var msgs []Message
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var errs []error
+		msgs, errs = r.ParseBatch(frames, msgs)
+		if errs != nil {
+			b.Fatal(errs)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		message  Message
+		expected string
+	}{
+		{
+			name: "all fields",
+			message: Message{
+				PRI:       common.MustPRI(165),
+				Version:   1,
+				Timestamp: time.Date(2003, time.October, 11, 22, 14, 15, 3000000, time.UTC),
+				Hostname:  "mymachine.example.com",
+				AppName:   "evntslog",
+				ProcID:    "42",
+				MsgID:     "ID47",
+				StructuredDataElements: &[]StructuredDataElement{
+					{ID: "exampleSDID@32473", Parameters: map[string]string{"iut": "3", "eventSource": "Application"}},
+				},
+				Message: "An application event log entry",
+			},
+			expected: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 42 ID47 [exampleSDID@32473 eventSource="Application" iut="3"] An application event log entry`,
+		},
+		{
+			name:     "nil values",
+			message:  Message{PRI: common.MustPRI(34)},
+			expected: "<34>1 - - - - - -",
+		},
+		{
+			name: "raw structured data and nanoseconds",
+			message: Message{
+				PRI:            common.MustPRI(34),
+				Version:        1,
+				Timestamp:      time.Date(2003, time.October, 11, 22, 14, 15, 123456789, time.FixedZone("", -7*3600)),
+				StructuredData: `[origin ip="192.0.2.1"][meta sequenceId="1"]`,
+				Message:        "message",
+			},
+			expected: `<34>1 2003-10-11T22:14:15.123456-07:00 - - - - [origin ip="192.0.2.1"][meta sequenceId="1"] message`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.message.String())
+			assert.Equal(t, "prefix"+tc.expected, string(tc.message.Append([]byte("prefix"))))
+		})
+	}
+}
+
+func TestAppendRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	input := `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"] An application event log entry`
+	m, err := NewParser().Parse(bytes.NewReader([]byte(input)))
+	assert.NoError(t, err)
+	assert.Equal(t, input, m.String())
+}
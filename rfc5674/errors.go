@@ -0,0 +1,12 @@
+package rfc5674
+
+import "errors"
+
+var (
+	ErrNoAlarm          = errors.New("no alarm structured data element")
+	ErrMissingParameter = errors.New("missing required alarm parameter")
+	ErrInvalidParameter = errors.New("invalid alarm parameter")
+	// ErrSeverityMismatch is returned when the severity of the message does not match the perceived severity of
+	// the alarm, as mapped in section 3 of RFC 5674.
+	ErrSeverityMismatch = errors.New("syslog severity does not match perceivedSeverity")
+)
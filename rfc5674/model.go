@@ -0,0 +1,56 @@
+package rfc5674
+
+// Alarm represents the parameters of an alarm structured data element.
+type Alarm struct {
+	// Resource identifies the resource under alarm, e.g. an interface name. Required.
+	Resource string
+	// ResourceURI is an optional URI of the resource under alarm.
+	ResourceURI string
+	// ProbableCause is the numeric IANAItuProbableCause value. Required.
+	ProbableCause uint32
+	// ProbableCauseString is an optional textual description of the probable cause.
+	ProbableCauseString string
+	// PerceivedSeverity is required.
+	PerceivedSeverity PerceivedSeverity
+	// EventType and TrendIndication are optional.
+	EventType       EventType
+	TrendIndication TrendIndication
+}
+
+// PerceivedSeverity is the ITU perceived severity of an alarm.
+type PerceivedSeverity string
+
+const (
+	SeverityCleared       PerceivedSeverity = "cleared"
+	SeverityIndeterminate PerceivedSeverity = "indeterminate"
+	SeverityCritical      PerceivedSeverity = "critical"
+	SeverityMajor         PerceivedSeverity = "major"
+	SeverityMinor         PerceivedSeverity = "minor"
+	SeverityWarning       PerceivedSeverity = "warning"
+)
+
+// EventType is the ITU event type of an alarm.
+type EventType string
+
+const (
+	EventTypeOther                               EventType = "other"
+	EventTypeCommunicationsAlarm                 EventType = "communicationsAlarm"
+	EventTypeQualityOfServiceAlarm               EventType = "qualityOfServiceAlarm"
+	EventTypeProcessingErrorAlarm                EventType = "processingErrorAlarm"
+	EventTypeEquipmentAlarm                      EventType = "equipmentAlarm"
+	EventTypeEnvironmentalAlarm                  EventType = "environmentalAlarm"
+	EventTypeIntegrityViolation                  EventType = "integrityViolation"
+	EventTypeOperationalViolation                EventType = "operationalViolation"
+	EventTypePhysicalViolation                   EventType = "physicalViolation"
+	EventTypeSecurityServiceOrMechanismViolation EventType = "securityServiceOrMechanismViolation"
+	EventTypeTimeDomainViolation                 EventType = "timeDomainViolation"
+)
+
+// TrendIndication indicates whether the severity of an alarm changed.
+type TrendIndication string
+
+const (
+	TrendMoreSevere TrendIndication = "moreSevere"
+	TrendNoChange   TrendIndication = "noChange"
+	TrendLessSevere TrendIndication = "lessSevere"
+)
@@ -0,0 +1,178 @@
+// Package rfc5674 parses and generates the alarm structured data element defined in RFC 5674, which carries ITU
+// alarm information in RFC5424 messages.
+package rfc5674
+
+import (
+	"strconv"
+
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// SDID is the SD-ID of the alarm structured data element.
+const SDID = "alarm"
+
+// severities maps the perceived severities to the syslog severity a message carrying the alarm must have.
+var severities = map[PerceivedSeverity]byte{
+	SeverityCritical:      1,
+	SeverityMajor:         2,
+	SeverityMinor:         3,
+	SeverityWarning:       4,
+	SeverityIndeterminate: 5,
+	SeverityCleared:       5,
+}
+
+var eventTypes = map[EventType]bool{
+	EventTypeOther:                               true,
+	EventTypeCommunicationsAlarm:                 true,
+	EventTypeQualityOfServiceAlarm:               true,
+	EventTypeProcessingErrorAlarm:                true,
+	EventTypeEquipmentAlarm:                      true,
+	EventTypeEnvironmentalAlarm:                  true,
+	EventTypeIntegrityViolation:                  true,
+	EventTypeOperationalViolation:                true,
+	EventTypePhysicalViolation:                   true,
+	EventTypeSecurityServiceOrMechanismViolation: true,
+	EventTypeTimeDomainViolation:                 true,
+}
+
+var trendIndications = map[TrendIndication]bool{
+	TrendMoreSevere: true,
+	TrendNoChange:   true,
+	TrendLessSevere: true,
+}
+
+// Severity returns the syslog severity that corresponds to the perceived severity. The second return value is false
+// for unknown perceived severities.
+func (s PerceivedSeverity) Severity() (byte, bool) {
+	severity, ok := severities[s]
+	return severity, ok
+}
+
+// Parse parses an alarm structured data element.
+func Parse(element rfc5424.StructuredDataElement) (Alarm, error) {
+	if element.ID != SDID {
+		return Alarm{}, ErrNoAlarm
+	}
+
+	parameters := element.Parameters
+	for _, name := range []string{"resource", "probableCause", "perceivedSeverity"} {
+		if _, ok := parameters[name]; !ok {
+			return Alarm{}, ErrMissingParameter
+		}
+	}
+
+	cause, err := strconv.ParseUint(parameters["probableCause"], 10, 32)
+	if err != nil {
+		return Alarm{}, ErrInvalidParameter
+	}
+	a := Alarm{
+		Resource:            parameters["resource"],
+		ResourceURI:         parameters["resourceURI"],
+		ProbableCause:       uint32(cause),
+		ProbableCauseString: parameters["probableCauseString"],
+		PerceivedSeverity:   PerceivedSeverity(parameters["perceivedSeverity"]),
+		EventType:           EventType(parameters["eventType"]),
+		TrendIndication:     TrendIndication(parameters["trendIndication"]),
+	}
+	if err := a.Validate(); err != nil {
+		return Alarm{}, err
+	}
+	return a, nil
+}
+
+// FromMessage parses the alarm element of the message and checks that the severity of the message matches the
+// perceived severity of the alarm. ErrNoAlarm is returned if the message has no alarm element.
+func FromMessage(m rfc5424.Message) (Alarm, error) {
+	elements, err := m.Elements()
+	if err != nil {
+		return Alarm{}, err
+	}
+	for _, element := range elements {
+		if element.ID != SDID {
+			continue
+		}
+		a, err := Parse(element)
+		if err != nil {
+			return Alarm{}, err
+		}
+		if severity, _ := a.PerceivedSeverity.Severity(); severity != m.PRI.Severity() {
+			return Alarm{}, ErrSeverityMismatch
+		}
+		return a, nil
+	}
+	return Alarm{}, ErrNoAlarm
+}
+
+// Validate checks that the required parameters are set and that the enumerated parameters have a known value.
+func (a Alarm) Validate() error {
+	if a.Resource == "" {
+		return ErrMissingParameter
+	}
+	if _, ok := a.PerceivedSeverity.Severity(); !ok {
+		return ErrInvalidParameter
+	}
+	if a.EventType != "" && !eventTypes[a.EventType] {
+		return ErrInvalidParameter
+	}
+	if a.TrendIndication != "" && !trendIndications[a.TrendIndication] {
+		return ErrInvalidParameter
+	}
+	return nil
+}
+
+// Element returns the alarm as a structured data element. Optional parameters are only included when set.
+func (a Alarm) Element() (rfc5424.StructuredDataElement, error) {
+	if err := a.Validate(); err != nil {
+		return rfc5424.StructuredDataElement{}, err
+	}
+
+	parameters := map[string]string{
+		"resource":          a.Resource,
+		"probableCause":     strconv.FormatUint(uint64(a.ProbableCause), 10),
+		"perceivedSeverity": string(a.PerceivedSeverity),
+	}
+	optional := map[string]string{
+		"resourceURI":         a.ResourceURI,
+		"probableCauseString": a.ProbableCauseString,
+		"eventType":           string(a.EventType),
+		"trendIndication":     string(a.TrendIndication),
+	}
+	for name, value := range optional {
+		if value != "" {
+			parameters[name] = value
+		}
+	}
+	return rfc5424.StructuredDataElement{ID: SDID, Parameters: parameters}, nil
+}
+
+// Apply adds the alarm element to the message, replacing an existing one, and sets the severity of the message to
+// the one mapped from the perceived severity. The facility is kept.
+func (a Alarm) Apply(m *rfc5424.Message) error {
+	element, err := a.Element()
+	if err != nil {
+		return err
+	}
+	elements, err := m.Elements()
+	if err != nil {
+		return err
+	}
+
+	updated := make([]rfc5424.StructuredDataElement, 0, len(elements)+1)
+	for _, e := range elements {
+		if e.ID != SDID {
+			updated = append(updated, e)
+		}
+	}
+	updated = append(updated, element)
+
+	severity, _ := a.PerceivedSeverity.Severity()
+	pri, err := common.NewPRIFromParts(m.PRI.Facility(), severity)
+	if err != nil {
+		return err
+	}
+	m.PRI = pri
+	m.StructuredDataElements = &updated
+	m.StructuredData = rfc5424.FormatStructuredData(updated)
+	return nil
+}
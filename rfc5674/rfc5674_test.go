@@ -0,0 +1,195 @@
+//nolint:lll
+package rfc5674
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		element       rfc5424.StructuredDataElement
+		expectedAlarm Alarm
+		expectedError error
+	}{
+		{
+			name: "all parameters",
+			element: rfc5424.StructuredDataElement{ID: "alarm", Parameters: map[string]string{
+				"resource":            "su root",
+				"resourceURI":         "snmp://192.0.2.1/ifIndex.3",
+				"probableCause":       "1024",
+				"probableCauseString": "unauthorizedAccessAttempt",
+				"perceivedSeverity":   "major",
+				"eventType":           "securityServiceOrMechanismViolation",
+				"trendIndication":     "moreSevere",
+			}},
+			expectedAlarm: Alarm{
+				Resource:            "su root",
+				ResourceURI:         "snmp://192.0.2.1/ifIndex.3",
+				ProbableCause:       1024,
+				ProbableCauseString: "unauthorizedAccessAttempt",
+				PerceivedSeverity:   SeverityMajor,
+				EventType:           EventTypeSecurityServiceOrMechanismViolation,
+				TrendIndication:     TrendMoreSevere,
+			},
+		},
+		{
+			name: "required parameters",
+			element: rfc5424.StructuredDataElement{ID: "alarm", Parameters: map[string]string{
+				"resource": "eth0", "probableCause": "0", "perceivedSeverity": "cleared",
+			}},
+			expectedAlarm: Alarm{Resource: "eth0", PerceivedSeverity: SeverityCleared},
+		},
+		{
+			name:          "other element",
+			element:       rfc5424.StructuredDataElement{ID: "origin", Parameters: map[string]string{"ip": "192.0.2.1"}},
+			expectedError: ErrNoAlarm,
+		},
+		{
+			name: "missing resource",
+			element: rfc5424.StructuredDataElement{ID: "alarm", Parameters: map[string]string{
+				"probableCause": "0", "perceivedSeverity": "cleared",
+			}},
+			expectedError: ErrMissingParameter,
+		},
+		{
+			name: "empty resource",
+			element: rfc5424.StructuredDataElement{ID: "alarm", Parameters: map[string]string{
+				"resource": "", "probableCause": "0", "perceivedSeverity": "cleared",
+			}},
+			expectedError: ErrMissingParameter,
+		},
+		{
+			name: "textual probable cause",
+			element: rfc5424.StructuredDataElement{ID: "alarm", Parameters: map[string]string{
+				"resource": "eth0", "probableCause": "lossOfSignal", "perceivedSeverity": "cleared",
+			}},
+			expectedError: ErrInvalidParameter,
+		},
+		{
+			name: "unknown perceived severity",
+			element: rfc5424.StructuredDataElement{ID: "alarm", Parameters: map[string]string{
+				"resource": "eth0", "probableCause": "0", "perceivedSeverity": "emergency",
+			}},
+			expectedError: ErrInvalidParameter,
+		},
+		{
+			name: "unknown event type",
+			element: rfc5424.StructuredDataElement{ID: "alarm", Parameters: map[string]string{
+				"resource": "eth0", "probableCause": "0", "perceivedSeverity": "minor", "eventType": "alarm",
+			}},
+			expectedError: ErrInvalidParameter,
+		},
+		{
+			name: "unknown trend indication",
+			element: rfc5424.StructuredDataElement{ID: "alarm", Parameters: map[string]string{
+				"resource": "eth0", "probableCause": "0", "perceivedSeverity": "minor", "trendIndication": "worse",
+			}},
+			expectedError: ErrInvalidParameter,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, err := Parse(tc.element)
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedAlarm, a)
+		})
+	}
+}
+
+func TestFromMessage(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		input         string
+		expectedAlarm Alarm
+		expectedError error
+	}{
+		{
+			name:          "matching severity",
+			input:         `<129>1 2024-03-14T10:20:30Z router linkd - - [origin ip="192.0.2.1"][alarm resource="eth0" probableCause="1" perceivedSeverity="critical"] link down`,
+			expectedAlarm: Alarm{Resource: "eth0", ProbableCause: 1, PerceivedSeverity: SeverityCritical},
+		},
+		{
+			name:          "warning",
+			input:         `<132>1 - router linkd - - [alarm resource="eth0" probableCause="1" perceivedSeverity="warning"] link flapping`,
+			expectedAlarm: Alarm{Resource: "eth0", ProbableCause: 1, PerceivedSeverity: SeverityWarning},
+		},
+		{
+			name:          "severity mismatch",
+			input:         `<134>1 - router linkd - - [alarm resource="eth0" probableCause="1" perceivedSeverity="critical"] link down`,
+			expectedError: ErrSeverityMismatch,
+		},
+		{
+			name:          "no alarm",
+			input:         `<134>1 - router linkd - - [origin ip="192.0.2.1"] link down`,
+			expectedError: ErrNoAlarm,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := rfc5424.NewParser().Parse(bytes.NewReader([]byte(tc.input)))
+			assert.NoError(t, err)
+			a, err := FromMessage(m)
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedAlarm, a)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	m, err := rfc5424.NewParser().Parse(bytes.NewReader([]byte(`<134>1 - router linkd - - [alarm resource="eth1" probableCause="1" perceivedSeverity="major"][origin ip="192.0.2.1"] link down`)))
+	assert.NoError(t, err)
+
+	a := Alarm{
+		Resource:          "eth0",
+		ProbableCause:     1,
+		PerceivedSeverity: SeverityMinor,
+		EventType:         EventTypeCommunicationsAlarm,
+	}
+	assert.NoError(t, a.Apply(&m))
+	assert.Equal(t, byte(16), m.PRI.Facility())
+	assert.Equal(t, byte(3), m.PRI.Severity())
+	assert.Equal(t, `[origin ip="192.0.2.1"][alarm eventType="communicationsAlarm" perceivedSeverity="minor" probableCause="1" resource="eth0"]`, m.StructuredData)
+
+	parsed, err := FromMessage(m)
+	assert.NoError(t, err)
+	assert.Equal(t, a, parsed)
+
+	assert.ErrorIs(t, Alarm{Resource: "eth0"}.Apply(&m), ErrInvalidParameter)
+}
+
+func TestSeverity(t *testing.T) {
+	t.Parallel()
+
+	expected := map[PerceivedSeverity]byte{
+		SeverityCritical:      1,
+		SeverityMajor:         2,
+		SeverityMinor:         3,
+		SeverityWarning:       4,
+		SeverityIndeterminate: 5,
+		SeverityCleared:       5,
+	}
+	for perceived, severity := range expected {
+		actual, ok := perceived.Severity()
+		assert.True(t, ok)
+		assert.Equal(t, severity, actual, perceived)
+	}
+	_, ok := PerceivedSeverity("fatal").Severity()
+	assert.False(t, ok)
+}
@@ -0,0 +1,13 @@
+package rfc5848
+
+import "errors"
+
+var (
+	ErrUnsupportedKey     = errors.New("unsupported key type")
+	ErrUnsupportedVersion = errors.New("unsupported VER")
+	ErrInvalidBlock       = errors.New("invalid signature or certificate block")
+	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrInvalidPayload     = errors.New("invalid payload block")
+	ErrNoKey              = errors.New("no public key to verify the signature block")
+	ErrKeyMismatch        = errors.New("certificate block key does not match the trusted key")
+)
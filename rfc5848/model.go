@@ -0,0 +1,73 @@
+package rfc5848
+
+import "time"
+
+// SignatureBlock represents the parameters of an ssign structured data element.
+type SignatureBlock struct {
+	// Version is the VER parameter, consisting of the protocol version, hash algorithm and signature scheme.
+	Version string
+	// RebootSessionID is the RSID parameter, which changes on every restart of the signer.
+	RebootSessionID    uint64
+	SignatureGroup     byte
+	SignaturePriority  byte
+	GlobalBlockCounter uint64
+	// FirstMessageNumber is the number of the message of which the hash is the first in Hashes.
+	FirstMessageNumber uint64
+	// Hashes holds the hashes of consecutive messages, in the order in which they were sent.
+	Hashes    [][]byte
+	Signature []byte
+}
+
+// CertificateBlock represents the parameters of an ssign-cert structured data element, which carries a fragment of
+// the payload block.
+type CertificateBlock struct {
+	Version            string
+	RebootSessionID    uint64
+	SignatureGroup     byte
+	SignaturePriority  byte
+	TotalPayloadLength int
+	// Index is the position of Fragment in the payload block, starting at 1.
+	Index     int
+	Fragment  []byte
+	Signature []byte
+}
+
+// Payload is the payload block that is distributed using certificate blocks.
+type Payload struct {
+	Timestamp time.Time
+	// KeyBlobType is 'K' for a PKIX public key and 'C' for an X.509 certificate.
+	KeyBlobType byte
+	KeyBlob     []byte
+}
+
+// Report describes the outcome of verifying a stream.
+type Report struct {
+	// Verified holds the numbers of the messages that were received unchanged.
+	Verified []MessageNumber
+	// Missing holds the numbers of the messages that were signed but not received.
+	Missing []MessageNumber
+	// Reordered holds the numbers of verified messages that were received before a message with a lower number.
+	Reordered []MessageNumber
+	// Modified holds the messages that were received in the place of a signed message, but with a different hash.
+	Modified []Modified
+	// Unsigned holds the received messages that are not covered by any valid signature block. These were injected,
+	// modified beyond recognition of their place, or their signature block was not received yet.
+	Unsigned [][]byte
+	// InvalidBlocks is the number of signature and certificate blocks that could not be verified.
+	InvalidBlocks int
+}
+
+// Modified is a received message that replaced a signed message.
+type Modified struct {
+	Number MessageNumber
+	Frame  []byte
+}
+
+// MessageNumber identifies a signed message. Messages are numbered from 1 within each combination of reboot session,
+// signature group and signature priority.
+type MessageNumber struct {
+	RebootSessionID   uint64
+	SignatureGroup    byte
+	SignaturePriority byte
+	Number            uint64
+}
@@ -0,0 +1,51 @@
+package rfc5848
+
+import "time"
+
+type signerOption func(*Signer)
+
+// WithRebootSessionID sets the RSID, which must be increased every time the signer restarts so that verifiers can
+// distinguish the sessions. It defaults to 0, which indicates that the signer does not persist it.
+func WithRebootSessionID(rsid uint64) signerOption {
+	return func(s *Signer) {
+		s.rsid = rsid
+	}
+}
+
+// WithSignaturePriority sets the SPRI, which is used as PRI of the signature and certificate block messages. It
+// defaults to 110, facility log audit with severity informational.
+func WithSignaturePriority(spri byte) signerOption {
+	return func(s *Signer) {
+		s.spri = spri
+	}
+}
+
+// WithMaxHashes sets the number of message hashes after which a signature block is emitted, between 1 and 99. It
+// defaults to 25, which keeps signature blocks well below the 2048 octets every receiver must accept.
+func WithMaxHashes(n int) signerOption {
+	return func(s *Signer) {
+		s.maxHashes = min(max(n, 1), 99)
+	}
+}
+
+// WithHostname sets the HOSTNAME of the signature and certificate block messages.
+func WithHostname(hostname string) signerOption {
+	return func(s *Signer) {
+		s.hostname = hostname
+	}
+}
+
+// WithAppName sets the APP-NAME of the signature and certificate block messages.
+func WithAppName(appName string) signerOption {
+	return func(s *Signer) {
+		s.appName = appName
+	}
+}
+
+// WithNow sets the function returning the current time, used for the TIMESTAMP of the block messages and the
+// payload block. It defaults to time.Now.
+func WithNow(now func() time.Time) signerOption {
+	return func(s *Signer) {
+		s.now = now
+	}
+}
@@ -0,0 +1,290 @@
+// Package rfc5848 generates and verifies signed syslog messages as defined in RFC 5848.
+//
+// A Signer hashes every message of a stream and periodically emits a signature block message holding the hashes and
+// a signature over them. Certificate block messages distribute the public key. A Verifier checks the signature
+// blocks of a received stream and reports which messages were verified, missing, reordered or modified.
+//
+// Messages are hashed using SHA-256. RFC 5848 only registers OpenPGP DSA as signature scheme, which is not
+// supported. ECDSA, RSA and Ed25519 keys are identified by the private signature scheme values 2, 3 and 4 in the VER
+// parameter, so verifiers of other implementations will not accept the signatures.
+package rfc5848
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// SD-IDs of the signature and certificate blocks.
+const (
+	SignatureBlockID   = "ssign"
+	CertificateBlockID = "ssign-cert"
+)
+
+// The parts of the VER parameter.
+const (
+	protocolVersion = "01"
+	hashSHA256      = '2'
+	schemeECDSA     = '2'
+	schemeRSA       = '3'
+	schemeEd25519   = '4'
+)
+
+// Limits of the certificate block parameters. The payload block only holds a timestamp and a key or certificate, so
+// larger payloads are rejected before any memory is allocated for them.
+const (
+	maxPayloadLength  = 64 * 1024
+	maxFragmentLength = 9999
+)
+
+// Key blob types of the payload block.
+const (
+	KeyBlobPKIX        byte = 'K'
+	KeyBlobCertificate byte = 'C'
+)
+
+// hash returns the hash of a complete syslog message, as it is included in the signature blocks.
+func hash(frame []byte) []byte {
+	sum := sha256.Sum256(frame)
+	return sum[:]
+}
+
+// versionForKey returns the VER parameter for signatures made with the key.
+func versionForKey(public crypto.PublicKey) (string, error) {
+	var scheme byte
+	switch public.(type) {
+	case ed25519.PublicKey:
+		scheme = schemeEd25519
+	case *ecdsa.PublicKey:
+		scheme = schemeECDSA
+	case *rsa.PublicKey:
+		scheme = schemeRSA
+	default:
+		return "", ErrUnsupportedKey
+	}
+	return protocolVersion + string([]byte{hashSHA256, scheme}), nil
+}
+
+// checkVersion checks that the VER parameter is supported and matches the key.
+func checkVersion(version string, public crypto.PublicKey) error {
+	if len(version) != 4 || version[:2] != protocolVersion || version[2] != hashSHA256 {
+		return ErrUnsupportedVersion
+	}
+	expected, err := versionForKey(public)
+	if err != nil {
+		return err
+	}
+	if version != expected {
+		return ErrUnsupportedVersion
+	}
+	return nil
+}
+
+func sign(key crypto.Signer, data []byte) ([]byte, error) {
+	if _, ok := key.Public().(ed25519.PublicKey); ok {
+		return key.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(data)
+	return key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+func verify(public crypto.PublicKey, data, signature []byte) error {
+	digest := sha256.Sum256(data)
+	var ok bool
+	switch key := public.(type) {
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, data, signature)
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return ErrUnsupportedKey
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// params writes SD-PARAMs in the order defined by the RFC. The values never need escaping.
+type params struct {
+	builder strings.Builder
+}
+
+func newParams(id string) *params {
+	p := &params{}
+	p.builder.WriteByte('[')
+	p.builder.WriteString(id)
+	return p
+}
+
+func (p *params) add(name, value string) {
+	p.builder.WriteByte(' ')
+	p.builder.WriteString(name)
+	p.builder.WriteString(`="`)
+	p.builder.WriteString(value)
+	p.builder.WriteByte('"')
+}
+
+// signed returns the element without the SIGN parameter, which is the data that is signed.
+func (p *params) signed() string {
+	return p.builder.String() + "]"
+}
+
+// element returns the complete element with the SIGN parameter.
+func (p *params) element(signature []byte) string {
+	p.add("SIGN", base64.StdEncoding.EncodeToString(signature))
+	p.builder.WriteByte(']')
+	return p.builder.String()
+}
+
+func (b SignatureBlock) params() *params {
+	p := newParams(SignatureBlockID)
+	p.add("VER", b.Version)
+	p.add("RSID", strconv.FormatUint(b.RebootSessionID, 10))
+	p.add("SG", strconv.Itoa(int(b.SignatureGroup)))
+	p.add("SPRI", strconv.Itoa(int(b.SignaturePriority)))
+	p.add("GBC", strconv.FormatUint(b.GlobalBlockCounter, 10))
+	p.add("FMN", strconv.FormatUint(b.FirstMessageNumber, 10))
+	p.add("CNT", strconv.Itoa(len(b.Hashes)))
+	hashes := make([]string, len(b.Hashes))
+	for i, h := range b.Hashes {
+		hashes[i] = base64.StdEncoding.EncodeToString(h)
+	}
+	p.add("HB", strings.Join(hashes, " "))
+	return p
+}
+
+func (b CertificateBlock) params() *params {
+	p := newParams(CertificateBlockID)
+	p.add("VER", b.Version)
+	p.add("RSID", strconv.FormatUint(b.RebootSessionID, 10))
+	p.add("SG", strconv.Itoa(int(b.SignatureGroup)))
+	p.add("SPRI", strconv.Itoa(int(b.SignaturePriority)))
+	p.add("TPBL", strconv.Itoa(b.TotalPayloadLength))
+	p.add("INDEX", strconv.Itoa(b.Index))
+	p.add("FLEN", strconv.Itoa(len(b.Fragment)))
+	p.add("FRAG", string(b.Fragment))
+	return p
+}
+
+// ParseSignatureBlock parses the parameters of an ssign element.
+func ParseSignatureBlock(element rfc5424.StructuredDataElement) (SignatureBlock, error) {
+	if element.ID != SignatureBlockID {
+		return SignatureBlock{}, ErrInvalidBlock
+	}
+	var r paramReader
+	b := SignatureBlock{
+		Version:            element.Parameters["VER"],
+		RebootSessionID:    r.number(element.Parameters["RSID"], 9999999999),
+		SignatureGroup:     byte(r.number(element.Parameters["SG"], 3)),
+		SignaturePriority:  byte(r.number(element.Parameters["SPRI"], 191)),
+		GlobalBlockCounter: r.number(element.Parameters["GBC"], 9999999999),
+		FirstMessageNumber: r.number(element.Parameters["FMN"], 9999999999),
+		Signature:          r.decode(element.Parameters["SIGN"]),
+	}
+	count := r.number(element.Parameters["CNT"], 99)
+	for _, h := range strings.Fields(element.Parameters["HB"]) {
+		b.Hashes = append(b.Hashes, r.decode(h))
+	}
+	if r.err != nil || b.FirstMessageNumber == 0 || count == 0 || uint64(len(b.Hashes)) != count {
+		return SignatureBlock{}, ErrInvalidBlock
+	}
+	return b, nil
+}
+
+// ParseCertificateBlock parses the parameters of an ssign-cert element.
+func ParseCertificateBlock(element rfc5424.StructuredDataElement) (CertificateBlock, error) {
+	if element.ID != CertificateBlockID {
+		return CertificateBlock{}, ErrInvalidBlock
+	}
+	var r paramReader
+	b := CertificateBlock{
+		Version:            element.Parameters["VER"],
+		RebootSessionID:    r.number(element.Parameters["RSID"], 9999999999),
+		SignatureGroup:     byte(r.number(element.Parameters["SG"], 3)),
+		SignaturePriority:  byte(r.number(element.Parameters["SPRI"], 191)),
+		TotalPayloadLength: int(r.number(element.Parameters["TPBL"], maxPayloadLength)),
+		Index:              int(r.number(element.Parameters["INDEX"], 9999999999)),
+		Fragment:           []byte(element.Parameters["FRAG"]),
+		Signature:          r.decode(element.Parameters["SIGN"]),
+	}
+	length := r.number(element.Parameters["FLEN"], maxFragmentLength)
+	if r.err != nil || b.Index == 0 || int(length) != len(b.Fragment) ||
+		b.Index-1+len(b.Fragment) > b.TotalPayloadLength {
+		return CertificateBlock{}, ErrInvalidBlock
+	}
+	return b, nil
+}
+
+// paramReader converts parameter values, keeping the first error.
+type paramReader struct {
+	err error
+}
+
+func (r *paramReader) number(value string, max uint64) uint64 {
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || n > max {
+		r.err = ErrInvalidBlock
+	}
+	return n
+}
+
+func (r *paramReader) decode(value string) []byte {
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(b) == 0 {
+		r.err = ErrInvalidBlock
+	}
+	return b
+}
+
+// encode formats the payload block as its timestamp, key blob type and base64 encoded key blob, separated by spaces.
+func (p Payload) encode() []byte {
+	return []byte(p.Timestamp.UTC().Format(time.RFC3339) + " " + string(p.KeyBlobType) + " " +
+		base64.StdEncoding.EncodeToString(p.KeyBlob))
+}
+
+// ParsePayload parses a payload block as reassembled from the fragments of the certificate blocks.
+func ParsePayload(data []byte) (Payload, error) {
+	fields := bytes.Fields(data)
+	if len(fields) != 3 || len(fields[1]) != 1 {
+		return Payload{}, ErrInvalidPayload
+	}
+	timestamp, err := time.Parse(time.RFC3339, string(fields[0]))
+	if err != nil {
+		return Payload{}, ErrInvalidPayload
+	}
+	blob, err := base64.StdEncoding.DecodeString(string(fields[2]))
+	if err != nil {
+		return Payload{}, ErrInvalidPayload
+	}
+	return Payload{Timestamp: timestamp, KeyBlobType: fields[1][0], KeyBlob: blob}, nil
+}
+
+// PublicKey returns the public key held by the payload.
+func (p Payload) PublicKey() (crypto.PublicKey, error) {
+	switch p.KeyBlobType {
+	case KeyBlobPKIX:
+		return x509.ParsePKIXPublicKey(p.KeyBlob)
+	case KeyBlobCertificate:
+		certificate, err := x509.ParseCertificate(p.KeyBlob)
+		if err != nil {
+			return nil, err
+		}
+		return certificate.PublicKey, nil
+	default:
+		return nil, ErrInvalidPayload
+	}
+}
@@ -0,0 +1,293 @@
+//nolint:lll
+package rfc5848
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func now() time.Time {
+	return time.Date(2024, time.March, 14, 10, 20, 30, 0, time.UTC)
+}
+
+func generateKeys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	rs, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return map[string]crypto.Signer{"ed25519": ed, "ecdsa": ec, "rsa": rs}
+}
+
+func messages(n int) [][]byte {
+	frames := make([][]byte, n)
+	for i := range frames {
+		frames[i] = []byte(fmt.Sprintf("<86>1 2024-03-14T10:20:%02dZ host sshd %d - - message %d", i, 1000+i, i+1))
+	}
+	return frames
+}
+
+// sign returns the stream as sent by a signer: the certificate blocks followed by the messages and signature blocks.
+func signStream(t *testing.T, s *Signer, frames [][]byte) [][]byte {
+	t.Helper()
+
+	stream, err := s.CertificateBlocks()
+	assert.NoError(t, err)
+	for _, frame := range frames {
+		stream = append(stream, frame)
+		block, err := s.Add(frame)
+		assert.NoError(t, err)
+		if block != nil {
+			stream = append(stream, block)
+		}
+	}
+	block, err := s.Flush()
+	assert.NoError(t, err)
+	return append(stream, block)
+}
+
+func numbers(values ...uint64) []MessageNumber {
+	result := make([]MessageNumber, len(values))
+	for i, value := range values {
+		result[i] = MessageNumber{SignaturePriority: 110, Number: value}
+	}
+	return result
+}
+
+func TestSignAndVerify(t *testing.T) {
+	t.Parallel()
+
+	for name, key := range generateKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := NewSigner(key, WithMaxHashes(2), WithNow(now), WithHostname("host"), WithAppName("signer"))
+			assert.NoError(t, err)
+			stream := signStream(t, s, messages(5))
+			assert.Len(t, stream, 9)
+
+			for _, trusted := range []crypto.PublicKey{nil, key.Public()} {
+				v := NewVerifier(trusted)
+				for _, frame := range stream {
+					assert.NoError(t, v.Add(frame))
+				}
+				assert.Equal(t, Report{Verified: numbers(1, 2, 3, 4, 5)}, v.Report())
+			}
+		})
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	s, err := NewSigner(key, WithNow(now))
+	assert.NoError(t, err)
+
+	frames := messages(6)
+	stream := signStream(t, s, frames)
+	certificate, block := stream[0], stream[len(stream)-1]
+
+	modified := bytes.Replace(frames[3], []byte("message 4"), []byte("message X"), 1)
+	injected := []byte("<86>1 - host sshd - - - injected")
+
+	// Message 2 is dropped, message 4 is modified and messages 5 and 6 are swapped.
+	received := [][]byte{certificate, frames[0], frames[2], modified, frames[5], frames[4], block, block, injected}
+
+	v := NewVerifier(nil)
+	for _, frame := range received {
+		assert.NoError(t, v.Add(frame))
+	}
+	assert.Equal(t, Report{
+		Verified:  numbers(1, 3, 5, 6),
+		Missing:   numbers(2),
+		Reordered: numbers(6),
+		Modified:  []Modified{{Number: numbers(4)[0], Frame: modified}},
+		Unsigned:  [][]byte{injected},
+	}, v.Report())
+}
+
+func TestVerifyInvalidBlocks(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	s, err := NewSigner(key, WithNow(now))
+	assert.NoError(t, err)
+	stream := signStream(t, s, messages(2))
+	certificate, block := stream[0], stream[len(stream)-1]
+
+	t.Run("no key", func(t *testing.T) {
+		t.Parallel()
+
+		v := NewVerifier(nil)
+		assert.ErrorIs(t, v.Add(block), ErrNoKey)
+		assert.Equal(t, 1, v.Report().InvalidBlocks)
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		t.Parallel()
+
+		v := NewVerifier(other.Public())
+		assert.ErrorIs(t, v.Add(certificate), ErrKeyMismatch)
+		assert.ErrorIs(t, v.Add(block), ErrInvalidSignature)
+		assert.Equal(t, 2, v.Report().InvalidBlocks)
+	})
+
+	t.Run("modified signature block", func(t *testing.T) {
+		t.Parallel()
+
+		v := NewVerifier(key.Public())
+		assert.ErrorIs(t, v.Add(bytes.Replace(block, []byte(`FMN="1"`), []byte(`FMN="2"`), 1)), ErrInvalidSignature)
+	})
+
+	t.Run("malformed signature block", func(t *testing.T) {
+		t.Parallel()
+
+		v := NewVerifier(key.Public())
+		assert.ErrorIs(t, v.Add(bytes.Replace(block, []byte(`CNT="2"`), []byte(`CNT="3"`), 1)), ErrInvalidBlock)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		t.Parallel()
+
+		v := NewVerifier(key.Public())
+		assert.ErrorIs(t, v.Add(bytes.Replace(block, []byte(`VER="0124"`), []byte(`VER="0111"`), 1)), ErrUnsupportedVersion)
+	})
+
+	t.Run("oversized payload", func(t *testing.T) {
+		t.Parallel()
+
+		v := NewVerifier(nil)
+		frame := []byte(`<110>1 - - - - - [ssign-cert VER="0124" RSID="1" SG="0" SPRI="0" TPBL="9999999999" INDEX="9999999990" FLEN="4" FRAG="AAAA" SIGN="AAAA"]`)
+		assert.ErrorIs(t, v.Add(frame), ErrInvalidBlock)
+		assert.Empty(t, v.payloads)
+	})
+}
+
+func TestCertificateBlockFragments(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	s, err := NewSigner(key, WithNow(now), WithRebootSessionID(7))
+	assert.NoError(t, err)
+	s.fragmentSize = 16
+
+	certificates, err := s.CertificateBlocks()
+	assert.NoError(t, err)
+	assert.Greater(t, len(certificates), 3)
+
+	frames := messages(3)
+	v := NewVerifier(nil)
+	// Fragments may arrive in any order.
+	for i := len(certificates) - 1; i >= 0; i-- {
+		assert.NoError(t, v.Add(certificates[i]))
+	}
+	for _, frame := range frames {
+		assert.NoError(t, v.Add(frame))
+		_, err := s.Add(frame)
+		assert.NoError(t, err)
+	}
+	block, err := s.Flush()
+	assert.NoError(t, err)
+	assert.NoError(t, v.Add(block))
+
+	report := v.Report()
+	assert.Len(t, report.Verified, 3)
+	assert.Equal(t, uint64(7), report.Verified[0].RebootSessionID)
+}
+
+func TestSignerBlocks(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	s, err := NewSigner(key, WithNow(now), WithSignaturePriority(13), WithHostname("host"), WithAppName("app"))
+	assert.NoError(t, err)
+
+	block, err := s.Flush()
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+
+	frame := messages(1)[0]
+	_, err = s.Add(frame)
+	assert.NoError(t, err)
+	block, err = s.Flush()
+	assert.NoError(t, err)
+
+	m, err := rfc5424.NewParser(rfc5424.WithParseStructuredDataElements()).Parse(bytes.NewReader(block))
+	assert.NoError(t, err)
+	assert.Equal(t, byte(13), m.PRI.Value())
+	assert.Equal(t, now(), m.Timestamp)
+	assert.Equal(t, "host", m.Hostname)
+	assert.Equal(t, "app", m.AppName)
+	assert.True(t, strings.HasPrefix(m.StructuredData, `[ssign VER="0124" RSID="0" SG="0" SPRI="13" GBC="0" FMN="1" CNT="1" HB="`))
+
+	b, err := ParseSignatureBlock((*m.StructuredDataElements)[0])
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{hash(frame)}, b.Hashes)
+	assert.NoError(t, verify(key.Public(), []byte(b.params().signed()), b.Signature))
+
+	_, err = NewSigner(unsupportedSigner{key})
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
+
+// unsupportedSigner wraps a key to hide its type.
+type unsupportedSigner struct {
+	crypto.Signer
+}
+
+func (s unsupportedSigner) Public() crypto.PublicKey {
+	return struct{}{}
+}
+
+func TestParsePayload(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	s, err := NewSigner(key, WithNow(now))
+	assert.NoError(t, err)
+	certificates, err := s.CertificateBlocks()
+	assert.NoError(t, err)
+
+	m, err := rfc5424.NewParser(rfc5424.WithParseStructuredDataElements()).Parse(bytes.NewReader(certificates[0]))
+	assert.NoError(t, err)
+	b, err := ParseCertificateBlock((*m.StructuredDataElements)[0])
+	assert.NoError(t, err)
+
+	payload, err := ParsePayload(b.Fragment)
+	assert.NoError(t, err)
+	assert.Equal(t, now(), payload.Timestamp)
+	assert.Equal(t, KeyBlobPKIX, payload.KeyBlobType)
+	public, err := payload.PublicKey()
+	assert.NoError(t, err)
+	assert.Equal(t, key.Public(), public)
+
+	for _, input := range []string{"", "2024-03-14T10:20:30Z K", "yesterday K AAAA", "2024-03-14T10:20:30Z K !!!"} {
+		_, err := ParsePayload([]byte(input))
+		assert.ErrorIs(t, err, ErrInvalidPayload, input)
+	}
+	_, err = Payload{KeyBlobType: 'P'}.PublicKey()
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
@@ -0,0 +1,143 @@
+package rfc5848
+
+import (
+	"crypto"
+	"crypto/x509"
+	"time"
+
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// maxFragment is the maximum length of a payload block fragment in a certificate block.
+const maxFragment = 1024
+
+// Signer generates the signature and certificate blocks for a stream of messages. It is not safe for concurrent use,
+// as the order of the messages is part of what is signed.
+type Signer struct {
+	key       crypto.Signer
+	version   string
+	rsid      uint64
+	spri      byte
+	maxHashes int
+	hostname  string
+	appName   string
+	now       func() time.Time
+	// fragmentSize is the maximum length of a payload block fragment.
+	fragmentSize int
+
+	gbc    uint64
+	next   uint64
+	hashes [][]byte
+}
+
+// NewSigner creates a Signer that signs with the key, which must be an Ed25519, ECDSA or RSA key.
+func NewSigner(key crypto.Signer, options ...signerOption) (*Signer, error) {
+	version, err := versionForKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	s := &Signer{
+		key:       key,
+		version:   version,
+		spri:      110,
+		maxHashes: 25,
+		now:       time.Now,
+		next:      1,
+
+		fragmentSize: maxFragment,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s, nil
+}
+
+// Add records the hash of a message frame, which must be the exact bytes that are sent. When enough hashes have
+// been collected, the signature block message that must be sent after the frame is returned. Otherwise nil is
+// returned.
+func (s *Signer) Add(frame []byte) ([]byte, error) {
+	s.hashes = append(s.hashes, hash(frame))
+	if len(s.hashes) < s.maxHashes {
+		return nil, nil
+	}
+	return s.Flush()
+}
+
+// Flush returns the signature block message for the hashes recorded since the last block, or nil if there are none.
+// It should be called before the stream is closed and can be called periodically to limit the delay until messages
+// can be verified.
+func (s *Signer) Flush() ([]byte, error) {
+	if len(s.hashes) == 0 {
+		return nil, nil
+	}
+
+	block := SignatureBlock{
+		Version:            s.version,
+		RebootSessionID:    s.rsid,
+		SignaturePriority:  s.spri,
+		GlobalBlockCounter: s.gbc,
+		FirstMessageNumber: s.next,
+		Hashes:             s.hashes,
+	}
+	p := block.params()
+	signature, err := sign(s.key, []byte(p.signed()))
+	if err != nil {
+		return nil, err
+	}
+
+	s.gbc++
+	s.next += uint64(len(s.hashes))
+	s.hashes = nil
+	return s.message(p.element(signature))
+}
+
+// CertificateBlocks returns the certificate block messages that distribute the public key. They should be sent at
+// the start of the stream, and may be repeated periodically for receivers that join later.
+func (s *Signer) CertificateBlocks() ([][]byte, error) {
+	blob, err := x509.MarshalPKIXPublicKey(s.key.Public())
+	if err != nil {
+		return nil, err
+	}
+	payload := Payload{Timestamp: s.now(), KeyBlobType: KeyBlobPKIX, KeyBlob: blob}.encode()
+
+	var frames [][]byte
+	for start := 0; start < len(payload); start += s.fragmentSize {
+		block := CertificateBlock{
+			Version:            s.version,
+			RebootSessionID:    s.rsid,
+			SignaturePriority:  s.spri,
+			TotalPayloadLength: len(payload),
+			Index:              start + 1,
+			Fragment:           payload[start:min(start+s.fragmentSize, len(payload))],
+		}
+		p := block.params()
+		signature, err := sign(s.key, []byte(p.signed()))
+		if err != nil {
+			return nil, err
+		}
+		frame, err := s.message(p.element(signature))
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// message formats a block message, using the SPRI as its PRI.
+func (s *Signer) message(element string) ([]byte, error) {
+	pri, err := common.NewPRI(s.spri)
+	if err != nil {
+		return nil, err
+	}
+	m := rfc5424.Message{
+		PRI:            pri,
+		Version:        1,
+		Timestamp:      s.now(),
+		Hostname:       s.hostname,
+		AppName:        s.appName,
+		StructuredData: element,
+	}
+	return m.Append(nil), nil
+}
@@ -0,0 +1,306 @@
+package rfc5848
+
+import (
+	"bytes"
+	"crypto"
+	"sort"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// session identifies the messages that are numbered together.
+type session struct {
+	rsid uint64
+	sg   byte
+	spri byte
+}
+
+func (n MessageNumber) session() session {
+	return session{rsid: n.RebootSessionID, sg: n.SignatureGroup, spri: n.SignaturePriority}
+}
+
+// received is a message frame that was added to the Verifier.
+type received struct {
+	frame   []byte
+	matched bool
+}
+
+// payloadAssembly collects the fragments of a payload block. The buffers grow as fragments arrive, up to the total
+// length announced by the blocks.
+type payloadAssembly struct {
+	length int
+	data   []byte
+	filled []bool
+	blocks []certificateFragment
+}
+
+type certificateFragment struct {
+	signed    []byte
+	signature []byte
+}
+
+// Verifier checks the signature blocks of a received stream against the messages in it.
+type Verifier struct {
+	trusted  crypto.PublicKey
+	parser   rfc5424.Parser
+	keys     map[session]crypto.PublicKey
+	payloads map[session]*payloadAssembly
+	blocks   map[session]map[uint64]bool
+
+	received  []received
+	unmatched map[string][]int
+	numbers   map[MessageNumber]int
+	missing   []MessageNumber
+	invalid   int
+}
+
+// NewVerifier creates a Verifier. When trusted is nil, the keys distributed in the certificate blocks of the stream
+// are used, which only shows that the messages were not changed after they were signed by whoever holds that key.
+// When trusted is set, it is used for all blocks and the key of the certificate blocks must match it.
+func NewVerifier(trusted crypto.PublicKey) *Verifier {
+	return &Verifier{
+		trusted:   trusted,
+		parser:    rfc5424.NewParser(rfc5424.WithParseStructuredDataElements()),
+		keys:      map[session]crypto.PublicKey{},
+		payloads:  map[session]*payloadAssembly{},
+		blocks:    map[session]map[uint64]bool{},
+		unmatched: map[string][]int{},
+		numbers:   map[MessageNumber]int{},
+	}
+}
+
+// Add processes the next received frame. Signature and certificate blocks are verified, other messages are stored
+// to be matched against the hashes of later signature blocks. An error is returned for blocks that can not be
+// verified, which are also counted in the report.
+func (v *Verifier) Add(frame []byte) error {
+	frame = bytes.Clone(frame)
+	if m, err := v.parser.Parse(bytes.NewReader(frame)); err == nil && m.StructuredDataElements != nil {
+		for _, element := range *m.StructuredDataElements {
+			var err error
+			switch element.ID {
+			case SignatureBlockID:
+				err = v.addSignatureBlock(element)
+			case CertificateBlockID:
+				err = v.addCertificateBlock(element)
+			default:
+				continue
+			}
+			if err != nil {
+				v.invalid++
+			}
+			return err
+		}
+	}
+
+	v.unmatched[string(hash(frame))] = append(v.unmatched[string(hash(frame))], len(v.received))
+	v.received = append(v.received, received{frame: frame})
+	return nil
+}
+
+func (v *Verifier) addSignatureBlock(element rfc5424.StructuredDataElement) error {
+	block, err := ParseSignatureBlock(element)
+	if err != nil {
+		return err
+	}
+	s := session{rsid: block.RebootSessionID, sg: block.SignatureGroup, spri: block.SignaturePriority}
+	key := v.key(s)
+	if key == nil {
+		return ErrNoKey
+	}
+	if err := checkVersion(block.Version, key); err != nil {
+		return err
+	}
+	if err := verify(key, []byte(block.params().signed()), block.Signature); err != nil {
+		return err
+	}
+
+	// Signature blocks may be sent more than once for redundancy.
+	if v.blocks[s] == nil {
+		v.blocks[s] = map[uint64]bool{}
+	}
+	if v.blocks[s][block.GlobalBlockCounter] {
+		return nil
+	}
+	v.blocks[s][block.GlobalBlockCounter] = true
+
+	for i, h := range block.Hashes {
+		number := MessageNumber{
+			RebootSessionID:   s.rsid,
+			SignatureGroup:    s.sg,
+			SignaturePriority: s.spri,
+			Number:            block.FirstMessageNumber + uint64(i),
+		}
+		indexes := v.unmatched[string(h)]
+		if len(indexes) == 0 {
+			v.missing = append(v.missing, number)
+			continue
+		}
+		v.unmatched[string(h)] = indexes[1:]
+		v.received[indexes[0]].matched = true
+		v.numbers[number] = indexes[0]
+	}
+	return nil
+}
+
+func (v *Verifier) addCertificateBlock(element rfc5424.StructuredDataElement) error {
+	block, err := ParseCertificateBlock(element)
+	if err != nil {
+		return err
+	}
+	s := session{rsid: block.RebootSessionID, sg: block.SignatureGroup, spri: block.SignaturePriority}
+	if v.keys[s] != nil {
+		// The key of this session is already known, so this is a repetition.
+		return v.verifyCertificateBlock(v.keys[s], block)
+	}
+
+	assembly := v.payloads[s]
+	if assembly == nil || assembly.length != block.TotalPayloadLength {
+		assembly = &payloadAssembly{length: block.TotalPayloadLength}
+		v.payloads[s] = assembly
+	}
+	if end := block.Index - 1 + len(block.Fragment); end > len(assembly.data) {
+		assembly.data = append(assembly.data, make([]byte, end-len(assembly.data))...)
+		assembly.filled = append(assembly.filled, make([]bool, end-len(assembly.filled))...)
+	}
+	copy(assembly.data[block.Index-1:], block.Fragment)
+	for i := range block.Fragment {
+		assembly.filled[block.Index-1+i] = true
+	}
+	assembly.blocks = append(assembly.blocks, certificateFragment{
+		signed:    []byte(block.params().signed()),
+		signature: block.Signature,
+	})
+	if len(assembly.data) != assembly.length {
+		return nil
+	}
+	for _, filled := range assembly.filled {
+		if !filled {
+			return nil
+		}
+	}
+	delete(v.payloads, s)
+	return v.completePayload(s, block.Version, assembly)
+}
+
+// completePayload extracts the key from a reassembled payload block and verifies the certificate blocks that carried
+// it.
+func (v *Verifier) completePayload(s session, version string, assembly *payloadAssembly) error {
+	payload, err := ParsePayload(assembly.data)
+	if err != nil {
+		return err
+	}
+	key, err := payload.PublicKey()
+	if err != nil {
+		return ErrInvalidPayload
+	}
+	if v.trusted != nil {
+		if equal, ok := v.trusted.(interface{ Equal(crypto.PublicKey) bool }); !ok || !equal.Equal(key) {
+			return ErrKeyMismatch
+		}
+	}
+	if err := checkVersion(version, key); err != nil {
+		return err
+	}
+	for _, fragment := range assembly.blocks {
+		if err := verify(key, fragment.signed, fragment.signature); err != nil {
+			return err
+		}
+	}
+	v.keys[s] = key
+	return nil
+}
+
+func (v *Verifier) verifyCertificateBlock(key crypto.PublicKey, block CertificateBlock) error {
+	if err := checkVersion(block.Version, key); err != nil {
+		return err
+	}
+	return verify(key, []byte(block.params().signed()), block.Signature)
+}
+
+// key returns the key to verify the blocks of the session with.
+func (v *Verifier) key(s session) crypto.PublicKey {
+	if v.trusted != nil {
+		return v.trusted
+	}
+	return v.keys[s]
+}
+
+// Report returns the outcome of the verification of the frames added so far. A signed message that was not received
+// is reported as modified when an unsigned message was received between its verified neighbours.
+func (v *Verifier) Report() Report {
+	r := Report{InvalidBlocks: v.invalid}
+
+	verified := make([]MessageNumber, 0, len(v.numbers))
+	for number := range v.numbers {
+		verified = append(verified, number)
+	}
+	sortNumbers(verified)
+
+	// A message is reordered when it was received before a lower numbered message of the same session.
+	latest := map[session]int{}
+	for _, number := range verified {
+		r.Verified = append(r.Verified, number)
+		index := v.numbers[number]
+		if last, ok := latest[number.session()]; ok && index < last {
+			r.Reordered = append(r.Reordered, number)
+			continue
+		}
+		latest[number.session()] = index
+	}
+
+	assigned := map[int]bool{}
+	missing := append([]MessageNumber(nil), v.missing...)
+	sortNumbers(missing)
+	for _, number := range missing {
+		if index, ok := v.replacement(number, verified, assigned); ok {
+			assigned[index] = true
+			r.Modified = append(r.Modified, Modified{Number: number, Frame: v.received[index].frame})
+			continue
+		}
+		r.Missing = append(r.Missing, number)
+	}
+
+	for i, m := range v.received {
+		if !m.matched && !assigned[i] {
+			r.Unsigned = append(r.Unsigned, m.frame)
+		}
+	}
+	return r
+}
+
+// replacement looks for an unmatched message that was received between the verified neighbours of a missing message.
+func (v *Verifier) replacement(number MessageNumber, verified []MessageNumber, assigned map[int]bool) (int, bool) {
+	low, high := -1, len(v.received)
+	for _, n := range verified {
+		if n.session() != number.session() {
+			continue
+		}
+		if n.Number < number.Number {
+			low = max(low, v.numbers[n])
+		} else if n.Number > number.Number {
+			high = min(high, v.numbers[n])
+		}
+	}
+	for i := low + 1; i < high; i++ {
+		if !v.received[i].matched && !assigned[i] {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func sortNumbers(numbers []MessageNumber) {
+	sort.Slice(numbers, func(i, j int) bool {
+		a, b := numbers[i], numbers[j]
+		if a.session() != b.session() {
+			if a.RebootSessionID != b.RebootSessionID {
+				return a.RebootSessionID < b.RebootSessionID
+			}
+			if a.SignatureGroup != b.SignatureGroup {
+				return a.SignatureGroup < b.SignatureGroup
+			}
+			return a.SignaturePriority < b.SignaturePriority
+		}
+		return a.Number < b.Number
+	})
+}
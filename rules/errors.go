@@ -0,0 +1,11 @@
+package rules
+
+import "errors"
+
+var (
+	ErrUnexpectedToken    = errors.New("unexpected token in expression")
+	ErrUnterminatedString = errors.New("unterminated string in expression")
+	ErrUnknownField       = errors.New("unknown field in expression")
+	ErrInvalidOperator    = errors.New("operator is not supported for field")
+	ErrInvalidValue       = errors.New("invalid value for field")
+)
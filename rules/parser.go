@@ -0,0 +1,297 @@
+package rules
+
+import (
+	"strconv"
+
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+type tokenKind byte
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	// text holds the token as written, except for strings which are unquoted.
+	text string
+}
+
+// symbols holds the operators and punctuation, two character symbols first.
+var symbols = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "!", "(", ")", "<", ">", "[", "]"}
+
+// lex splits an expression into tokens. The last token is always tokenEOF.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(input) && input[end] != '"' {
+				if input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, ErrUnterminatedString
+			}
+			value, err := strconv.Unquote(input[i : end+1])
+			if err != nil {
+				return nil, ErrUnterminatedString
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value})
+			i = end + 1
+		case isDigit(c):
+			end := i
+			for end < len(input) && isDigit(input[end]) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[i:end]})
+			i = end
+		case isLetter(c):
+			end := i
+			for end < len(input) && (isLetter(input[end]) || isDigit(input[end]) || input[end] == '-') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: input[i:end]})
+			i = end
+		default:
+			symbol := matchSymbol(input[i:])
+			if symbol == "" {
+				return nil, ErrUnexpectedToken
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+func matchSymbol(input string) string {
+	for _, symbol := range symbols {
+		if len(input) >= len(symbol) && input[:len(symbol)] == symbol {
+			return symbol
+		}
+	}
+	return ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+// parser is a recursive descent parser for the grammar:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | comparison
+//	comparison = field operator value
+//	field      = ident | "sd" "[" string "]" "[" string "]"
+type parser struct {
+	tokens []token
+	pos    int
+	// parameters is set when the expression compares a structured data parameter.
+	parameters bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the symbol.
+func (p *parser) accept(symbol string) bool {
+	if t := p.peek(); t.kind == tokenSymbol && t.text == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, symbol string) (token, error) {
+	t := p.next()
+	if t.kind != kind || kind == tokenSymbol && t.text != symbol {
+		return token{}, ErrUnexpectedToken
+	}
+	return t, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = or{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = and{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not{operand: operand}, nil
+	}
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenSymbol, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	field, err := p.expect(tokenIdent, "")
+	if err != nil {
+		return nil, err
+	}
+
+	switch field.text {
+	case "facility", "severity":
+		op, err := p.parseOperator()
+		if err != nil {
+			return nil, err
+		}
+		if op == opGlob || op == opNotGlob {
+			return nil, ErrInvalidOperator
+		}
+		value, err := p.parseLevel(field.text == "facility")
+		if err != nil {
+			return nil, err
+		}
+		return levelComparison{facility: field.text == "facility", op: op, value: value}, nil
+	case "sd":
+		id, name, err := p.parseParameter()
+		if err != nil {
+			return nil, err
+		}
+		p.parameters = true
+		return p.parseStringComparison(func(m *rfc5424.Message) string { return m.Parameter(id, name) })
+	}
+
+	get, ok := stringFields[field.text]
+	if !ok {
+		return nil, ErrUnknownField
+	}
+	return p.parseStringComparison(get)
+}
+
+// parseParameter parses the `["SD-ID"]["PARAM-NAME"]` following sd.
+func (p *parser) parseParameter() (string, string, error) {
+	var parts [2]string
+	for i := range parts {
+		if _, err := p.expect(tokenSymbol, "["); err != nil {
+			return "", "", err
+		}
+		t, err := p.expect(tokenString, "")
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := p.expect(tokenSymbol, "]"); err != nil {
+			return "", "", err
+		}
+		parts[i] = t.text
+	}
+	return parts[0], parts[1], nil
+}
+
+func (p *parser) parseStringComparison(get func(*rfc5424.Message) string) (node, error) {
+	op, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case opEqual, opNotEqual, opGlob, opNotGlob:
+	default:
+		return nil, ErrInvalidOperator
+	}
+	value := p.next()
+	if value.kind != tokenString {
+		return nil, ErrInvalidValue
+	}
+	return stringComparison{get: get, op: op, value: value.text}, nil
+}
+
+func (p *parser) parseOperator() (operator, error) {
+	t := p.next()
+	if t.kind == tokenSymbol {
+		if op, ok := operators[t.text]; ok {
+			return op, nil
+		}
+	}
+	return 0, ErrUnexpectedToken
+}
+
+// parseLevel parses a facility or severity given as a number or keyword, e.g. 4 or warning.
+func (p *parser) parseLevel(facility bool) (byte, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		limit := uint64(7)
+		if facility {
+			limit = 23
+		}
+		value, err := strconv.ParseUint(t.text, 10, 8)
+		if err != nil || value > limit {
+			return 0, ErrInvalidValue
+		}
+		return byte(value), nil
+	case tokenIdent:
+		parse := common.ParseSeverityName
+		if facility {
+			parse = common.ParseFacilityName
+		}
+		if value, ok := parse(t.text); ok {
+			return value, nil
+		}
+	}
+	return 0, ErrInvalidValue
+}
@@ -0,0 +1,72 @@
+package rules
+
+import (
+	"github.com/ysmilda/syslog/convert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Mode determines which routes a Router selects.
+type Mode byte
+
+const (
+	// FanOut selects the targets of all matching rules.
+	FanOut Mode = iota
+	// FirstMatch selects the target of the first matching rule only.
+	FirstMatch
+)
+
+// Router selects targets, e.g. sinks, for messages using rules. Rules are evaluated in the order in which they were
+// added. A Router is safe for concurrent use once all routes are added.
+type Router[T any] struct {
+	mode   Mode
+	routes []route[T]
+	// parameters is set when a rule compares a structured data parameter.
+	parameters bool
+}
+
+type route[T any] struct {
+	rule   *Rule
+	target T
+}
+
+// NewRouter creates a Router with the given mode.
+func NewRouter[T any](mode Mode) *Router[T] {
+	return &Router[T]{mode: mode}
+}
+
+// Add adds a route that selects the target for messages that match the rule.
+func (r *Router[T]) Add(rule *Rule, target T) {
+	r.routes = append(r.routes, route[T]{rule: rule, target: target})
+	r.parameters = r.parameters || rule.parameters
+}
+
+// RouteRFC5424 returns the targets for the message, or nil if no rule matches.
+func (r *Router[T]) RouteRFC5424(m rfc5424.Message) []T {
+	return r.route(m)
+}
+
+// RouteRFC3164 returns the targets for the message, or nil if no rule matches.
+func (r *Router[T]) RouteRFC3164(m rfc3164.Message) []T {
+	return r.route(convert.ToRFC5424(m))
+}
+
+// route evaluates the rules against a single message. If a rule uses a structured data parameter, the structured
+// data is parsed up front, so it is parsed once rather than once per comparison.
+func (r *Router[T]) route(m rfc5424.Message) []T {
+	if r.parameters && m.StructuredDataElements == nil {
+		elements, _ := m.Elements()
+		m.StructuredDataElements = &elements
+	}
+	var targets []T
+	for _, route := range r.routes {
+		if !route.rule.match(&m) {
+			continue
+		}
+		targets = append(targets, route.target)
+		if r.mode == FirstMatch {
+			break
+		}
+	}
+	return targets
+}
@@ -0,0 +1,217 @@
+// Package rules selects syslog messages using expressions such as
+//
+//	severity <= warning && app == "sshd"
+//
+// The fields facility and severity are compared by value and accept a number or keyword, e.g. 4 or warning. Note that
+// lower severities are more severe, so `severity <= warning` matches warnings and everything worse. The string fields
+// hostname, app, procid, msgid and msg, and structured data parameters written as sd["SD-ID"]["PARAM-NAME"], support
+// == and != and the glob operators =~ and !~, where * matches any sequence of characters and ? a single character.
+// A parameter that is not present compares as the empty string. Comparisons are combined using &&, || and ! and can
+// be grouped with parentheses.
+//
+// RFC3164 messages are matched after converting them to RFC5424, so the tag is split into app and procid.
+package rules
+
+import (
+	"unicode/utf8"
+
+	"github.com/ysmilda/syslog/convert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Rule is a compiled expression. It is safe for concurrent use.
+type Rule struct {
+	expression string
+	root       node
+	parameters bool
+}
+
+// Compile parses an expression into a Rule.
+func Compile(expression string) (*Rule, error) {
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, ErrUnexpectedToken
+	}
+	return &Rule{expression: expression, root: root, parameters: p.parameters}, nil
+}
+
+// MustCompile is like Compile but panics if the expression is invalid.
+func MustCompile(expression string) *Rule {
+	rule, err := Compile(expression)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// String returns the expression the rule was compiled from.
+func (r *Rule) String() string {
+	return r.expression
+}
+
+// MatchRFC5424 reports whether the message matches the rule.
+func (r *Rule) MatchRFC5424(m rfc5424.Message) bool {
+	return r.match(&m)
+}
+
+// MatchRFC3164 reports whether the message matches the rule.
+func (r *Rule) MatchRFC3164(m rfc3164.Message) bool {
+	return r.MatchRFC5424(convert.ToRFC5424(m))
+}
+
+func (r *Rule) match(m *rfc5424.Message) bool {
+	return r.root.eval(m)
+}
+
+var stringFields = map[string]func(*rfc5424.Message) string{
+	"hostname": func(m *rfc5424.Message) string { return m.Hostname },
+	"app":      func(m *rfc5424.Message) string { return m.AppName },
+	"procid":   func(m *rfc5424.Message) string { return m.ProcID },
+	"msgid":    func(m *rfc5424.Message) string { return m.MsgID },
+	"msg":      func(m *rfc5424.Message) string { return m.Message },
+}
+
+type operator byte
+
+const (
+	opEqual operator = iota
+	opNotEqual
+	opLess
+	opLessEqual
+	opGreater
+	opGreaterEqual
+	opGlob
+	opNotGlob
+)
+
+var operators = map[string]operator{
+	"==": opEqual,
+	"!=": opNotEqual,
+	"<":  opLess,
+	"<=": opLessEqual,
+	">":  opGreater,
+	">=": opGreaterEqual,
+	"=~": opGlob,
+	"!~": opNotGlob,
+}
+
+type node interface {
+	eval(m *rfc5424.Message) bool
+}
+
+type and struct {
+	left, right node
+}
+
+func (n and) eval(m *rfc5424.Message) bool {
+	return n.left.eval(m) && n.right.eval(m)
+}
+
+type or struct {
+	left, right node
+}
+
+func (n or) eval(m *rfc5424.Message) bool {
+	return n.left.eval(m) || n.right.eval(m)
+}
+
+type not struct {
+	operand node
+}
+
+func (n not) eval(m *rfc5424.Message) bool {
+	return !n.operand.eval(m)
+}
+
+// levelComparison compares the facility or severity of a message.
+type levelComparison struct {
+	facility bool
+	op       operator
+	value    byte
+}
+
+func (n levelComparison) eval(m *rfc5424.Message) bool {
+	level := m.PRI.Severity()
+	if n.facility {
+		level = m.PRI.Facility()
+	}
+	switch n.op {
+	case opEqual:
+		return level == n.value
+	case opNotEqual:
+		return level != n.value
+	case opLess:
+		return level < n.value
+	case opLessEqual:
+		return level <= n.value
+	case opGreater:
+		return level > n.value
+	default:
+		return level >= n.value
+	}
+}
+
+// stringComparison compares a string field of a message.
+type stringComparison struct {
+	get   func(*rfc5424.Message) string
+	op    operator
+	value string
+}
+
+func (n stringComparison) eval(m *rfc5424.Message) bool {
+	s := n.get(m)
+	switch n.op {
+	case opEqual:
+		return s == n.value
+	case opNotEqual:
+		return s != n.value
+	case opGlob:
+		return glob(n.value, s)
+	default:
+		return !glob(n.value, s)
+	}
+}
+
+// glob reports whether s matches the pattern, in which * matches any sequence of characters and ? a single
+// character.
+func glob(pattern, s string) bool {
+	px, sx := 0, 0
+	// The position to continue at when the characters after a * do not match.
+	starPx, starSx := -1, 0
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			switch c := pattern[px]; {
+			case c == '*':
+				starPx, starSx = px, sx
+				px++
+				continue
+			case c == '?' && sx < len(s):
+				_, size := utf8.DecodeRuneInString(s[sx:])
+				px++
+				sx += size
+				continue
+			case c != '?' && sx < len(s) && s[sx] == c:
+				px++
+				sx++
+				continue
+			}
+		}
+		if starPx < 0 || starSx >= len(s) {
+			return false
+		}
+		// Let the last * consume one more character and try again.
+		_, size := utf8.DecodeRuneInString(s[starSx:])
+		starSx += size
+		px, sx = starPx+1, starSx
+	}
+	return true
+}
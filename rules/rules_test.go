@@ -0,0 +1,182 @@
+//nolint:lll
+package rules
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestMatchRFC5424(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<36>1 2003-10-11T22:14:15.003Z web-01.example.com sshd 42 AUTH [origin ip=\"192.0.2.1\"][exampleSDID@32473 iut=\"3\" eventSource=\"Application\"] Failed password for root")
+	m, err := rfc5424.NewParser().Parse(bytes.NewReader(input))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{`severity == warning`, true},
+		{`severity == 4`, true},
+		{`severity <= warning`, true},
+		{`severity < warning`, false},
+		{`severity > err && severity >= warning`, true},
+		{`severity != warning`, false},
+		{`facility == auth`, true},
+		{`facility == local4`, false},
+		{`facility == solaris-cron`, false},
+		{`facility <= 4`, true},
+		{`hostname == "web-01.example.com"`, true},
+		{`hostname =~ "web-*.example.com"`, true},
+		{`hostname =~ "web-??.example.com"`, true},
+		{`hostname =~ "web-?.example.com"`, false},
+		{`hostname !~ "db-*"`, true},
+		{`app == "sshd"`, true},
+		{`app != "sshd"`, false},
+		{`procid == "42"`, true},
+		{`msgid == "AUTH"`, true},
+		{`msg =~ "*root"`, true},
+		{`msg =~ "Failed*"`, true},
+		{`sd["origin"]["ip"] == "192.0.2.1"`, true},
+		{`sd["exampleSDID@32473"]["eventSource"] =~ "App*"`, true},
+		{`sd["exampleSDID@32473"]["missing"] == ""`, true},
+		{`sd["missing"]["ip"] == "192.0.2.1"`, false},
+		{`severity <= warning && app == "sshd"`, true},
+		{`severity <= err && app == "sshd"`, false},
+		{`severity <= err || app == "sshd"`, true},
+		{`!(app == "sshd")`, false},
+		{`!app == "sshd"`, false},
+		{`app == "cron" || app == "sudo" || app == "sshd"`, true},
+		{`app == "cron" || app == "sshd" && severity == emerg`, false},
+		{`(app == "cron" || app == "sshd") && severity == warning`, true},
+		{"app == \"ss\\x68d\"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			t.Parallel()
+
+			rule, err := Compile(tt.expression)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expression, rule.String())
+			assert.Equal(t, tt.expected, rule.MatchRFC5424(m))
+		})
+	}
+}
+
+func TestMatchRFC3164(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8")
+	m, err := rfc3164.NewParser().Parse(bytes.NewReader(input))
+	assert.Nil(t, err)
+
+	assert.True(t, MustCompile(`facility == auth && severity == crit`).MatchRFC3164(m))
+	assert.True(t, MustCompile(`hostname == "mymachine" && app == "su" && procid == "123"`).MatchRFC3164(m))
+	assert.True(t, MustCompile(`msg =~ "'su root' failed*"`).MatchRFC3164(m))
+	assert.False(t, MustCompile(`msgid != ""`).MatchRFC3164(m))
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expression string
+		err        error
+	}{
+		{``, ErrUnexpectedToken},
+		{`severity`, ErrUnexpectedToken},
+		{`severity <= `, ErrInvalidValue},
+		{`severity <= fatal`, ErrInvalidValue},
+		{`severity <= 8`, ErrInvalidValue},
+		{`facility == 24`, ErrInvalidValue},
+		{`facility =~ "local*"`, ErrInvalidOperator},
+		{`app < "sshd"`, ErrInvalidOperator},
+		{`app == sshd`, ErrInvalidValue},
+		{`application == "sshd"`, ErrUnknownField},
+		{`app == "sshd`, ErrUnterminatedString},
+		{`app == "sshd" &&`, ErrUnexpectedToken},
+		{`app == "sshd" & severity == err`, ErrUnexpectedToken},
+		{`(app == "sshd"`, ErrUnexpectedToken},
+		{`app == "sshd")`, ErrUnexpectedToken},
+		{`app == "sshd" severity == err`, ErrUnexpectedToken},
+		{`sd["origin"] == "192.0.2.1"`, ErrUnexpectedToken},
+		{`sd[origin]["ip"] == "192.0.2.1"`, ErrUnexpectedToken},
+		{`app = "sshd"`, ErrUnexpectedToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Compile(tt.expression)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+
+	assert.Panics(t, func() { MustCompile(`app ==`) })
+}
+
+func TestGlob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern, s string
+		expected   bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abbbd", false},
+		{"a*b*c", "aXbYbZc", true},
+		{"*.example.com", "web.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"?", "é", true},
+		{"??", "é", false},
+		{"*é", "caféé", true},
+		{"a?c", "ac", false},
+		{"**", "ab", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, glob(tt.pattern, tt.s), "%q %q", tt.pattern, tt.s)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<36>1 2003-10-11T22:14:15.003Z web-01 sshd - - - Failed password for root")
+	m, err := rfc5424.NewParser().Parse(bytes.NewReader(input))
+	assert.Nil(t, err)
+
+	add := func(r *Router[string]) {
+		r.Add(MustCompile(`app == "cron"`), "cron")
+		r.Add(MustCompile(`app == "sshd"`), "auth")
+		r.Add(MustCompile(`severity <= warning`), "alerts")
+		r.Add(MustCompile(`facility == auth`), "security")
+	}
+
+	fanOut := NewRouter[string](FanOut)
+	add(fanOut)
+	assert.Equal(t, []string{"auth", "alerts", "security"}, fanOut.RouteRFC5424(m))
+
+	firstMatch := NewRouter[string](FirstMatch)
+	add(firstMatch)
+	assert.Equal(t, []string{"auth"}, firstMatch.RouteRFC5424(m))
+
+	legacy, err := rfc3164.NewParser().Parse(bytes.NewReader([]byte("<78>Oct 11 22:14:15 host cron: job started")))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cron"}, fanOut.RouteRFC3164(legacy))
+
+	m.AppName = "kernel"
+	m.PRI, _ = rfc5424.NewPRI(6)
+	assert.Nil(t, fanOut.RouteRFC5424(m))
+}
@@ -0,0 +1,315 @@
+package snmp
+
+// BER tags of the types used in SNMP messages.
+const (
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagNull        = 0x05
+	tagOID         = 0x06
+	tagSequence    = 0x30
+	tagIPAddress   = 0x40
+	tagCounter32   = 0x41
+	tagGauge32     = 0x42
+	tagTimeTicks   = 0x43
+	tagOpaque      = 0x44
+	tagCounter64   = 0x46
+	tagTrapPDU     = 0xa7
+)
+
+// appendTLV appends a tag, the definite length of the content and the content.
+func appendTLV(dst []byte, tag byte, content []byte) []byte {
+	dst = append(dst, tag)
+	n := len(content)
+	switch {
+	case n < 0x80:
+		dst = append(dst, byte(n))
+	case n <= 0xff:
+		dst = append(dst, 0x81, byte(n))
+	case n <= 0xffff:
+		dst = append(dst, 0x82, byte(n>>8), byte(n))
+	default:
+		dst = append(dst, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(dst, content...)
+}
+
+// appendInteger appends a signed integer in the minimal number of two's complement octets.
+func appendInteger(dst []byte, tag byte, v int64) []byte {
+	n := 8
+	for n > 1 {
+		top := v >> (8*(n-1) - 1)
+		if top != 0 && top != -1 {
+			break
+		}
+		n--
+	}
+	content := make([]byte, n)
+	for i := range content {
+		content[i] = byte(v >> (8 * (n - 1 - i)))
+	}
+	return appendTLV(dst, tag, content)
+}
+
+// appendUnsigned appends an unsigned integer, with a leading zero octet when the high bit is set.
+func appendUnsigned(dst []byte, tag byte, v uint64) []byte {
+	var content []byte
+	for shift := 56; shift >= 0; shift -= 8 {
+		b := byte(v >> shift)
+		if len(content) == 0 && b == 0 && shift > 0 {
+			continue
+		}
+		if len(content) == 0 && b&0x80 != 0 {
+			content = append(content, 0)
+		}
+		content = append(content, b)
+	}
+	return appendTLV(dst, tag, content)
+}
+
+func appendOID(dst []byte, oid OID) ([]byte, error) {
+	if len(oid) < 2 || oid[0] > 2 || (oid[0] < 2 && oid[1] >= 40) {
+		return nil, ErrInvalidOID
+	}
+	content := appendBase128(nil, uint64(oid[0])*40+uint64(oid[1]))
+	for _, arc := range oid[2:] {
+		content = appendBase128(content, uint64(arc))
+	}
+	return appendTLV(dst, tagOID, content), nil
+}
+
+func appendBase128(dst []byte, v uint64) []byte {
+	n := 1
+	for rest := v >> 7; rest > 0; rest >>= 7 {
+		n++
+	}
+	for i := n - 1; i >= 0; i-- {
+		b := byte(v>>(7*i)) & 0x7f
+		if i > 0 {
+			b |= 0x80
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
+
+func appendValue(dst []byte, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case Integer:
+		return appendInteger(dst, tagInteger, int64(v)), nil
+	case OctetString:
+		return appendTLV(dst, tagOctetString, v), nil
+	case Null:
+		return appendTLV(dst, tagNull, nil), nil
+	case OID:
+		return appendOID(dst, v)
+	case IPAddress:
+		return appendTLV(dst, tagIPAddress, v[:]), nil
+	case Counter32:
+		return appendUnsigned(dst, tagCounter32, uint64(v)), nil
+	case Gauge32:
+		return appendUnsigned(dst, tagGauge32, uint64(v)), nil
+	case TimeTicks:
+		return appendUnsigned(dst, tagTimeTicks, uint64(v)), nil
+	case Opaque:
+		return appendTLV(dst, tagOpaque, v), nil
+	case Counter64:
+		return appendUnsigned(dst, tagCounter64, uint64(v)), nil
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func appendVarBinds(dst []byte, varBinds []VarBind) ([]byte, error) {
+	var list []byte
+	for _, varBind := range varBinds {
+		content, err := appendOID(nil, varBind.OID)
+		if err != nil {
+			return nil, err
+		}
+		content, err = appendValue(content, varBind.Value)
+		if err != nil {
+			return nil, err
+		}
+		list = appendTLV(list, tagSequence, content)
+	}
+	return appendTLV(dst, tagSequence, list), nil
+}
+
+// decoder reads BER encoded values from data.
+type decoder struct {
+	data []byte
+}
+
+// next reads the next TLV and returns its tag and content.
+func (d *decoder) next() (byte, []byte, error) {
+	if len(d.data) < 2 {
+		return 0, nil, ErrInvalidBER
+	}
+	tag, length := d.data[0], int(d.data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		octets := length & 0x7f
+		if octets == 0 || octets > 4 || len(d.data) < offset+octets {
+			return 0, nil, ErrInvalidBER
+		}
+		length = 0
+		for _, b := range d.data[offset : offset+octets] {
+			length = length<<8 | int(b)
+		}
+		offset += octets
+	}
+	if length < 0 || len(d.data)-offset < length {
+		return 0, nil, ErrInvalidBER
+	}
+	content := d.data[offset : offset+length]
+	d.data = d.data[offset+length:]
+	return tag, content, nil
+}
+
+// expect reads the next TLV and checks its tag.
+func (d *decoder) expect(tag byte) ([]byte, error) {
+	actual, content, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	if actual != tag {
+		return nil, ErrInvalidBER
+	}
+	return content, nil
+}
+
+func decodeInteger(content []byte) (int64, error) {
+	if len(content) == 0 || len(content) > 8 {
+		return 0, ErrInvalidBER
+	}
+	v := int64(int8(content[0]))
+	for _, b := range content[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// decodeUnsigned decodes an unsigned integer of at most bits. Values without the leading zero octet that BER
+// requires when the high bit is set are accepted, as some agents omit it.
+func decodeUnsigned(content []byte, bits int) (uint64, error) {
+	if len(content) > 1 && content[0] == 0 {
+		content = content[1:]
+	}
+	if len(content) == 0 || len(content) > bits/8 {
+		return 0, ErrInvalidBER
+	}
+	var v uint64
+	for _, b := range content {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+func decodeOID(content []byte) (OID, error) {
+	var arcs []uint64
+	var v uint64
+	for i, b := range content {
+		if v > 1<<57 {
+			return nil, ErrInvalidBER
+		}
+		v = v<<7 | uint64(b&0x7f)
+		if b&0x80 == 0 {
+			arcs = append(arcs, v)
+			v = 0
+		} else if i == len(content)-1 {
+			return nil, ErrInvalidBER
+		}
+	}
+	if len(arcs) == 0 {
+		return nil, ErrInvalidBER
+	}
+
+	oid := OID{0, 0}
+	switch first := arcs[0]; {
+	case first < 40:
+		oid[1] = uint32(first)
+	case first < 80:
+		oid[0], oid[1] = 1, uint32(first-40)
+	default:
+		oid[0], oid[1] = 2, uint32(first-80)
+	}
+	for _, arc := range arcs[1:] {
+		if arc > 1<<32-1 {
+			return nil, ErrInvalidBER
+		}
+		oid = append(oid, uint32(arc))
+	}
+	return oid, nil
+}
+
+func decodeValue(tag byte, content []byte) (any, error) {
+	switch tag {
+	case tagInteger:
+		v, err := decodeInteger(content)
+		if err != nil || v != int64(int32(v)) {
+			return nil, ErrInvalidBER
+		}
+		return Integer(v), nil
+	case tagOctetString:
+		return OctetString(append([]byte{}, content...)), nil
+	case tagNull:
+		return Null{}, nil
+	case tagOID:
+		return decodeOID(content)
+	case tagIPAddress:
+		if len(content) != 4 {
+			return nil, ErrInvalidBER
+		}
+		return IPAddress(content), nil
+	case tagOpaque:
+		return Opaque(append([]byte{}, content...)), nil
+	case tagCounter64:
+		v, err := decodeUnsigned(content, 64)
+		return Counter64(v), err
+	case tagCounter32, tagGauge32, tagTimeTicks:
+		v, err := decodeUnsigned(content, 32)
+		if err != nil {
+			return nil, err
+		}
+		switch tag {
+		case tagCounter32:
+			return Counter32(v), nil
+		case tagGauge32:
+			return Gauge32(v), nil
+		default:
+			return TimeTicks(v), nil
+		}
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func decodeVarBinds(content []byte) ([]VarBind, error) {
+	var varBinds []VarBind
+	list := decoder{data: content}
+	for len(list.data) > 0 {
+		entry, err := list.expect(tagSequence)
+		if err != nil {
+			return nil, err
+		}
+		d := decoder{data: entry}
+		oidContent, err := d.expect(tagOID)
+		if err != nil {
+			return nil, err
+		}
+		oid, err := decodeOID(oidContent)
+		if err != nil {
+			return nil, err
+		}
+		tag, valueContent, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		value, err := decodeValue(tag, valueContent)
+		if err != nil {
+			return nil, err
+		}
+		varBinds = append(varBinds, VarBind{OID: oid, Value: value})
+	}
+	return varBinds, nil
+}
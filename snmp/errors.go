@@ -0,0 +1,10 @@
+package snmp
+
+import "errors"
+
+var (
+	ErrInvalidOID      = errors.New("invalid object identifier")
+	ErrInvalidBER      = errors.New("invalid BER encoding")
+	ErrUnsupportedType = errors.New("unsupported value type")
+	ErrNotNotification = errors.New("not an SNMPv2c notification")
+)
@@ -0,0 +1,123 @@
+package snmp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// OID is an object identifier.
+type OID []uint32
+
+// ParseOID parses an object identifier in dotted notation, e.g. "1.3.6.1.2.1.192". A leading dot is allowed.
+func ParseOID(s string) (OID, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "."), ".")
+	if len(parts) < 2 {
+		return nil, ErrInvalidOID
+	}
+	oid := make(OID, len(parts))
+	for i, part := range parts {
+		arc, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, ErrInvalidOID
+		}
+		oid[i] = uint32(arc)
+	}
+	return oid, nil
+}
+
+// MustParseOID is like ParseOID but panics on an invalid OID. It is intended for constants.
+func MustParseOID(s string) OID {
+	oid, err := ParseOID(s)
+	if err != nil {
+		panic(err)
+	}
+	return oid
+}
+
+// String returns the OID in dotted notation.
+func (o OID) String() string {
+	parts := make([]string, len(o))
+	for i, arc := range o {
+		parts[i] = strconv.FormatUint(uint64(arc), 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Append returns a new OID with the arcs appended, e.g. to add the index of a table row.
+func (o OID) Append(arcs ...uint32) OID {
+	return append(append(OID{}, o...), arcs...)
+}
+
+// Equal reports whether both OIDs have the same arcs.
+func (o OID) Equal(other OID) bool {
+	if len(o) != len(other) {
+		return false
+	}
+	for i := range o {
+		if o[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// VarBind binds a value to an object instance. The value is one of Integer, OctetString, Null, OID, IPAddress,
+// Counter32, Gauge32, TimeTicks, Opaque or Counter64.
+type VarBind struct {
+	OID   OID
+	Value any
+}
+
+// The SNMP value types. Unsigned32 values are encoded as Gauge32.
+type (
+	Integer     int32
+	OctetString []byte
+	Null        struct{}
+	IPAddress   [4]byte
+	Counter32   uint32
+	Gauge32     uint32
+	TimeTicks   uint32
+	Opaque      []byte
+	Counter64   uint64
+)
+
+// Notification is an SNMPv2c notification, as sent in an SNMPv2-Trap-PDU.
+type Notification struct {
+	Community string
+	RequestID int32
+	// Uptime and TrapOID are sent as the sysUpTime.0 and snmpTrapOID.0 varbinds that start every notification.
+	Uptime  TimeTicks
+	TrapOID OID
+	// VarBinds holds the varbinds following sysUpTime.0 and snmpTrapOID.0.
+	VarBinds []VarBind
+
+	// ContextEngineID and ContextName identify the SNMP context of the notification. They are not part of an SNMPv2c
+	// message, but are carried in the syslog message when set, e.g. by an SNMPv3 receiver.
+	ContextEngineID []byte
+	ContextName     string
+}
+
+// Entry represents a row of the syslogMsgTable and its rows in the syslogMsgSDTable, as defined in the
+// SYSLOG-MSG-MIB of RFC 5676.
+type Entry struct {
+	Index    uint32
+	Facility byte
+	Severity byte
+	Version  uint32
+	// Timestamp is a DateAndTime value of 11 octets, or 8 zero octets when the message had no timestamp.
+	Timestamp []byte
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	Msg       string
+	SDParams  []SDParam
+}
+
+// SDParam represents a row of the syslogMsgSDTable.
+type SDParam struct {
+	Index uint32
+	SDID  string
+	Name  string
+	Value string
+}
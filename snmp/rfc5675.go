@@ -0,0 +1,100 @@
+package snmp
+
+import (
+	"encoding/hex"
+	"net/netip"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// SDID is the SD-ID of the structured data element that carries a notification in a syslog message.
+const SDID = "snmp"
+
+// ToRFC5424 converts a notification to a syslog message. The MSG holds the notification OID and the notification is
+// carried in an SDID element. Its ctxEngine (hex encoded) and ctxName parameters hold the context when set, and the
+// parameters v1 to vN hold the varbinds, starting with sysUpTime.0 and snmpTrapOID.0. Each varbind is written as
+// the OID, a type letter and the value, separated by spaces:
+//
+//	i  Integer        s  OctetString of printable UTF-8   x  other OctetString or Opaque, hex encoded
+//	o  OID            a  IPAddress                        n  Null
+//	c  Counter32      u  Gauge32                          t  TimeTicks    C  Counter64
+func ToRFC5424(n Notification, pri common.PRI, hostname string, timestamp time.Time) (rfc5424.Message, error) {
+	parameters := map[string]string{}
+	if len(n.ContextEngineID) > 0 {
+		parameters["ctxEngine"] = hex.EncodeToString(n.ContextEngineID)
+	}
+	if n.ContextName != "" {
+		parameters["ctxName"] = n.ContextName
+	}
+
+	varBinds := append([]VarBind{
+		{OID: OIDSysUpTime, Value: n.Uptime},
+		{OID: OIDSnmpTrapOID, Value: n.TrapOID},
+	}, n.VarBinds...)
+	for i, varBind := range varBinds {
+		value, err := formatValue(varBind.Value)
+		if err != nil {
+			return rfc5424.Message{}, err
+		}
+		parameters["v"+strconv.Itoa(i+1)] = varBind.OID.String() + " " + value
+	}
+
+	elements := []rfc5424.StructuredDataElement{{ID: SDID, Parameters: parameters}}
+	return rfc5424.Message{
+		PRI:                    pri,
+		Version:                1,
+		Timestamp:              timestamp,
+		Hostname:               hostname,
+		StructuredData:         rfc5424.FormatStructuredData(elements),
+		StructuredDataElements: &elements,
+		Message:                n.TrapOID.String(),
+	}, nil
+}
+
+// formatValue writes the type letter and value of a varbind.
+func formatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case Integer:
+		return "i " + strconv.FormatInt(int64(v), 10), nil
+	case OctetString:
+		if printable(v) {
+			return "s " + string(v), nil
+		}
+		return "x " + hex.EncodeToString(v), nil
+	case Opaque:
+		return "x " + hex.EncodeToString(v), nil
+	case Null:
+		return "n", nil
+	case OID:
+		return "o " + v.String(), nil
+	case IPAddress:
+		return "a " + netip.AddrFrom4(v).String(), nil
+	case Counter32:
+		return "c " + strconv.FormatUint(uint64(v), 10), nil
+	case Gauge32:
+		return "u " + strconv.FormatUint(uint64(v), 10), nil
+	case TimeTicks:
+		return "t " + strconv.FormatUint(uint64(v), 10), nil
+	case Counter64:
+		return "C " + strconv.FormatUint(uint64(v), 10), nil
+	default:
+		return "", ErrUnsupportedType
+	}
+}
+
+func printable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package snmp
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Object identifiers of the SYSLOG-MSG-MIB.
+var (
+	OIDSyslogMsgMIB          = MustParseOID("1.3.6.1.2.1.192")
+	OIDSyslogMsgNotification = OIDSyslogMsgMIB.Append(0, 1)
+	OIDSyslogMsgEntry        = OIDSyslogMsgMIB.Append(1, 2, 1)
+	OIDSyslogMsgSDEntry      = OIDSyslogMsgMIB.Append(1, 3, 1)
+)
+
+// Columns of the syslogMsgTable.
+const (
+	columnFacility = 2 + iota
+	columnSeverity
+	columnVersion
+	columnTimeStamp
+	columnHostName
+	columnAppName
+	columnProcID
+	columnMsgID
+	columnSDParams
+	columnMsg
+)
+
+// Columns of the syslogMsgSDTable.
+const (
+	columnSDID = 2 + iota
+	columnSDParamName
+	columnSDParamValue
+)
+
+// FromRFC5424 converts a message to a row of the syslogMsgTable with the given index. The parameters of the
+// structured data are numbered from 1, in the order of the elements and by name within an element.
+func FromRFC5424(m rfc5424.Message, index uint32) (Entry, error) {
+	elements, err := m.Elements()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e := Entry{
+		Index:     index,
+		Facility:  m.PRI.Facility(),
+		Severity:  m.PRI.Severity(),
+		Version:   uint32(m.Version),
+		Timestamp: dateAndTime(m.Timestamp),
+		Hostname:  m.Hostname,
+		AppName:   m.AppName,
+		ProcID:    m.ProcID,
+		MsgID:     m.MsgID,
+		Msg:       m.Message,
+	}
+	for _, element := range elements {
+		names := make([]string, 0, len(element.Parameters))
+		for name := range element.Parameters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			e.SDParams = append(e.SDParams, SDParam{
+				Index: uint32(len(e.SDParams) + 1),
+				SDID:  element.ID,
+				Name:  name,
+				Value: element.Parameters[name],
+			})
+		}
+	}
+	return e, nil
+}
+
+// VarBinds returns the accessible columns of the row in the syslogMsgTable followed by its rows in the
+// syslogMsgSDTable. The index columns are not accessible and are part of the instance OIDs instead.
+func (e Entry) VarBinds() []VarBind {
+	varBinds := e.notificationVarBinds()
+	for _, param := range e.SDParams {
+		varBinds = append(varBinds,
+			VarBind{OID: OIDSyslogMsgSDEntry.Append(columnSDID, e.Index, param.Index), Value: OctetString(param.SDID)},
+			VarBind{OID: OIDSyslogMsgSDEntry.Append(columnSDParamName, e.Index, param.Index), Value: OctetString(param.Name)},
+			VarBind{OID: OIDSyslogMsgSDEntry.Append(columnSDParamValue, e.Index, param.Index), Value: OctetString(param.Value)},
+		)
+	}
+	return varBinds
+}
+
+// Notification returns the syslogMsgNotification for the row, which carries the columns of the syslogMsgTable.
+func (e Entry) Notification(community string, uptime TimeTicks) Notification {
+	return Notification{
+		Community: community,
+		Uptime:    uptime,
+		TrapOID:   OIDSyslogMsgNotification,
+		VarBinds:  e.notificationVarBinds(),
+	}
+}
+
+func (e Entry) notificationVarBinds() []VarBind {
+	column := func(column uint32) OID {
+		return OIDSyslogMsgEntry.Append(column, e.Index)
+	}
+	return []VarBind{
+		{OID: column(columnFacility), Value: Integer(e.Facility)},
+		{OID: column(columnSeverity), Value: Integer(e.Severity)},
+		{OID: column(columnVersion), Value: Gauge32(e.Version)},
+		{OID: column(columnTimeStamp), Value: OctetString(e.Timestamp)},
+		{OID: column(columnHostName), Value: OctetString(e.Hostname)},
+		{OID: column(columnAppName), Value: OctetString(e.AppName)},
+		{OID: column(columnProcID), Value: OctetString(e.ProcID)},
+		{OID: column(columnMsgID), Value: OctetString(e.MsgID)},
+		{OID: column(columnSDParams), Value: Gauge32(len(e.SDParams))},
+		{OID: column(columnMsg), Value: OctetString(e.Msg)},
+	}
+}
+
+// dateAndTime encodes a timestamp as the 11 octet DateAndTime textual convention of SNMPv2-TC. A zero timestamp is
+// encoded as 8 zero octets.
+func dateAndTime(t time.Time) []byte {
+	if t.IsZero() {
+		return make([]byte, 8)
+	}
+	_, offset := t.Zone()
+	direction := byte('+')
+	if offset < 0 {
+		direction, offset = '-', -offset
+	}
+	return []byte{
+		byte(t.Year() >> 8), byte(t.Year()),
+		byte(t.Month()), byte(t.Day()),
+		byte(t.Hour()), byte(t.Minute()), byte(t.Second()),
+		byte(t.Nanosecond() / int(100*time.Millisecond)),
+		direction, byte(offset / 3600), byte(offset % 3600 / 60),
+	}
+}
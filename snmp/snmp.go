@@ -0,0 +1,109 @@
+// Package snmp maps between syslog messages and SNMP. RFC5424 messages are converted to the rows of the
+// SYSLOG-MSG-MIB (RFC 5676) and the syslogMsgNotification, and SNMP notifications are converted to syslog messages
+// carrying the varbinds as structured data (RFC 5675). Notifications are encoded as SNMPv2c messages using BER, no
+// SNMP agent or transport is included.
+package snmp
+
+// snmpVersion2c is the version field of an SNMPv2c message.
+const snmpVersion2c = 1
+
+// Object identifiers of the varbinds that start every notification.
+var (
+	OIDSysUpTime   = MustParseOID("1.3.6.1.2.1.1.3.0")
+	OIDSnmpTrapOID = MustParseOID("1.3.6.1.6.3.1.1.4.1.0")
+)
+
+// MarshalBinary encodes the notification as an SNMPv2c message containing an SNMPv2-Trap-PDU.
+func (n Notification) MarshalBinary() ([]byte, error) {
+	varBinds := append([]VarBind{
+		{OID: OIDSysUpTime, Value: n.Uptime},
+		{OID: OIDSnmpTrapOID, Value: n.TrapOID},
+	}, n.VarBinds...)
+
+	pdu := appendInteger(nil, tagInteger, int64(n.RequestID))
+	pdu = appendInteger(pdu, tagInteger, 0) // error-status
+	pdu = appendInteger(pdu, tagInteger, 0) // error-index
+	pdu, err := appendVarBinds(pdu, varBinds)
+	if err != nil {
+		return nil, err
+	}
+
+	message := appendInteger(nil, tagInteger, snmpVersion2c)
+	message = appendTLV(message, tagOctetString, []byte(n.Community))
+	message = appendTLV(message, tagTrapPDU, pdu)
+	return appendTLV(nil, tagSequence, message), nil
+}
+
+// UnmarshalBinary decodes an SNMPv2c message containing an SNMPv2-Trap-PDU. The first two varbinds must be
+// sysUpTime.0 and snmpTrapOID.0.
+func (n *Notification) UnmarshalBinary(data []byte) error {
+	outer := decoder{data: data}
+	message, err := outer.expect(tagSequence)
+	if err != nil {
+		return err
+	}
+
+	d := decoder{data: message}
+	content, err := d.expect(tagInteger)
+	if err != nil {
+		return err
+	}
+	if version, err := decodeInteger(content); err != nil || version != snmpVersion2c {
+		return ErrNotNotification
+	}
+	community, err := d.expect(tagOctetString)
+	if err != nil {
+		return err
+	}
+	pdu, err := d.expect(tagTrapPDU)
+	if err != nil {
+		return ErrNotNotification
+	}
+
+	d = decoder{data: pdu}
+	content, err = d.expect(tagInteger)
+	if err != nil {
+		return err
+	}
+	requestID, err := decodeInteger(content)
+	if err != nil || requestID != int64(int32(requestID)) {
+		return ErrInvalidBER
+	}
+	// Skip the error-status and error-index, which are zero in notifications.
+	for i := 0; i < 2; i++ {
+		if _, err := d.expect(tagInteger); err != nil {
+			return err
+		}
+	}
+	content, err = d.expect(tagSequence)
+	if err != nil {
+		return err
+	}
+	varBinds, err := decodeVarBinds(content)
+	if err != nil {
+		return err
+	}
+
+	if len(varBinds) < 2 || !varBinds[0].OID.Equal(OIDSysUpTime) || !varBinds[1].OID.Equal(OIDSnmpTrapOID) {
+		return ErrNotNotification
+	}
+	uptime, ok := varBinds[0].Value.(TimeTicks)
+	if !ok {
+		return ErrNotNotification
+	}
+	trapOID, ok := varBinds[1].Value.(OID)
+	if !ok {
+		return ErrNotNotification
+	}
+
+	*n = Notification{
+		Community: string(community),
+		RequestID: int32(requestID),
+		Uptime:    uptime,
+		TrapOID:   trapOID,
+	}
+	if len(varBinds) > 2 {
+		n.VarBinds = varBinds[2:]
+	}
+	return nil
+}
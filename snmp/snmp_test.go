@@ -0,0 +1,235 @@
+//nolint:lll
+package snmp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/common"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestParseOID(t *testing.T) {
+	t.Parallel()
+
+	oid, err := ParseOID(".1.3.6.1.2.1.192")
+	assert.NoError(t, err)
+	assert.Equal(t, OID{1, 3, 6, 1, 2, 1, 192}, oid)
+	assert.Equal(t, "1.3.6.1.2.1.192", oid.String())
+	assert.Equal(t, OID{1, 3, 6, 1, 2, 1, 192, 0, 1}, oid.Append(0, 1))
+	assert.True(t, oid.Equal(OID{1, 3, 6, 1, 2, 1, 192}))
+	assert.False(t, oid.Equal(OID{1, 3, 6, 1, 2, 1}))
+
+	for _, input := range []string{"", "1", "1.3.a", "1..3", "1.3.4294967296"} {
+		_, err := ParseOID(input)
+		assert.ErrorIs(t, err, ErrInvalidOID, input)
+	}
+}
+
+func TestEncodeValue(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "integer zero", value: Integer(0), expected: "020100"},
+		{name: "integer 127", value: Integer(127), expected: "02017f"},
+		{name: "integer 128", value: Integer(128), expected: "02020080"},
+		{name: "integer 256", value: Integer(256), expected: "02020100"},
+		{name: "integer -1", value: Integer(-1), expected: "0201ff"},
+		{name: "integer -129", value: Integer(-129), expected: "0202ff7f"},
+		{name: "octet string", value: OctetString("abc"), expected: "0403616263"},
+		{name: "null", value: Null{}, expected: "0500"},
+		{name: "oid", value: OID{1, 3, 6, 1, 2, 1, 192}, expected: "06072b060102018140"},
+		{name: "ip address", value: IPAddress{192, 0, 2, 1}, expected: "4004c0000201"},
+		{name: "counter32 zero", value: Counter32(0), expected: "410100"},
+		{name: "gauge32 255", value: Gauge32(255), expected: "420200ff"},
+		{name: "timeticks max", value: TimeTicks(0xffffffff), expected: "430500ffffffff"},
+		{name: "opaque", value: Opaque{1}, expected: "440101"},
+		{name: "counter64", value: Counter64(1 << 40), expected: "4606010000000000"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			encoded, err := appendValue(nil, tc.value)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, hex.EncodeToString(encoded))
+
+			d := decoder{data: encoded}
+			tag, content, err := d.next()
+			assert.NoError(t, err)
+			decoded, err := decodeValue(tag, content)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.value, decoded)
+		})
+	}
+
+	_, err := appendValue(nil, "string")
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	_, err = appendValue(nil, OID{3, 1})
+	assert.ErrorIs(t, err, ErrInvalidOID)
+}
+
+func TestEncodeLongLength(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{127, 128, 255, 256, 70000} {
+		encoded := appendTLV(nil, tagOctetString, make([]byte, n))
+		d := decoder{data: encoded}
+		content, err := d.expect(tagOctetString)
+		assert.NoError(t, err)
+		assert.Len(t, content, n)
+		assert.Empty(t, d.data)
+	}
+	assert.Equal(t, "0481c8", hex.EncodeToString(appendTLV(nil, tagOctetString, make([]byte, 200))[:3]))
+}
+
+func TestNotification(t *testing.T) {
+	t.Parallel()
+
+	n := Notification{
+		Community: "public",
+		RequestID: 1,
+		TrapOID:   MustParseOID("1.3.6.1.6.3.1.1.5.1"),
+	}
+	encoded, err := n.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, "3040020101"+"04067075626c6963"+"a733020101020100020100"+"3028"+
+		"300d06082b06010201010300430100"+"3017060a2b06010603010104010006092b0601060301010501", hex.EncodeToString(encoded))
+
+	var decoded Notification
+	assert.NoError(t, decoded.UnmarshalBinary(encoded))
+	assert.Equal(t, n, decoded)
+
+	n.VarBinds = []VarBind{
+		{OID: MustParseOID("1.3.6.1.2.1.2.2.1.1.3"), Value: Integer(3)},
+		{OID: MustParseOID("1.3.6.1.2.1.2.2.1.2.3"), Value: OctetString("eth0")},
+		{OID: MustParseOID("1.3.6.1.2.1.31.1.1.1.6.3"), Value: Counter64(1 << 40)},
+	}
+	n.RequestID = -5
+	n.Uptime = 123456
+	encoded, err = n.MarshalBinary()
+	assert.NoError(t, err)
+	assert.NoError(t, decoded.UnmarshalBinary(encoded))
+	assert.Equal(t, n, decoded)
+
+	// A GetRequest-PDU is not a notification.
+	getRequest := bytes.Replace(encoded, []byte{tagTrapPDU}, []byte{0xa0}, 1)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(getRequest), ErrNotNotification)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(encoded[:len(encoded)-1]), ErrInvalidBER)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(nil), ErrInvalidBER)
+}
+
+func TestFromRFC5424(t *testing.T) {
+	t.Parallel()
+
+	m, err := rfc5424.NewParser().Parse(bytes.NewReader([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application"][origin ip="192.0.2.1"] An application event log entry`)))
+	assert.NoError(t, err)
+
+	e, err := FromRFC5424(m, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, Entry{
+		Index:     7,
+		Facility:  20,
+		Severity:  5,
+		Version:   1,
+		Timestamp: []byte{0x07, 0xd3, 10, 11, 22, 14, 15, 0, '+', 0, 0},
+		Hostname:  "mymachine.example.com",
+		AppName:   "evntslog",
+		MsgID:     "ID47",
+		Msg:       "An application event log entry",
+		SDParams: []SDParam{
+			{Index: 1, SDID: "exampleSDID@32473", Name: "eventSource", Value: "Application"},
+			{Index: 2, SDID: "exampleSDID@32473", Name: "iut", Value: "3"},
+			{Index: 3, SDID: "origin", Name: "ip", Value: "192.0.2.1"},
+		},
+	}, e)
+
+	varBinds := e.VarBinds()
+	assert.Len(t, varBinds, 10+3*3)
+	assert.Equal(t, VarBind{OID: MustParseOID("1.3.6.1.2.1.192.1.2.1.2.7"), Value: Integer(20)}, varBinds[0])
+	assert.Equal(t, VarBind{OID: MustParseOID("1.3.6.1.2.1.192.1.2.1.11.7"), Value: OctetString("An application event log entry")}, varBinds[9])
+	assert.Equal(t, VarBind{OID: MustParseOID("1.3.6.1.2.1.192.1.3.1.4.7.3"), Value: OctetString("192.0.2.1")}, varBinds[18])
+
+	n := e.Notification("public", 100)
+	assert.Equal(t, OIDSyslogMsgNotification, n.TrapOID)
+	assert.Equal(t, varBinds[:10], n.VarBinds)
+	_, err = n.MarshalBinary()
+	assert.NoError(t, err)
+
+	m.Timestamp = time.Time{}
+	e, err = FromRFC5424(m, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, make([]byte, 8), e.Timestamp)
+}
+
+func TestDateAndTime(t *testing.T) {
+	t.Parallel()
+
+	timestamp := time.Date(2024, time.March, 14, 10, 20, 30, 450000000, time.FixedZone("", -(5*3600+30*60)))
+	assert.Equal(t, []byte{0x07, 0xe8, 3, 14, 10, 20, 30, 4, '-', 5, 30}, dateAndTime(timestamp))
+}
+
+func TestToRFC5424(t *testing.T) {
+	t.Parallel()
+
+	pri, err := common.NewPRIFromParts(3, 4)
+	assert.NoError(t, err)
+	timestamp := time.Date(2024, time.March, 14, 10, 20, 30, 0, time.UTC)
+
+	n := Notification{
+		Uptime:  123,
+		TrapOID: MustParseOID("1.3.6.1.6.3.1.1.5.3"),
+		VarBinds: []VarBind{
+			{OID: MustParseOID("1.3.6.1.2.1.2.2.1.1.3"), Value: Integer(-3)},
+			{OID: MustParseOID("1.3.6.1.2.1.2.2.1.2.3"), Value: OctetString("eth0 \"up\"")},
+			{OID: MustParseOID("1.3.6.1.2.1.2.2.1.6.3"), Value: OctetString{0x00, 0x1b, 0x21}},
+			{OID: MustParseOID("1.3.6.1.2.1.4.20.1.1.3"), Value: IPAddress{192, 0, 2, 1}},
+			{OID: MustParseOID("1.3.6.1.2.1.2.2.1.10.3"), Value: Counter32(1)},
+			{OID: MustParseOID("1.3.6.1.2.1.2.2.1.5.3"), Value: Gauge32(2)},
+			{OID: MustParseOID("1.3.6.1.2.1.31.1.1.1.6.3"), Value: Counter64(3)},
+			{OID: MustParseOID("1.3.6.1.4.1.32473.1"), Value: Null{}},
+			{OID: MustParseOID("1.3.6.1.4.1.32473.2"), Value: Opaque{0xff}},
+		},
+		ContextEngineID: []byte{0x80, 0x00, 0x1f, 0x88},
+		ContextName:     "vrf-red",
+	}
+
+	m, err := ToRFC5424(n, pri, "router", timestamp)
+	assert.NoError(t, err)
+	assert.Equal(t, pri, m.PRI)
+	assert.Equal(t, timestamp, m.Timestamp)
+	assert.Equal(t, "router", m.Hostname)
+	assert.Equal(t, "1.3.6.1.6.3.1.1.5.3", m.Message)
+	assert.Equal(t, []rfc5424.StructuredDataElement{{ID: SDID, Parameters: map[string]string{
+		"ctxEngine": "80001f88",
+		"ctxName":   "vrf-red",
+		"v1":        "1.3.6.1.2.1.1.3.0 t 123",
+		"v2":        "1.3.6.1.6.3.1.1.4.1.0 o 1.3.6.1.6.3.1.1.5.3",
+		"v3":        "1.3.6.1.2.1.2.2.1.1.3 i -3",
+		"v4":        "1.3.6.1.2.1.2.2.1.2.3 s eth0 \"up\"",
+		"v5":        "1.3.6.1.2.1.2.2.1.6.3 x 001b21",
+		"v6":        "1.3.6.1.2.1.4.20.1.1.3 a 192.0.2.1",
+		"v7":        "1.3.6.1.2.1.2.2.1.10.3 c 1",
+		"v8":        "1.3.6.1.2.1.2.2.1.5.3 u 2",
+		"v9":        "1.3.6.1.2.1.31.1.1.1.6.3 C 3",
+		"v10":       "1.3.6.1.4.1.32473.1 n",
+		"v11":       "1.3.6.1.4.1.32473.2 x ff",
+	}}}, *m.StructuredDataElements)
+
+	// The formatted message must be parseable again.
+	parsed, err := rfc5424.NewParser(rfc5424.WithParseStructuredDataElements()).Parse(bytes.NewReader([]byte(m.String())))
+	assert.NoError(t, err)
+	assert.Equal(t, *m.StructuredDataElements, *parsed.StructuredDataElements)
+
+	n.VarBinds = []VarBind{{OID: OID{1, 3}, Value: 3}}
+	_, err = ToRFC5424(n, pri, "router", timestamp)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
@@ -0,0 +1,10 @@
+package spool
+
+import "errors"
+
+var (
+	ErrEmpty   = errors.New("queue is empty")
+	ErrFull    = errors.New("queue has reached its maximum size")
+	ErrClosed  = errors.New("queue is closed")
+	ErrCorrupt = errors.New("entry does not match its checksum")
+)
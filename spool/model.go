@@ -0,0 +1,36 @@
+package spool
+
+// SyncPolicy determines when written data is flushed to stable storage using fsync.
+type SyncPolicy byte
+
+const (
+	// SyncAlways syncs after every Append and Ack. No acknowledged entry is lost or redelivered after a crash, at the
+	// cost of throughput.
+	SyncAlways SyncPolicy = iota
+	// SyncOnRotate syncs when a segment is full. Entries appended since, and acknowledgements, can be lost on a power
+	// failure. Call Sync periodically to bound the loss.
+	SyncOnRotate
+	// SyncNever leaves flushing to the operating system.
+	SyncNever
+)
+
+// Stats describes the contents of a Queue.
+type Stats struct {
+	// Entries is the number of entries that have not been acknowledged.
+	Entries int
+	// Segments is the number of segment files.
+	Segments int
+	// Size is the total size of the segment files, including acknowledged entries of the oldest segment.
+	Size int64
+	// Truncated is the number of bytes of incomplete or corrupt entries that were discarded by Open or Ack.
+	Truncated int64
+}
+
+// segment is a file holding a sequence of entries. Segments are named after their id, which increases with every
+// segment.
+type segment struct {
+	id   uint64
+	size int64
+	// entries is the number of unacknowledged entries in the segment.
+	entries int
+}
@@ -0,0 +1,26 @@
+package spool
+
+type option func(*Queue)
+
+// WithSegmentSize sets the size after which a new segment file is started. Entries are never split, so a segment can
+// exceed it by one entry. It defaults to 16 MiB.
+func WithSegmentSize(size int64) option {
+	return func(q *Queue) {
+		q.segmentSize = size
+	}
+}
+
+// WithMaxSize limits the total size of the unacknowledged entries, including their headers. Append returns ErrFull
+// when an entry does not fit. There is no limit by default.
+func WithMaxSize(size int64) option {
+	return func(q *Queue) {
+		q.maxSize = size
+	}
+}
+
+// WithSyncPolicy sets when data is synced to disk. It defaults to SyncAlways.
+func WithSyncPolicy(policy SyncPolicy) option {
+	return func(q *Queue) {
+		q.syncPolicy = policy
+	}
+}
@@ -0,0 +1,164 @@
+package spool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// An entry is stored as its length and CRC-32C checksum, both 32-bit big endian, followed by the data.
+const headerSize = 8
+
+// The cursor holds the id of the oldest segment and the offset of its first unacknowledged entry, followed by a
+// checksum of both.
+const (
+	cursorName = "cursor"
+	cursorSize = 20
+)
+
+const segmentExtension = ".seg"
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+func appendEntry(dst, data []byte) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(data)))
+	dst = binary.BigEndian.AppendUint32(dst, crc32.Checksum(data, castagnoli))
+	return append(dst, data...)
+}
+
+// readEntry reads the entry at the offset of the file.
+func readEntry(f *os.File, offset int64) ([]byte, error) {
+	var header [headerSize]byte
+	if _, err := f.ReadAt(header[:], offset); err != nil {
+		return nil, err
+	}
+	data := make([]byte, binary.BigEndian.Uint32(header[:4]))
+	if _, err := f.ReadAt(data, offset+headerSize); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(data, castagnoli) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, ErrCorrupt
+	}
+	return data, nil
+}
+
+// scanResult describes the valid entries at the start of a segment file.
+type scanResult struct {
+	size int64
+	// entries is the number of entries at or after the start offset.
+	entries int
+	// aligned reports whether an entry starts at the start offset.
+	aligned bool
+}
+
+// scan validates the entries of a segment file up to the first incomplete or corrupt entry.
+func scan(f *os.File, start int64) (scanResult, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return scanResult{}, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return scanResult{}, err
+	}
+
+	reader := bufio.NewReader(f)
+	var (
+		result scanResult
+		header [headerSize]byte
+		data   []byte
+	)
+	for {
+		result.aligned = result.aligned || result.size == start
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			break
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		if result.size+headerSize+length > info.Size() {
+			break
+		}
+		if int64(cap(data)) < length {
+			data = make([]byte, length)
+		}
+		data = data[:length]
+		if _, err := io.ReadFull(reader, data); err != nil {
+			break
+		}
+		if crc32.Checksum(data, castagnoli) != binary.BigEndian.Uint32(header[4:]) {
+			break
+		}
+		if result.size >= start {
+			result.entries++
+		}
+		result.size += headerSize + length
+	}
+	return result, nil
+}
+
+func encodeCursor(id uint64, offset int64) []byte {
+	b := make([]byte, 0, cursorSize)
+	b = binary.BigEndian.AppendUint64(b, id)
+	b = binary.BigEndian.AppendUint64(b, uint64(offset))
+	return binary.BigEndian.AppendUint32(b, crc32.Checksum(b, castagnoli))
+}
+
+// decodeCursor decodes a cursor. A missing or torn cursor is reported as invalid.
+func decodeCursor(b []byte) (uint64, int64, bool) {
+	if len(b) != cursorSize || crc32.Checksum(b[:16], castagnoli) != binary.BigEndian.Uint32(b[16:]) {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint64(b), int64(binary.BigEndian.Uint64(b[8:])), true
+}
+
+func segmentPath(dir string, id uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", id, segmentExtension))
+}
+
+// listSegments returns the segments in the directory, oldest first.
+func listSegments(dir string) ([]segment, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []segment
+	for _, file := range files {
+		name, ok := strings.CutSuffix(file.Name(), segmentExtension)
+		if !ok || file.IsDir() {
+			continue
+		}
+		id, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment{id: id, size: info.Size()})
+	}
+	// ReadDir sorts by name, and the ids are zero padded.
+	return segments, nil
+}
+
+// syncDir syncs a directory, so that created and removed files survive a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	if errors.Is(err, os.ErrInvalid) {
+		// Not all platforms support syncing directories.
+		return nil
+	}
+	return err
+}
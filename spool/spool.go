@@ -0,0 +1,372 @@
+// Package spool implements a durable first-in first-out queue on disk, to hold messages while a destination is
+// unreachable.
+//
+// Entries are appended to segment files and carry a checksum. A consumer reads the oldest entry using Peek and removes
+// it using Ack once it has been delivered, which gives at-least-once delivery: after a crash, entries that were read
+// but not acknowledged are delivered again. Segments are deleted once all their entries are acknowledged. Open
+// recovers a queue after a crash by discarding incomplete entries at the end of the segments.
+package spool
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Queue is a durable queue in a directory. It is safe for concurrent use, although entries should be acknowledged by
+// a single consumer.
+type Queue struct {
+	dir         string
+	segmentSize int64
+	maxSize     int64
+	syncPolicy  SyncPolicy
+
+	mu sync.Mutex
+	// segments holds the segments oldest first. Entries are read from the first and appended to the last.
+	segments   []segment
+	write      *os.File
+	read       *os.File
+	readOffset int64
+	cursor     *os.File
+	entries    int
+	size       int64
+	truncated  int64
+	buf        []byte
+	closed     bool
+	// pending is the size of the entry returned by Peek.
+	pending int64
+	// unreadable is set when the entry at the read offset could not be read, so Ack discards it.
+	unreadable bool
+}
+
+// Open opens the queue in the directory, creating the directory if needed. Entries left by a previous run are
+// recovered, starting after the last acknowledged entry.
+func Open(dir string, options ...option) (*Queue, error) {
+	q := &Queue{dir: dir, segmentSize: 16 << 20}
+	for _, option := range options {
+		option(q)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := q.recover(); err != nil {
+		q.closeFiles()
+		return nil, err
+	}
+	return q, nil
+}
+
+// recover opens the files of the queue and discards anything that was not completely written before a crash.
+func (q *Queue) recover() error {
+	var err error
+	q.cursor, err = os.OpenFile(filepath.Join(q.dir, cursorName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	b := make([]byte, cursorSize+1)
+	n, err := q.cursor.ReadAt(b, 0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	id, offset, ok := decodeCursor(b[:n])
+
+	segments, err := listSegments(q.dir)
+	if err != nil {
+		return err
+	}
+	// Segments before the cursor were fully acknowledged, but not yet removed.
+	for len(segments) > 0 && ok && segments[0].id < id {
+		if err := os.Remove(segmentPath(q.dir, segments[0].id)); err != nil {
+			return err
+		}
+		segments = segments[1:]
+	}
+	if len(segments) == 0 || segments[0].id != id {
+		offset = 0
+	}
+
+	for i := range segments {
+		start := int64(0)
+		if i == 0 {
+			start = offset
+		}
+		size, entries, err := q.repair(segments[i], start)
+		if err != nil {
+			return err
+		}
+		if i == 0 && entries < 0 {
+			// The cursor does not point to an entry, so everything is delivered again.
+			offset = 0
+			size, entries, err = q.repair(segments[i], 0)
+			if err != nil {
+				return err
+			}
+		}
+		segments[i].size = size
+		segments[i].entries = entries
+		q.entries += entries
+		q.size += size
+	}
+
+	if len(segments) == 0 {
+		segments = []segment{{id: 1}}
+		f, err := os.OpenFile(segmentPath(q.dir, 1), os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			return err
+		}
+		f.Close()
+	}
+	q.segments = segments
+
+	last := segments[len(segments)-1]
+	if q.write, err = os.OpenFile(segmentPath(q.dir, last.id), os.O_WRONLY|os.O_APPEND, 0); err != nil {
+		return err
+	}
+	if q.read, err = os.Open(segmentPath(q.dir, segments[0].id)); err != nil {
+		return err
+	}
+	q.readOffset = offset
+	return q.writeCursor()
+}
+
+// repair truncates a segment after its last valid entry and returns its size and the number of entries from start,
+// or -1 entries if no entry starts there.
+func (q *Queue) repair(s segment, start int64) (int64, int, error) {
+	f, err := os.OpenFile(segmentPath(q.dir, s.id), os.O_RDWR, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	result, err := scan(f, start)
+	if err != nil {
+		return 0, 0, err
+	}
+	if result.size < s.size {
+		if err := f.Truncate(result.size); err != nil {
+			return 0, 0, err
+		}
+		if err := f.Sync(); err != nil {
+			return 0, 0, err
+		}
+		q.truncated += s.size - result.size
+		s.size = result.size
+	}
+	if !result.aligned {
+		return s.size, -1, nil
+	}
+	return s.size, result.entries, nil
+}
+
+// Append adds an entry to the end of the queue.
+func (q *Queue) Append(data []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+	n := int64(headerSize + len(data))
+	// Acknowledged entries of the oldest segment are still on disk, but do not count towards the limit.
+	if q.maxSize > 0 && q.size-q.readOffset+n > q.maxSize {
+		return ErrFull
+	}
+	last := &q.segments[len(q.segments)-1]
+	if last.size > 0 && last.size+n > q.segmentSize {
+		if err := q.rotate(); err != nil {
+			return err
+		}
+		last = &q.segments[len(q.segments)-1]
+	}
+
+	q.buf = appendEntry(q.buf[:0], data)
+	if _, err := q.write.Write(q.buf); err != nil {
+		// Remove a partially written entry, so the next entry is not appended after it.
+		_ = q.write.Truncate(last.size)
+		return err
+	}
+	last.size += n
+	last.entries++
+	q.size += n
+	q.entries++
+	if q.syncPolicy == SyncAlways {
+		return q.write.Sync()
+	}
+	return nil
+}
+
+// rotate starts a new segment.
+func (q *Queue) rotate() error {
+	if q.syncPolicy != SyncNever {
+		if err := q.write.Sync(); err != nil {
+			return err
+		}
+	}
+	id := q.segments[len(q.segments)-1].id + 1
+	f, err := os.OpenFile(segmentPath(q.dir, id), os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if q.syncPolicy != SyncNever {
+		if err := syncDir(q.dir); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	q.write.Close()
+	q.write = f
+	q.segments = append(q.segments, segment{id: id})
+	return nil
+}
+
+// Peek returns the oldest unacknowledged entry without removing it. It returns ErrEmpty if there is none. Calling Peek
+// again returns the same entry until it is acknowledged.
+func (q *Queue) Peek() ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.peek()
+}
+
+func (q *Queue) peek() ([]byte, error) {
+	if q.closed {
+		return nil, ErrClosed
+	}
+	if q.entries == 0 {
+		return nil, ErrEmpty
+	}
+	// Skip segments that were emptied by an Ack before a new segment was started.
+	for q.readOffset >= q.segments[0].size {
+		if err := q.removeHead(); err != nil {
+			return nil, err
+		}
+	}
+	data, err := readEntry(q.read, q.readOffset)
+	if err != nil {
+		q.unreadable = true
+		return nil, err
+	}
+	q.unreadable = false
+	q.pending = int64(headerSize + len(data))
+	return data, nil
+}
+
+// Ack removes the oldest entry, which is normally the one returned by Peek, from the queue.
+//
+// If Peek returned ErrCorrupt or an I/O error, Ack discards the rest of the oldest segment instead, as the entries
+// after an unreadable one cannot be located reliably. The discarded bytes are counted in Stats.Truncated.
+func (q *Queue) Ack() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.pending == 0 {
+		if _, err := q.peek(); err != nil {
+			if !q.unreadable {
+				return err
+			}
+			return q.discardHead()
+		}
+	}
+	q.readOffset += q.pending
+	q.pending = 0
+	q.entries--
+	q.segments[0].entries--
+
+	if q.readOffset >= q.segments[0].size && len(q.segments) > 1 {
+		return q.removeHead()
+	}
+	return q.writeCursor()
+}
+
+// discardHead drops the unacknowledged entries of the oldest segment.
+func (q *Queue) discardHead() error {
+	head := &q.segments[0]
+	q.truncated += head.size - q.readOffset
+	q.entries -= head.entries
+	head.entries = 0
+	q.readOffset = head.size
+	q.unreadable = false
+
+	if len(q.segments) > 1 {
+		return q.removeHead()
+	}
+	return q.writeCursor()
+}
+
+// removeHead deletes the oldest segment, after all its entries have been acknowledged.
+func (q *Queue) removeHead() error {
+	head := q.segments[0]
+	next := q.segments[1]
+	read, err := os.Open(segmentPath(q.dir, next.id))
+	if err != nil {
+		return err
+	}
+	q.read.Close()
+	q.read = read
+	q.readOffset = 0
+	q.segments = q.segments[1:]
+	q.size -= head.size
+
+	// The cursor is moved first, so a crash never causes a removed segment to be expected.
+	if err := q.writeCursor(); err != nil {
+		return err
+	}
+	return os.Remove(segmentPath(q.dir, head.id))
+}
+
+func (q *Queue) writeCursor() error {
+	if _, err := q.cursor.WriteAt(encodeCursor(q.segments[0].id, q.readOffset), 0); err != nil {
+		return err
+	}
+	if q.syncPolicy == SyncAlways {
+		return q.cursor.Sync()
+	}
+	return nil
+}
+
+// Sync flushes the appended entries and acknowledgements to disk.
+func (q *Queue) Sync() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+	if err := q.write.Sync(); err != nil {
+		return err
+	}
+	return q.cursor.Sync()
+}
+
+// Stats returns the current state of the queue.
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return Stats{Entries: q.entries, Segments: len(q.segments), Size: q.size, Truncated: q.truncated}
+}
+
+// Close syncs and closes the queue. Unacknowledged entries are kept for the next Open.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+	q.closed = true
+	err := q.write.Sync()
+	if syncErr := q.cursor.Sync(); err == nil {
+		err = syncErr
+	}
+	q.closeFiles()
+	return err
+}
+
+func (q *Queue) closeFiles() {
+	for _, f := range []*os.File{q.write, q.read, q.cursor} {
+		if f != nil {
+			f.Close()
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package spool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	q, err := Open(t.TempDir())
+	assert.Nil(t, err)
+	defer q.Close()
+
+	_, err = q.Peek()
+	assert.Equal(t, ErrEmpty, err)
+	assert.Equal(t, ErrEmpty, q.Ack())
+
+	for _, entry := range []string{"first", "", "third"} {
+		assert.Nil(t, q.Append([]byte(entry)))
+	}
+	assert.Equal(t, Stats{Entries: 3, Segments: 1, Size: 3*headerSize + 10}, q.Stats())
+
+	for _, expected := range []string{"first", ""} {
+		for range 2 {
+			data, err := q.Peek()
+			assert.Nil(t, err)
+			assert.Equal(t, expected, string(data))
+		}
+		assert.Nil(t, q.Ack())
+	}
+	// Ack without Peek acknowledges the oldest entry.
+	assert.Nil(t, q.Ack())
+
+	_, err = q.Peek()
+	assert.Equal(t, ErrEmpty, err)
+	assert.Equal(t, 0, q.Stats().Entries)
+}
+
+func TestSegments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	q, err := Open(dir, WithSegmentSize(64))
+	assert.Nil(t, err)
+	defer q.Close()
+
+	for i := range 20 {
+		assert.Nil(t, q.Append([]byte(fmt.Sprintf("entry %02d", i))))
+	}
+	// An entry takes 16 bytes, so a segment holds 4.
+	assert.Equal(t, Stats{Entries: 20, Segments: 5, Size: 320}, q.Stats())
+	assert.Len(t, segmentFiles(t, dir), 5)
+
+	for i := range 18 {
+		data, err := q.Peek()
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("entry %02d", i), string(data))
+		assert.Nil(t, q.Ack())
+	}
+	assert.Equal(t, Stats{Entries: 2, Segments: 1, Size: 64}, q.Stats())
+	assert.Equal(t, []string{"00000000000000000005.seg"}, segmentFiles(t, dir))
+
+	// An entry larger than a segment gets a segment of its own.
+	assert.Nil(t, q.Append(make([]byte, 100)))
+	assert.Nil(t, q.Append([]byte("after")))
+	assert.Equal(t, 3, q.Stats().Segments)
+}
+
+func TestMaxSize(t *testing.T) {
+	t.Parallel()
+
+	q, err := Open(t.TempDir(), WithSegmentSize(32), WithMaxSize(64))
+	assert.Nil(t, err)
+	defer q.Close()
+
+	for range 4 {
+		assert.Nil(t, q.Append([]byte("12345678")))
+	}
+	assert.Equal(t, ErrFull, q.Append([]byte("x")))
+
+	// Space is freed once an entry is acknowledged.
+	assert.Nil(t, q.Ack())
+	assert.Nil(t, q.Append([]byte("12345678")))
+	assert.Equal(t, ErrFull, q.Append([]byte("x")))
+}
+
+func TestMaxSizeSmallerThanSegment(t *testing.T) {
+	t.Parallel()
+
+	q, err := Open(t.TempDir(), WithMaxSize(64))
+	assert.Nil(t, err)
+	defer q.Close()
+
+	// Acknowledged entries stay in the segment, but do not count towards the limit.
+	for i := range 100 {
+		assert.Nil(t, q.Append([]byte(fmt.Sprintf("entry %02d", i))))
+		assert.Nil(t, q.Append([]byte(fmt.Sprintf("entry %02d", i))))
+		assert.Equal(t, []string{fmt.Sprintf("entry %02d", i), fmt.Sprintf("entry %02d", i)}, drain(t, q))
+	}
+	assert.Equal(t, Stats{Segments: 1, Size: 3200}, q.Stats())
+}
+
+func TestCorruptEntry(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	q, err := Open(dir, WithSegmentSize(64))
+	assert.Nil(t, err)
+	defer q.Close()
+
+	for i := range 10 {
+		assert.Nil(t, q.Append([]byte(fmt.Sprintf("entry %02d", i))))
+	}
+	flip(t, segmentPath(dir, 1), 16+headerSize)
+
+	data, err := q.Peek()
+	assert.Nil(t, err)
+	assert.Equal(t, "entry 00", string(data))
+	assert.Nil(t, q.Ack())
+
+	// The corrupt entry is returned until Ack discards the rest of its segment.
+	for range 2 {
+		_, err = q.Peek()
+		assert.Equal(t, ErrCorrupt, err)
+	}
+	assert.Nil(t, q.Ack())
+	assert.Equal(t, entries(4, 10), drain(t, q))
+	assert.Equal(t, int64(48), q.Stats().Truncated)
+}
+
+func TestClosed(t *testing.T) {
+	t.Parallel()
+
+	q, err := Open(t.TempDir())
+	assert.Nil(t, err)
+	assert.Nil(t, q.Close())
+
+	assert.Equal(t, ErrClosed, q.Append([]byte("x")))
+	_, err = q.Peek()
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, ErrClosed, q.Ack())
+	assert.Equal(t, ErrClosed, q.Sync())
+	assert.Equal(t, ErrClosed, q.Close())
+}
+
+func TestReopen(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []SyncPolicy{SyncAlways, SyncOnRotate, SyncNever} {
+		dir := t.TempDir()
+		q, err := Open(dir, WithSegmentSize(64), WithSyncPolicy(policy))
+		assert.Nil(t, err)
+		for i := range 10 {
+			assert.Nil(t, q.Append([]byte(fmt.Sprintf("entry %02d", i))))
+		}
+		for range 5 {
+			assert.Nil(t, q.Ack())
+		}
+		assert.Nil(t, q.Close())
+
+		q, err = Open(dir, WithSegmentSize(64), WithSyncPolicy(policy))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"entry 05", "entry 06", "entry 07", "entry 08", "entry 09"}, drain(t, q))
+		assert.Nil(t, q.Close())
+	}
+}
+
+func TestCrashRecovery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// damage modifies the files of a queue holding "entry 00" to "entry 09" in segments of 4 entries, of which the
+		// first 3 are acknowledged and the fourth was returned by Peek.
+		damage    func(t *testing.T, dir string)
+		expected  []string
+		truncated int64
+	}{
+		{
+			name:     "unacknowledged entries are delivered again",
+			damage:   func(*testing.T, string) {},
+			expected: entries(3, 10),
+		},
+		{
+			name: "torn entry",
+			damage: func(t *testing.T, dir string) {
+				truncate(t, segmentPath(dir, 3), 4)
+			},
+			expected:  entries(3, 9),
+			truncated: 12,
+		},
+		{
+			name: "torn header",
+			damage: func(t *testing.T, dir string) {
+				truncate(t, segmentPath(dir, 3), 12)
+			},
+			expected:  entries(3, 9),
+			truncated: 4,
+		},
+		{
+			name: "garbage after the last entry",
+			damage: func(t *testing.T, dir string) {
+				appendFile(t, segmentPath(dir, 3), []byte{0, 0, 0, 0xff, 1, 2})
+			},
+			expected:  entries(3, 10),
+			truncated: 6,
+		},
+		{
+			name: "corrupt entry in an older segment",
+			damage: func(t *testing.T, dir string) {
+				flip(t, segmentPath(dir, 2), 16+headerSize)
+			},
+			expected:  append(entries(3, 5), entries(8, 10)...),
+			truncated: 48,
+		},
+		{
+			name: "torn cursor",
+			damage: func(t *testing.T, dir string) {
+				flip(t, filepath.Join(dir, cursorName), 3)
+			},
+			expected: entries(0, 10),
+		},
+		{
+			name: "cursor inside an entry",
+			damage: func(t *testing.T, dir string) {
+				assert.Nil(t, os.WriteFile(filepath.Join(dir, cursorName), encodeCursor(1, 20), 0o644))
+			},
+			expected: entries(0, 10),
+		},
+		{
+			name: "acknowledged segment that was not removed",
+			damage: func(t *testing.T, dir string) {
+				assert.Nil(t, os.WriteFile(filepath.Join(dir, cursorName), encodeCursor(2, 0), 0o644))
+			},
+			expected: entries(4, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			q, err := Open(dir, WithSegmentSize(64))
+			assert.Nil(t, err)
+			for i := range 10 {
+				assert.Nil(t, q.Append([]byte(fmt.Sprintf("entry %02d", i))))
+			}
+			for range 3 {
+				assert.Nil(t, q.Ack())
+			}
+			data, err := q.Peek()
+			assert.Nil(t, err)
+			assert.Equal(t, "entry 03", string(data))
+			crash(q)
+
+			tt.damage(t, dir)
+
+			q, err = Open(dir, WithSegmentSize(64))
+			assert.Nil(t, err)
+			defer q.Close()
+			assert.Equal(t, tt.truncated, q.Stats().Truncated)
+
+			// The queue accepts new entries after recovery.
+			assert.Nil(t, q.Append([]byte("new")))
+			assert.Equal(t, append(tt.expected, "new"), drain(t, q))
+		})
+	}
+}
+
+// crash closes the files of the queue without syncing them, as if the process was killed.
+func crash(q *Queue) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.closeFiles()
+}
+
+func drain(t *testing.T, q *Queue) []string {
+	t.Helper()
+	var out []string
+	for {
+		data, err := q.Peek()
+		if err == ErrEmpty {
+			return out
+		}
+		if !assert.Nil(t, err) {
+			return out
+		}
+		out = append(out, string(data))
+		assert.Nil(t, q.Ack())
+	}
+}
+
+func entries(from, to int) []string {
+	var out []string
+	for i := from; i < to; i++ {
+		out = append(out, fmt.Sprintf("entry %02d", i))
+	}
+	return out
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+segmentExtension))
+	assert.Nil(t, err)
+	for i, match := range matches {
+		matches[i] = filepath.Base(match)
+	}
+	return matches
+}
+
+func truncate(t *testing.T, path string, removed int64) {
+	t.Helper()
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Nil(t, os.Truncate(path, info.Size()-removed))
+}
+
+func appendFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	assert.Nil(t, err)
+	_, err = f.Write(data)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+}
+
+func flip(t *testing.T, path string, offset int) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	data[offset] ^= 0xff
+	assert.Nil(t, os.WriteFile(path, data, 0o644))
+}
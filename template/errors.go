@@ -0,0 +1,10 @@
+package template
+
+import "errors"
+
+var (
+	ErrUnterminatedProperty = errors.New("template contains an unterminated property")
+	ErrUnknownProperty      = errors.New("template contains an unknown property")
+	ErrInvalidProperty      = errors.New("template contains a property with invalid arguments")
+	ErrUnknownOption        = errors.New("template contains an unknown property option")
+)
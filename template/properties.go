@@ -0,0 +1,108 @@
+package template
+
+import (
+	"strconv"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// timestampLayout is RFC3339 with at most the six fractional digits that RFC5424 allows.
+const timestampLayout = "2006-01-02T15:04:05.999999Z07:00"
+
+// property appends the value of a property of the message to dst.
+type property func(dst []byte, m *rfc5424.Message) []byte
+
+var properties = map[string]property{
+	"MSG":             func(dst []byte, m *rfc5424.Message) []byte { return append(dst, m.Message...) },
+	"HOSTNAME":        func(dst []byte, m *rfc5424.Message) []byte { return appendNil(dst, m.Hostname) },
+	"APP-NAME":        func(dst []byte, m *rfc5424.Message) []byte { return appendNil(dst, m.AppName) },
+	"PROCID":          func(dst []byte, m *rfc5424.Message) []byte { return appendNil(dst, m.ProcID) },
+	"MSGID":           func(dst []byte, m *rfc5424.Message) []byte { return appendNil(dst, m.MsgID) },
+	"STRUCTURED-DATA": appendStructuredData,
+	"SYSLOGTAG":       appendSyslogTag,
+	"PRI-TEXT":        appendPRIText,
+	"PRI": func(dst []byte, m *rfc5424.Message) []byte {
+		return strconv.AppendUint(dst, uint64(m.PRI.Value()), 10)
+	},
+	"FACILITY": func(dst []byte, m *rfc5424.Message) []byte {
+		return strconv.AppendUint(dst, uint64(m.PRI.Facility()), 10)
+	},
+	"FACILITY-TEXT": func(dst []byte, m *rfc5424.Message) []byte { return append(dst, m.PRI.FacilityName()...) },
+	"SEVERITY": func(dst []byte, m *rfc5424.Message) []byte {
+		return strconv.AppendUint(dst, uint64(m.PRI.Severity()), 10)
+	},
+	"SEVERITY-TEXT": func(dst []byte, m *rfc5424.Message) []byte { return append(dst, m.PRI.SeverityName()...) },
+	"VERSION": func(dst []byte, m *rfc5424.Message) []byte {
+		return strconv.AppendUint(dst, uint64(max(m.Version, 1)), 10)
+	},
+}
+
+// appendNil appends the value of a header field, or the NILVALUE if it is empty.
+func appendNil(dst []byte, value string) []byte {
+	if value == "" {
+		return append(dst, '-')
+	}
+	return append(dst, value...)
+}
+
+func appendStructuredData(dst []byte, m *rfc5424.Message) []byte {
+	if m.StructuredDataElements != nil {
+		return appendNil(dst, rfc5424.FormatStructuredData(*m.StructuredDataElements))
+	}
+	return appendNil(dst, m.StructuredData)
+}
+
+// appendSyslogTag appends the RFC3164 TAG, e.g. "sshd[42]:".
+func appendSyslogTag(dst []byte, m *rfc5424.Message) []byte {
+	dst = append(dst, m.AppName...)
+	if m.ProcID != "" {
+		dst = append(dst, '[')
+		dst = append(dst, m.ProcID...)
+		dst = append(dst, ']')
+	}
+	return append(dst, ':')
+}
+
+// appendPRIText appends the PRI in the form used by rsyslog, e.g. "local4.notice<165>".
+func appendPRIText(dst []byte, m *rfc5424.Message) []byte {
+	dst = append(dst, m.PRI.FacilityName()...)
+	dst = append(dst, '.')
+	dst = append(dst, m.PRI.SeverityName()...)
+	dst = append(dst, '<')
+	dst = strconv.AppendUint(dst, uint64(m.PRI.Value()), 10)
+	return append(dst, '>')
+}
+
+// timestampFormats holds the formats of the TIMESTAMP property. A zero timestamp is written as the NILVALUE.
+var timestampFormats = map[string]property{
+	"rfc3164": func(dst []byte, m *rfc5424.Message) []byte { return m.Timestamp.AppendFormat(dst, "Jan _2 15:04:05") },
+	"rfc3339": func(dst []byte, m *rfc5424.Message) []byte { return m.Timestamp.AppendFormat(dst, timestampLayout) },
+	"unixtimestamp": func(dst []byte, m *rfc5424.Message) []byte {
+		return strconv.AppendInt(dst, m.Timestamp.Unix(), 10)
+	},
+	"year": func(dst []byte, m *rfc5424.Message) []byte {
+		return strconv.AppendInt(dst, int64(m.Timestamp.Year()), 10)
+	},
+	"month":  func(dst []byte, m *rfc5424.Message) []byte { return m.Timestamp.AppendFormat(dst, "01") },
+	"day":    func(dst []byte, m *rfc5424.Message) []byte { return m.Timestamp.AppendFormat(dst, "02") },
+	"hour":   func(dst []byte, m *rfc5424.Message) []byte { return m.Timestamp.AppendFormat(dst, "15") },
+	"minute": func(dst []byte, m *rfc5424.Message) []byte { return m.Timestamp.AppendFormat(dst, "04") },
+	"second": func(dst []byte, m *rfc5424.Message) []byte { return m.Timestamp.AppendFormat(dst, "05") },
+}
+
+func timestamp(format property) property {
+	return func(dst []byte, m *rfc5424.Message) []byte {
+		if m.Timestamp.IsZero() {
+			return append(dst, '-')
+		}
+		return format(dst, m)
+	}
+}
+
+// structuredDataParameter returns the property for a parameter of a structured data element. A missing parameter is
+// written as an empty string.
+func structuredDataParameter(id, name string) property {
+	return func(dst []byte, m *rfc5424.Message) []byte {
+		return append(dst, m.Parameter(id, name)...)
+	}
+}
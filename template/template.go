@@ -0,0 +1,297 @@
+// Package template formats syslog messages using templates modelled after rsyslog templates, such as
+//
+//	%TIMESTAMP:rfc3339% %HOSTNAME% %SYSLOGTAG%%MSG%\n
+//
+// A property is written as %NAME%, optionally followed by the positions and options of the rsyslog property
+// replacer: %NAME:FROM:TO:OPTIONS%. FROM and TO select the characters FROM up to and including TO, counting from 1,
+// and OPTIONS is a comma separated list of lowercase, uppercase, json and csv. The json option escapes the value for
+// use inside a JSON string, the csv option quotes it as a CSV field as defined in RFC 4180.
+//
+// The properties are MSG, HOSTNAME, APP-NAME, PROCID, MSGID, STRUCTURED-DATA, SYSLOGTAG, PRI, PRI-TEXT, FACILITY,
+// FACILITY-TEXT, SEVERITY, SEVERITY-TEXT, VERSION and TIMESTAMP. Names are case insensitive. Empty header fields are
+// written as the NILVALUE "-". TIMESTAMP takes an optional format as in %TIMESTAMP:rfc3339%: rfc3164 (the default),
+// rfc3339, unixtimestamp, year, month, day, hour, minute or second. The value of a structured data parameter is
+// written as %SD:SD-ID:PARAM-NAME%.
+//
+// Outside of properties, \n, \t, \r, \\ and \% are replaced by a newline, tab, carriage return, backslash and percent
+// sign.
+//
+// RFC3164 messages are formatted as converted by convert.ToRFC5424, so %SYSLOGTAG% is rebuilt from APP-NAME and PROCID.
+package template
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ysmilda/syslog/convert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Template is a compiled template. It is safe for concurrent use.
+type Template struct {
+	parts []part
+	// parameters is set when the template writes a structured data parameter.
+	parameters bool
+}
+
+// part is either literal text or a property.
+type part struct {
+	literal  string
+	property property
+	// parameter is set for the value of a structured data parameter.
+	parameter bool
+	// from and to are the 1-based positions of the first and last character to write. Zero selects the start and end
+	// of the value.
+	from, to int
+	// modifiers are applied in order, as given by the options of the property.
+	modifiers []modifier
+}
+
+// modifier appends a transformed value to dst.
+type modifier func(dst []byte, value string) []byte
+
+var modifiers = map[string]modifier{
+	"lowercase": func(dst []byte, value string) []byte { return append(dst, strings.ToLower(value)...) },
+	"uppercase": func(dst []byte, value string) []byte { return append(dst, strings.ToUpper(value)...) },
+	"json":      appendJSON,
+	"csv":       appendCSV,
+}
+
+// Compile parses a template.
+func Compile(text string) (*Template, error) {
+	t := &Template{}
+	var literal strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\\':
+			if i+1 < len(text) {
+				i++
+				literal.WriteByte(unescape(text[i]))
+			} else {
+				literal.WriteByte(c)
+			}
+		case '%':
+			end := strings.IndexByte(text[i+1:], '%')
+			if end < 0 {
+				return nil, ErrUnterminatedProperty
+			}
+			p, err := parseProperty(text[i+1 : i+1+end])
+			if err != nil {
+				return nil, err
+			}
+			if literal.Len() > 0 {
+				t.parts = append(t.parts, part{literal: literal.String()})
+				literal.Reset()
+			}
+			t.parts = append(t.parts, p)
+			t.parameters = t.parameters || p.parameter
+			i += end + 1
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	if literal.Len() > 0 {
+		t.parts = append(t.parts, part{literal: literal.String()})
+	}
+	return t, nil
+}
+
+// MustCompile is like Compile but panics if the template is invalid.
+func MustCompile(text string) *Template {
+	t, err := Compile(text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	default:
+		return c
+	}
+}
+
+// parseProperty parses the text between the percent signs of a property.
+func parseProperty(spec string) (part, error) {
+	fields := strings.Split(spec, ":")
+	name := strings.ToUpper(fields[0])
+	rest := fields[1:]
+
+	var p part
+	switch name {
+	case "SD":
+		if len(rest) < 2 || rest[0] == "" || rest[1] == "" {
+			return part{}, ErrInvalidProperty
+		}
+		p.property = structuredDataParameter(rest[0], rest[1])
+		p.parameter = true
+		rest = rest[2:]
+	case "TIMESTAMP":
+		format := timestampFormats["rfc3164"]
+		if len(rest) > 0 {
+			if f, ok := timestampFormats[strings.ToLower(rest[0])]; ok {
+				format = f
+				rest = rest[1:]
+			}
+		}
+		p.property = timestamp(format)
+	default:
+		property, ok := properties[name]
+		if !ok {
+			return part{}, ErrUnknownProperty
+		}
+		p.property = property
+	}
+
+	if len(rest) > 3 {
+		return part{}, ErrInvalidProperty
+	}
+	positions := [2]*int{&p.from, &p.to}
+	for i, field := range rest[:min(len(rest), 2)] {
+		if field == "" {
+			continue
+		}
+		position, err := strconv.Atoi(field)
+		if err != nil || position < 1 {
+			return part{}, ErrInvalidProperty
+		}
+		*positions[i] = position
+	}
+	if p.to != 0 && p.to < p.from {
+		return part{}, ErrInvalidProperty
+	}
+	if len(rest) == 3 && rest[2] != "" {
+		for _, name := range strings.Split(rest[2], ",") {
+			modify, ok := modifiers[strings.ToLower(strings.TrimSpace(name))]
+			if !ok {
+				return part{}, ErrUnknownOption
+			}
+			p.modifiers = append(p.modifiers, modify)
+		}
+	}
+	return p, nil
+}
+
+// AppendRFC5424 formats the message and appends it to dst.
+func (t *Template) AppendRFC5424(dst []byte, m rfc5424.Message) []byte {
+	// The structured data is parsed up front, so it is parsed once rather than once per parameter.
+	if t.parameters && m.StructuredDataElements == nil {
+		elements, _ := m.Elements()
+		m.StructuredDataElements = &elements
+	}
+	for _, p := range t.parts {
+		if p.property == nil {
+			dst = append(dst, p.literal...)
+			continue
+		}
+		if p.from == 0 && p.to == 0 && p.modifiers == nil {
+			dst = p.property(dst, &m)
+			continue
+		}
+		start := len(dst)
+		dst = p.property(dst, &m)
+		value := substring(string(dst[start:]), p.from, p.to)
+		for _, modify := range p.modifiers {
+			value = string(modify(nil, value))
+		}
+		dst = append(dst[:start], value...)
+	}
+	return dst
+}
+
+// AppendRFC3164 formats the message and appends it to dst.
+func (t *Template) AppendRFC3164(dst []byte, m rfc3164.Message) []byte {
+	return t.AppendRFC5424(dst, convert.ToRFC5424(m))
+}
+
+// FormatRFC5424 formats the message.
+func (t *Template) FormatRFC5424(m rfc5424.Message) string {
+	return string(t.AppendRFC5424(nil, m))
+}
+
+// FormatRFC3164 formats the message.
+func (t *Template) FormatRFC3164(m rfc3164.Message) string {
+	return string(t.AppendRFC3164(nil, m))
+}
+
+// substring returns the characters from up to and including to, counting from 1. A zero to selects the end of the
+// value.
+func substring(value string, from, to int) string {
+	start, end := len(value), len(value)
+	n := 0
+	for i := range value {
+		n++
+		if n == from {
+			start = i
+		}
+		if to > 0 && n == to+1 {
+			end = i
+			break
+		}
+	}
+	if from <= 1 {
+		start = 0
+	}
+	if start > end {
+		return ""
+	}
+	return value[start:end]
+}
+
+// appendJSON escapes the value for use inside a JSON string.
+func appendJSON(dst []byte, value string) []byte {
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(value); {
+		c := value[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(value[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, "\ufffd"...)
+			} else {
+				dst = append(dst, value[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+		switch {
+		case c == '"' || c == '\\':
+			dst = append(dst, '\\', c)
+		case c == '\n':
+			dst = append(dst, '\\', 'n')
+		case c == '\r':
+			dst = append(dst, '\\', 'r')
+		case c == '\t':
+			dst = append(dst, '\\', 't')
+		case c < 0x20:
+			dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+		default:
+			dst = append(dst, c)
+		}
+		i++
+	}
+	return dst
+}
+
+// appendCSV quotes the value as a CSV field, doubling the quotes it contains.
+func appendCSV(dst []byte, value string) []byte {
+	dst = append(dst, '"')
+	for {
+		before, after, found := strings.Cut(value, `"`)
+		dst = append(dst, before...)
+		if !found {
+			break
+		}
+		dst = append(dst, '"', '"')
+		value = after
+	}
+	return append(dst, '"')
+}
@@ -0,0 +1,160 @@
+//nolint:lll
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc3164"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+func TestFormatRFC5424(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 42 ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\"][origin ip=\"192.0.2.1\"] An \"application\" event,\nlog entry")
+	m, err := rfc5424.NewParser().Parse(bytes.NewReader(input))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{`%HOSTNAME% %APP-NAME% %PROCID% %MSGID%`, `mymachine.example.com evntslog 42 ID47`},
+		{`%hostname% %App-Name%`, `mymachine.example.com evntslog`},
+		{`%STRUCTURED-DATA%`, `[exampleSDID@32473 iut="3" eventSource="Application"][origin ip="192.0.2.1"]`},
+		{`%SYSLOGTAG%`, `evntslog[42]:`},
+		{`%PRI% %PRI-TEXT% %FACILITY% %FACILITY-TEXT% %SEVERITY% %SEVERITY-TEXT% %VERSION%`, `165 local4.notice<165> 20 local4 5 notice 1`},
+		{`%TIMESTAMP%`, `Oct 11 22:14:15`},
+		{`%TIMESTAMP:rfc3164%`, `Oct 11 22:14:15`},
+		{`%TIMESTAMP:rfc3339%`, `2003-10-11T22:14:15.003Z`},
+		{`%TIMESTAMP:unixtimestamp%`, `1065910455`},
+		{`%TIMESTAMP:year%-%TIMESTAMP:month%-%TIMESTAMP:day% %TIMESTAMP:hour%:%TIMESTAMP:minute%:%TIMESTAMP:second%`, `2003-10-11 22:14:15`},
+		{`%SD:origin:ip% %SD:exampleSDID@32473:eventSource%`, `192.0.2.1 Application`},
+		{`[%SD:origin:software%] [%SD:missing:ip%]`, `[] []`},
+		{`%MSG%`, "An \"application\" event,\nlog entry"},
+		{`%MSG:1:2%`, `An`},
+		{`%MSG:4:%`, "\"application\" event,\nlog entry"},
+		{`%MSG::2%`, `An`},
+		{`%MSG:100:200%`, ``},
+		{`%HOSTNAME:1:9:uppercase%`, `MYMACHINE`},
+		{`%SD:origin:ip:1:3%`, `192`},
+		{`%TIMESTAMP:rfc3339:1:4%`, `2003`},
+		{`%APP-NAME:::uppercase%`, `EVNTSLOG`},
+		{`%SEVERITY-TEXT:::uppercase,json%`, `NOTICE`},
+		{`{"msg":"%MSG:::json%"}`, `{"msg":"An \"application\" event,\nlog entry"}`},
+		{`%HOSTNAME:::csv%,%MSG:::csv%`, "\"mymachine.example.com\",\"An \"\"application\"\" event,\nlog entry\""},
+		{`100\% %HOSTNAME%\t%APP-NAME%\n`, "100% mymachine.example.com\tevntslog\n"},
+		{`\\%HOSTNAME%\`, `\mymachine.example.com\`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			t.Parallel()
+
+			template, err := Compile(tt.template)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, template.FormatRFC5424(m))
+			assert.Equal(t, "prefix "+tt.expected, string(template.AppendRFC5424([]byte("prefix "), m)))
+		})
+	}
+}
+
+func TestNilValues(t *testing.T) {
+	t.Parallel()
+
+	template := MustCompile(`%TIMESTAMP% %TIMESTAMP:rfc3339% %HOSTNAME% %APP-NAME% %PROCID% %MSGID% %STRUCTURED-DATA% %VERSION% [%MSG%]`)
+	assert.Equal(t, "- - - - - - - 1 []", template.FormatRFC5424(rfc5424.Message{}))
+
+	elements := []rfc5424.StructuredDataElement{{ID: "origin", Parameters: map[string]string{"ip": "192.0.2.1"}}}
+	m := rfc5424.Message{StructuredDataElements: &elements}
+	assert.Equal(t, `[origin ip="192.0.2.1"] 192.0.2.1`, MustCompile(`%STRUCTURED-DATA% %SD:origin:ip%`).FormatRFC5424(m))
+}
+
+func TestFormatRFC3164(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8")
+	m, err := rfc3164.NewParser().Parse(bytes.NewReader(input))
+	assert.Nil(t, err)
+
+	template := MustCompile(`%PRI-TEXT% %TIMESTAMP% %HOSTNAME% %SYSLOGTAG% %MSG%`)
+	assert.Equal(t, "auth.crit<34> Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8", template.FormatRFC3164(m))
+	assert.Equal(t, "su 123", string(MustCompile(`%APP-NAME% %PROCID%`).AppendRFC3164(nil, m)))
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		template string
+		err      error
+	}{
+		{`%HOSTNAME`, ErrUnterminatedProperty},
+		{`%HOSTNAME% %MSG`, ErrUnterminatedProperty},
+		{`%%`, ErrUnknownProperty},
+		{`%HOST%`, ErrUnknownProperty},
+		{`%SD%`, ErrInvalidProperty},
+		{`%SD:origin%`, ErrInvalidProperty},
+		{`%SD::ip%`, ErrInvalidProperty},
+		{`%MSG:a:2%`, ErrInvalidProperty},
+		{`%MSG:0:2%`, ErrInvalidProperty},
+		{`%MSG:3:2%`, ErrInvalidProperty},
+		{`%MSG:1:2:json:extra%`, ErrInvalidProperty},
+		{`%TIMESTAMP:iso%`, ErrInvalidProperty},
+		{`%MSG:::escape%`, ErrUnknownOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Compile(tt.template)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+
+	assert.Panics(t, func() { MustCompile(`%MSG`) })
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"plain", "quote \" and \\ backslash", "control \x00\x01\x1f\n\r\t", "unicode é 😀", "invalid \xff utf-8"} {
+		m := rfc5424.Message{Message: value, Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+		var decoded map[string]string
+		assert.Nil(t, json.Unmarshal(MustCompile(`{"msg":"%MSG:::json%"}`).AppendRFC5424(nil, m), &decoded), value)
+		expected := value
+		if value == "invalid \xff utf-8" {
+			expected = "invalid � utf-8"
+		}
+		assert.Equal(t, expected, decoded["msg"])
+	}
+}
+
+func TestSubstring(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "abc", substring("abc", 0, 0))
+	assert.Equal(t, "bc", substring("abc", 2, 0))
+	assert.Equal(t, "b", substring("abc", 2, 2))
+	assert.Equal(t, "abc", substring("abc", 1, 10))
+	assert.Equal(t, "", substring("abc", 4, 5))
+	assert.Equal(t, "é😀", substring("aé😀b", 2, 3))
+}
+
+func BenchmarkAppendRFC5424(b *testing.B) {
+	input := []byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 42 ID47 [origin ip=\"192.0.2.1\"] An application event log entry")
+	m, err := rfc5424.NewParser().Parse(bytes.NewReader(input))
+	assert.Nil(b, err)
+	template := MustCompile(`%TIMESTAMP:rfc3339% %HOSTNAME% %SYSLOGTAG% %SD:origin:ip% %MSG%\n`)
+
+	buf := make([]byte, 0, 256)
+	b.ReportAllocs()
+	for range b.N {
+		buf = template.AppendRFC5424(buf[:0], m)
+	}
+}
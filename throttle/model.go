@@ -0,0 +1,46 @@
+package throttle
+
+import (
+	"time"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Stats holds the counters of a Processor.
+type Stats struct {
+	// Passed is the number of messages that were returned unchanged.
+	Passed uint64
+	// Repeated is the number of messages that were collapsed into a summary.
+	Repeated uint64
+	// RateLimited is the number of messages that were dropped because their token bucket was empty.
+	RateLimited uint64
+}
+
+// bucketKey identifies the token bucket of a message.
+type bucketKey struct {
+	hostname string
+	appName  string
+	severity byte
+}
+
+// bucket is a token bucket that holds up to burst tokens and is refilled at the configured rate.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// sourceKey identifies the stream of messages in which consecutive duplicates are collapsed.
+type sourceKey struct {
+	hostname string
+	appName  string
+}
+
+// source holds the last message of a stream and the number of times it was repeated since.
+type source struct {
+	last     rfc5424.Message
+	repeated int
+	// since is the time the first repeat was suppressed.
+	since time.Time
+	// seen is the time the last message was received.
+	seen time.Time
+}
@@ -0,0 +1,31 @@
+package throttle
+
+import "time"
+
+type option func(*Processor)
+
+// WithRateLimit limits the messages per hostname, APP-NAME and severity to rate per second, allowing bursts of up to
+// burst messages. Rate limiting is disabled by default.
+func WithRateLimit(rate float64, burst int) option {
+	return func(p *Processor) {
+		p.rate = rate
+		p.burst = float64(burst)
+	}
+}
+
+// WithDeduplication collapses identical consecutive messages of a hostname and APP-NAME into a "last message repeated
+// N times" summary. A summary is emitted when a different message arrives, or by Flush once the repeats have been
+// pending for the interval. Classic syslogd uses 30 seconds. Deduplication is disabled by default.
+func WithDeduplication(interval time.Duration) option {
+	return func(p *Processor) {
+		p.dedup = true
+		p.interval = interval
+	}
+}
+
+// WithNow sets the clock of the Processor. It defaults to time.Now.
+func WithNow(now func() time.Time) option {
+	return func(p *Processor) {
+		p.now = now
+	}
+}
@@ -0,0 +1,190 @@
+// Package throttle protects downstream systems from chatty senders. A Processor rate-limits messages per hostname,
+// APP-NAME and severity using token buckets, and collapses identical consecutive messages into a single
+// "last message repeated N times" summary like classic syslogd.
+package throttle
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// Processor rate-limits and deduplicates messages. It is safe for concurrent use.
+type Processor struct {
+	rate     float64
+	burst    float64
+	dedup    bool
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+	sources map[sourceKey]*source
+	stats   Stats
+}
+
+// NewProcessor creates a Processor. Without options messages are passed unchanged.
+func NewProcessor(options ...option) *Processor {
+	p := &Processor{
+		now:     time.Now,
+		buckets: map[bucketKey]*bucket{},
+		sources: map[sourceKey]*source{},
+	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Process returns the messages to forward in place of m: a summary of the repeats of the previous message of the
+// same source if any, followed by m unless it is a repeat or rate-limited. Duplicates do not count towards the rate
+// limit.
+func (p *Processor) Process(m rfc5424.Message) []rfc5424.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var out []rfc5424.Message
+
+	key := sourceKey{hostname: m.Hostname, appName: m.AppName}
+	s := p.sources[key]
+	if p.dedup && s != nil {
+		if duplicate(s.last, m) {
+			p.stats.Repeated++
+			s.seen = now
+			if s.repeated == 0 {
+				s.since = now
+			}
+			s.repeated++
+			if now.Sub(s.since) >= p.interval {
+				out = append(out, s.summary(now))
+			}
+			return out
+		}
+		if s.repeated > 0 {
+			out = append(out, s.summary(now))
+		}
+	}
+
+	if p.rate > 0 && !p.take(m, now) {
+		p.stats.RateLimited++
+		return out
+	}
+	if p.dedup {
+		// The key is taken from the clone, as the strings of m may share a buffer that is reused by the caller.
+		last := clone(m)
+		p.sources[sourceKey{hostname: last.Hostname, appName: last.AppName}] = &source{last: last, seen: now}
+	}
+	p.stats.Passed++
+	return append(out, m)
+}
+
+// take takes a token from the bucket of the message and reports whether one was available.
+func (p *Processor) take(m rfc5424.Message, now time.Time) bool {
+	key := bucketKey{hostname: m.Hostname, appName: m.AppName, severity: m.PRI.Severity()}
+	b := p.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: p.burst, updated: now}
+		key.hostname, key.appName = strings.Clone(key.hostname), strings.Clone(key.appName)
+		p.buckets[key] = b
+	}
+	b.refill(now, p.rate, p.burst)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *bucket) refill(now time.Time, rate, burst float64) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = min(burst, b.tokens+elapsed.Seconds()*rate)
+		b.updated = now
+	}
+}
+
+// Flush returns the summaries of repeats that have been pending for at least the deduplication interval. It should
+// be called periodically, so a summary is not held back until the source sends a different message.
+// Sources that have been idle for the interval and token buckets that have refilled are forgotten.
+func (p *Processor) Flush() []rfc5424.Message {
+	return p.flush(false)
+}
+
+// FlushAll returns the summaries of all pending repeats, e.g. on shutdown.
+func (p *Processor) FlushAll() []rfc5424.Message {
+	return p.flush(true)
+}
+
+func (p *Processor) flush(all bool) []rfc5424.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var out []rfc5424.Message
+	for key, s := range p.sources {
+		if s.repeated > 0 && (all || now.Sub(s.since) >= p.interval) {
+			out = append(out, s.summary(now))
+		}
+		if s.repeated == 0 && now.Sub(s.seen) >= p.interval {
+			delete(p.sources, key)
+		}
+	}
+	for key, b := range p.buckets {
+		// A full bucket is identical to a new one.
+		if b.refill(now, p.rate, p.burst); b.tokens >= p.burst {
+			delete(p.buckets, key)
+		}
+	}
+
+	slices.SortFunc(out, func(a, b rfc5424.Message) int {
+		return cmp.Or(strings.Compare(a.Hostname, b.Hostname), strings.Compare(a.AppName, b.AppName))
+	})
+	return out
+}
+
+// Stats returns the current counters of the processor.
+func (p *Processor) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// summary returns the "last message repeated N times" message for the repeats and resets them.
+func (s *source) summary(now time.Time) rfc5424.Message {
+	m := rfc5424.Message{
+		PRI:       s.last.PRI,
+		Version:   s.last.Version,
+		Timestamp: now,
+		Hostname:  s.last.Hostname,
+		AppName:   s.last.AppName,
+		ProcID:    s.last.ProcID,
+		Message:   "last message repeated " + strconv.Itoa(s.repeated) + " times",
+	}
+	s.repeated = 0
+	return m
+}
+
+// duplicate reports whether b repeats a. The timestamp is ignored.
+func duplicate(a, b rfc5424.Message) bool {
+	return a.PRI == b.PRI && a.ProcID == b.ProcID && a.MsgID == b.MsgID && a.StructuredData == b.StructuredData &&
+		a.Message == b.Message
+}
+
+// clone copies the fields used for deduplication, as the strings of a message may be reused by ParseInto.
+func clone(m rfc5424.Message) rfc5424.Message {
+	return rfc5424.Message{
+		PRI:            m.PRI,
+		Version:        m.Version,
+		Hostname:       strings.Clone(m.Hostname),
+		AppName:        strings.Clone(m.AppName),
+		ProcID:         strings.Clone(m.ProcID),
+		MsgID:          strings.Clone(m.MsgID),
+		StructuredData: strings.Clone(m.StructuredData),
+		Message:        strings.Clone(m.Message),
+	}
+}
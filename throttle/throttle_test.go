@@ -0,0 +1,193 @@
+//nolint:lll
+package throttle
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmilda/syslog/rfc5424"
+)
+
+// clock is a deterministic clock that is advanced by the tests.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+}
+
+func message(t *testing.T, hostname, appName string, severity byte, text string) rfc5424.Message {
+	t.Helper()
+	pri, err := rfc5424.NewPRI(16<<3 | severity)
+	assert.Nil(t, err)
+	return rfc5424.Message{PRI: pri, Version: 1, Hostname: hostname, AppName: appName, Message: text}
+}
+
+func summary(t *testing.T, m rfc5424.Message, now time.Time, text string) rfc5424.Message {
+	t.Helper()
+	return rfc5424.Message{PRI: m.PRI, Version: 1, Timestamp: now, Hostname: m.Hostname, AppName: m.AppName, Message: text}
+}
+
+func TestPassThrough(t *testing.T) {
+	t.Parallel()
+
+	p := NewProcessor()
+	m := message(t, "host", "app", 6, "hello")
+	for range 3 {
+		assert.Equal(t, []rfc5424.Message{m}, p.Process(m))
+	}
+	assert.Nil(t, p.FlushAll())
+	assert.Equal(t, Stats{Passed: 3}, p.Stats())
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	c := newClock()
+	p := NewProcessor(WithRateLimit(2, 3), WithNow(c.Now))
+
+	m := message(t, "host", "app", 6, "hello")
+	passed := func() int {
+		n := 0
+		for range 10 {
+			n += len(p.Process(m))
+		}
+		return n
+	}
+
+	assert.Equal(t, 3, passed(), "burst")
+	c.advance(500 * time.Millisecond)
+	assert.Equal(t, 1, passed(), "refill at rate")
+	c.advance(time.Hour)
+	assert.Equal(t, 3, passed(), "refill up to burst")
+
+	// Other hostnames, applications and severities have their own buckets.
+	assert.Len(t, p.Process(message(t, "other", "app", 6, "hello")), 1)
+	assert.Len(t, p.Process(message(t, "host", "other", 6, "hello")), 1)
+	assert.Len(t, p.Process(message(t, "host", "app", 3, "hello")), 1)
+
+	assert.Equal(t, Stats{Passed: 10, RateLimited: 23}, p.Stats())
+
+	assert.Nil(t, p.Flush())
+	assert.Len(t, p.buckets, 4)
+	c.advance(2 * time.Second)
+	assert.Nil(t, p.Flush())
+	assert.Empty(t, p.buckets)
+}
+
+func TestDeduplication(t *testing.T) {
+	t.Parallel()
+
+	c := newClock()
+	p := NewProcessor(WithDeduplication(30*time.Second), WithNow(c.Now))
+
+	m := message(t, "host", "app", 6, "link down")
+	assert.Equal(t, []rfc5424.Message{m}, p.Process(m))
+	for range 3 {
+		c.advance(time.Second)
+		repeat := m
+		repeat.Timestamp = c.now
+		assert.Nil(t, p.Process(repeat))
+	}
+
+	// Another source does not interrupt the repeats.
+	other := message(t, "other", "app", 6, "link down")
+	assert.Equal(t, []rfc5424.Message{other}, p.Process(other))
+
+	c.advance(time.Second)
+	next := message(t, "host", "app", 6, "link up")
+	assert.Equal(t, []rfc5424.Message{summary(t, m, c.now, "last message repeated 3 times"), next}, p.Process(next))
+	notice := message(t, "host", "app", 5, "link up")
+	assert.Equal(t, []rfc5424.Message{notice}, p.Process(notice), "different PRI")
+
+	assert.Equal(t, Stats{Passed: 4, Repeated: 3}, p.Stats())
+}
+
+func TestParseInto(t *testing.T) {
+	t.Parallel()
+
+	// ParseInto reuses the storage of the message, so the processor must not keep its strings.
+	p := NewProcessor(WithDeduplication(time.Minute), WithRateLimit(1, 10), WithNow(newClock().Now))
+	parser := rfc5424.NewParser()
+	var m rfc5424.Message
+	for range 2 {
+		for i := range 200 {
+			input := fmt.Sprintf("<14>1 - host%d app - - - link down", i)
+			assert.Nil(t, parser.ParseInto(bytes.NewReader([]byte(input)), &m))
+			p.Process(m)
+		}
+	}
+	assert.Equal(t, Stats{Passed: 200, Repeated: 200}, p.Stats())
+}
+
+func TestDeduplicationFlush(t *testing.T) {
+	t.Parallel()
+
+	c := newClock()
+	p := NewProcessor(WithDeduplication(30*time.Second), WithNow(c.Now))
+
+	a := message(t, "b-host", "app", 6, "flood")
+	b := message(t, "a-host", "app", 6, "flood")
+	p.Process(a)
+	p.Process(a)
+	p.Process(b)
+	c.advance(10 * time.Second)
+	p.Process(b)
+
+	c.advance(20 * time.Second)
+	assert.Equal(t, []rfc5424.Message{summary(t, a, c.now, "last message repeated 1 times")}, p.Flush())
+
+	assert.Equal(t, []rfc5424.Message{summary(t, b, c.now, "last message repeated 1 times")}, p.FlushAll())
+	assert.Nil(t, p.FlushAll())
+
+	// The source of a was idle for the interval when it was flushed, so it was forgotten.
+	c.advance(time.Second)
+	assert.Equal(t, []rfc5424.Message{a}, p.Process(a))
+
+	// Repeats that continue for the interval are summarised without a flush.
+	for range 30 {
+		c.advance(time.Second)
+		assert.Nil(t, p.Process(a))
+	}
+	c.advance(time.Second)
+	assert.Equal(t, []rfc5424.Message{summary(t, a, c.now, "last message repeated 31 times")}, p.Process(a))
+
+	// Idle sources are forgotten, so a message is passed again.
+	c.advance(time.Minute)
+	assert.Nil(t, p.Flush())
+	assert.Empty(t, p.sources)
+	assert.Equal(t, []rfc5424.Message{a}, p.Process(a))
+}
+
+func TestDeduplicationRateLimit(t *testing.T) {
+	t.Parallel()
+
+	c := newClock()
+	p := NewProcessor(WithDeduplication(30*time.Second), WithRateLimit(1, 1), WithNow(c.Now))
+
+	a := message(t, "host", "app", 6, "a")
+	b := message(t, "host", "app", 6, "b")
+
+	assert.Equal(t, []rfc5424.Message{a}, p.Process(a))
+	assert.Nil(t, p.Process(a), "duplicates do not take a token")
+	assert.Equal(t, []rfc5424.Message{summary(t, a, c.now, "last message repeated 1 times")}, p.Process(b))
+	// b was rate-limited, so a is still the last message.
+	assert.Nil(t, p.Process(a))
+
+	c.advance(time.Second)
+	assert.Equal(t, []rfc5424.Message{summary(t, a, c.now, "last message repeated 1 times"), b}, p.Process(b))
+
+	assert.Equal(t, Stats{Passed: 2, Repeated: 2, RateLimited: 1}, p.Stats())
+}